@@ -0,0 +1,13 @@
+// Команда dice_roller-cli — сборка dice_roller без окна: roll, order, history
+// и verify. Она не связана с Ebitengine и GTK и запускается на сервере без дисплея.
+package main
+
+import (
+	"os"
+
+	"github.com/olegshirko/dice_roller/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], nil))
+}
@@ -0,0 +1,140 @@
+// Package cli реализует команды dice_roller без экрана, которыми могут пользоваться
+// скрипты и задания cron. Окно с костью (команда gui) передается в Run извне,
+// чтобы эти команды собирались без Ebitengine и работали на сервере без дисплея.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+)
+
+// stdout и stderr — куда команды пишут результаты и ошибки. Тесты подменяют их.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// command — подкоманда dice_roller.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int // nil у gui: окно передается в Run
+}
+
+// commands перечисляет подкоманды в том порядке, в котором они показываются в справке.
+var commands = []command{
+	{"gui", "open the window with the die (default)", nil},
+	{"roll", "roll dice expressions like 3d6 and print the results", runRoll},
+	{"order", "draw a stand-up speaking order and print it", runOrder},
+	{"history", "show or export the history of results", runHistory},
+	{"verify", "verify fairness proofs", runVerify},
+}
+
+// Run выполняет команду, заданную первым из аргументов args (без имени программы),
+// и возвращает код завершения процесса. Если команда не указана или первый
+// аргумент — флаг, окно с костью открывает gui. Сборка без окна передает nil,
+// и тогда команда gui недоступна.
+func Run(args []string, gui func(args []string) int) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runGUI(gui, args)
+	}
+	for _, c := range commands {
+		if c.name == args[0] && c.run == nil {
+			return runGUI(gui, args[1:])
+		}
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	if args[0] == "help" {
+		usage(stdout, gui != nil)
+		return 0
+	}
+	fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+	usage(stderr, gui != nil)
+	return 2
+}
+
+// runGUI открывает окно или, если эта сборка без окна, сообщает об этом.
+func runGUI(gui func(args []string) int, args []string) int {
+	if gui == nil {
+		fmt.Fprintln(stderr, "this build has no window; run dice_roller for the gui or pass a command")
+		fmt.Fprintln(stderr)
+		usage(stderr, false)
+		return 2
+	}
+	return gui(args)
+}
+
+// usage выводит список команд; withGUI показывает и команду gui.
+func usage(w io.Writer, withGUI bool) {
+	fmt.Fprintln(w, "usage: dice_roller [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		if c.run == nil && !withGUI {
+			continue
+		}
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "dice_roller <command> -h" for the flags of a command`)
+}
+
+// newFlagSet создает набор флагов подкоманды name. Ошибки разбора не завершают
+// процесс, а возвращаются, чтобы команда вернула код 2.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: dice_roller %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// DefaultConfigPath возвращает файл настроек из переменной окружения DICE_ROLLER_CONFIG
+// или, если она не задана, из пользовательского каталога настроек.
+func DefaultConfigPath() string {
+	if path, ok := os.LookupEnv(config.EnvName("config")); ok {
+		return path
+	}
+	return config.DefaultPath()
+}
+
+// NewSource создает источник случайности: crypto/rand или генератор с зерном seed
+// (0 выбирает случайное зерно).
+func NewSource(seed uint64, useCrypto bool) random.Source {
+	if useCrypto {
+		log.Println("Using crypto/rand random source.")
+		return random.NewCrypto()
+	}
+	if seed == 0 {
+		seed = random.NewSeed()
+	}
+	// Зерно выводится в лог, чтобы любой запуск можно было повторить с флагом -seed.
+	log.Printf("Using random seed %d", seed)
+	return random.NewPCG(seed)
+}
+
+// OpenHistory открывает журнал результатов path и читает прошлые результаты.
+// Пустой путь отключает журнал: возвращается nil.
+func OpenHistory(path string) (*history.Store, []history.Entry) {
+	if path == "" {
+		return nil, nil
+	}
+	store := history.NewStore(path)
+	past, err := store.Load()
+	if err != nil {
+		log.Printf("Could not read history: %v", err)
+	}
+	return store, past
+}
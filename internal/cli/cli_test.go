@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run выполняет команду и возвращает код завершения и вывод в stdout.
+func run(t *testing.T, args ...string) (int, string) {
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+	t.Cleanup(func() { stdout, stderr = os.Stdout, os.Stderr })
+	t.Setenv("DICE_ROLLER_CONFIG", "") // Не читаем настройки пользователя
+
+	code := Run(args, nil)
+	if code != 0 {
+		t.Logf("stderr: %s", errOut.String())
+	}
+	return code, out.String()
+}
+
+func TestRoll(t *testing.T) {
+	code, out := run(t, "roll", "-seed", "42", "3d6", "4d6kh3+5")
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "3d6: 3d6["), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "4d6kh3+5: 4d6kh3["), lines[1])
+
+	_, again := run(t, "roll", "-seed", "42", "3d6", "4d6kh3+5")
+	assert.Equal(t, out, again, "The same seed gives the same rolls")
+
+	code, out = run(t, "roll", "-seed", "42", "-format", "json", "2d6")
+	require.Equal(t, 0, code)
+	var results []struct {
+		Expr   string
+		Groups []struct {
+			Dice []struct{ Value int }
+		}
+		Total int
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "2d6", results[0].Expr)
+	require.Len(t, results[0].Groups, 1)
+	dice := results[0].Groups[0].Dice
+	require.Len(t, dice, 2)
+	assert.Equal(t, dice[0].Value+dice[1].Value, results[0].Total)
+
+	code, _ = run(t, "roll", "3x6")
+	assert.Equal(t, 2, code, "Malformed expressions are usage errors")
+	code, _ = run(t, "roll")
+	assert.Equal(t, 2, code)
+}
+
+func TestOrder(t *testing.T) {
+	dir := t.TempDir()
+	roster := filepath.Join(dir, "team.txt")
+	require.NoError(t, os.WriteFile(roster, []byte("Ann\nBob\nCarl\nDana\nEve\nFred\nGil\n"), 0o644))
+	attendance := filepath.Join(dir, "attendance.json")
+	require.NoError(t, os.WriteFile(attendance, []byte(`{"absent": ["Carl"]}`), 0o644))
+	historyPath := filepath.Join(dir, "history.jsonl")
+
+	args := []string{"order", "-roster", roster, "-dir", dir, "-attendance", attendance, "-history", historyPath, "-seed", "3"}
+	code, out := run(t, args...)
+	require.Equal(t, 0, code)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 6, "Everyone present speaks exactly once")
+	var names []string
+	for i, line := range lines {
+		var n int
+		var name string
+		_, err := fmt.Sscanf(line, "%d. %s", &n, &name)
+		require.NoError(t, err, line)
+		assert.Equal(t, i+1, n)
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"Ann", "Bob", "Dana", "Eve", "Fred", "Gil"}, names)
+
+	entries, err := history.NewStore(historyPath).Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 6, "Every pick is recorded in history")
+	for i, e := range entries {
+		assert.Equal(t, names[i], e.Label)
+		assert.Equal(t, 1, e.Cycle)
+	}
+
+	code, _ = run(t, args...)
+	require.Equal(t, 0, code)
+	entries, err = history.NewStore(historyPath).Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 12)
+	assert.Equal(t, 2, entries[6].Cycle, "The next run continues cycle numbering from the history")
+
+	code, out = run(t, append(args, "-format", "json", "-history", "")...)
+	require.Equal(t, 0, code)
+	var order []string
+	require.NoError(t, json.Unmarshal([]byte(out), &order))
+	assert.Equal(t, names, order, "The same seed gives the same order")
+
+	code, _ = run(t, "order", "-roster", filepath.Join(dir, "missing.txt"), "-history", "")
+	assert.Equal(t, 1, code)
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := history.NewStore(path)
+	for _, label := range []string{"Ann", "Bob", "Carl"} {
+		require.NoError(t, store.Append(history.Entry{Label: label, Cycle: 1}))
+	}
+
+	code, out := run(t, "history", "-file", path, "-n", "2")
+	require.Equal(t, 0, code)
+	assert.NotContains(t, out, "Ann")
+	assert.Contains(t, out, "Bob")
+	assert.Contains(t, out, "Carl")
+
+	code, out = run(t, "history", "-file", path, "-format", "csv")
+	require.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(out, "time,label,"), out)
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	code, _ := run(t, "dance")
+	assert.Equal(t, 2, code)
+
+	code, out := run(t, "help")
+	assert.Equal(t, 0, code)
+	for _, c := range commands[1:] {
+		assert.Contains(t, out, c.name)
+	}
+	assert.NotContains(t, out, "gui", "A build without a window does not offer it")
+}
+
+func TestRun_GUI(t *testing.T) {
+	// Без окна команда gui и запуск без команды завершаются ошибкой.
+	code, _ := run(t)
+	assert.Equal(t, 2, code)
+	code, _ = run(t, "gui")
+	assert.Equal(t, 2, code)
+
+	var got []string
+	gui := func(args []string) int {
+		got = args
+		return 0
+	}
+	assert.Equal(t, 0, Run([]string{"gui", "-config", "x"}, gui))
+	assert.Equal(t, []string{"-config", "x"}, got)
+	assert.Equal(t, 0, Run([]string{"-config", "y"}, gui))
+	assert.Equal(t, []string{"-config", "y"}, got)
+}
+
+// TestHeadlessDeps проверяет, что команды без экрана не связаны с Ebitengine и GTK:
+// их init требует дисплея.
+func TestHeadlessDeps(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not in PATH")
+	}
+	out, err := exec.Command(goBin, "list", "-deps", "github.com/olegshirko/dice_roller/cmd/dice_roller-cli").Output()
+	require.NoError(t, err)
+	for _, dep := range strings.Fields(string(out)) {
+		assert.NotContains(t, dep, "hajimehoshi/ebiten")
+		assert.NotContains(t, dep, "sqweek/dialog")
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+)
+
+// runHistory выводит или экспортирует журнал результатов.
+func runHistory(args []string) int {
+	fs := newFlagSet("history", "")
+	path := fs.String("file", history.DefaultPath(), "history file")
+	last := fs.Int("n", 0, "show only the last N results (0 shows all)")
+	format := fs.String("format", "text", "output format: text, csv or json")
+	output := fs.String("o", "", "write to a file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	entries, err := history.NewStore(*path).Load()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *last > 0 {
+		entries = history.Tail(entries, *last)
+	}
+
+	out := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *format {
+	case "csv":
+		err = history.ExportCSV(out, entries)
+	case "json":
+		err = history.ExportJSON(out, entries)
+	case "text":
+		for _, e := range entries {
+			fmt.Fprintf(out, "%s  cycle %d  %s", e.Time.Format("2006-01-02 15:04:05"), e.Cycle, e.Label)
+			if e.SpeakingSeconds > 0 {
+				fmt.Fprintf(out, "  spoke %.0fs", e.SpeakingSeconds)
+			}
+			fmt.Fprintln(out)
+		}
+	default:
+		err = fmt.Errorf("unknown format %q (want text, csv or json)", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
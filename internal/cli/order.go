@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+)
+
+// runOrder разыгрывает очередь выступлений так же, как клавиша F в окне,
+// и печатает ее. Отсутствующие не участвуют, а результаты попадают в журнал,
+// поэтому политики выбора учитывают запуски из cron наравне с запусками окна.
+func runOrder(args []string) int {
+	cfg, err := config.Load(DefaultConfigPath(), os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid settings: %v\n", err)
+		return 1
+	}
+
+	fs := newFlagSet("order", "")
+	roster := fs.String("roster", cfg.Assets.Roster, "team roster file (.txt, .csv or .json)")
+	dir := fs.String("dir", cfg.Assets.Dir, "directory with face images, used when there is no roster")
+	attendance := fs.String("attendance", assets.DefaultAttendancePath(), "file with people marked absent (empty ignores attendance)")
+	historyPath := fs.String("history", history.DefaultPath(), "file where every pick is appended (empty disables history)")
+	policy := fs.String("policy", "uniform", "how speakers are picked: uniform, weighted or round-robin")
+	seed := fs.Uint64("seed", 0, "seed for a reproducible order (0 picks a random seed)")
+	useCrypto := fs.Bool("crypto", false, "use crypto/rand instead of a seeded generator")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q (want text or json)\n", *format)
+		return 2
+	}
+
+	rnd := NewSource(*seed, *useCrypto)
+	am := assets.NewManager(rnd)
+	am.AttendancePath = *attendance
+	am.LoadAttendance()
+	if *roster != "" {
+		if !am.LoadRoster(*roster, *dir) {
+			fmt.Fprintf(stderr, "could not load roster %s\n", *roster)
+			return 1
+		}
+	} else if !am.LoadFromDirectory(*dir) {
+		fmt.Fprintf(stderr, "no images in %s; pass -roster or -dir\n", *dir)
+		return 1
+	}
+
+	die, err := cube.NewPolyhedron(cfg.Cube.Sides)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	sm := roll.NewStateManager(die, am, rnd, cfg.Animation)
+	if cfg.Cube.Physics {
+		sm.Mode = roll.ModePhysics
+	}
+	store, past := OpenHistory(*historyPath)
+	p, err := selection.New(*policy, past)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	sm.SetPolicy(p)
+
+	// DrawOrder начинает новый цикл, продолжая нумерацию журнала.
+	sm.Cycle = 0
+	if len(past) > 0 {
+		sm.Cycle = past[len(past)-1].Cycle
+	}
+	order := sm.DrawOrder(func(label string) {
+		entry := history.Entry{Time: time.Now(), Label: label, Cycle: sm.Cycle}
+		if seeded, ok := rnd.(random.Seeded); ok {
+			seed := seeded.Seed()
+			entry.Seed = &seed
+		}
+		sm.Policy.Observe(entry)
+		if store == nil {
+			return
+		}
+		if err := store.Append(entry); err != nil {
+			log.Printf("Could not save history to %s: %v", store.Path, err)
+		}
+	})
+	if len(order) == 0 {
+		fmt.Fprintln(stderr, "nobody to draw: everyone is absent")
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(order); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+	for i, name := range order {
+		fmt.Fprintf(stdout, "%2d. %s\n", i+1, name)
+	}
+	return 0
+}
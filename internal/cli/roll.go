@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/olegshirko/dice_roller/pkg/dice"
+)
+
+// runRoll бросает кости по выражениям из аргументов ("4d6kh3", "2d20kl1+5", "d%")
+// и печатает результаты с расшифровкой по костям: в текстовом виде по строке
+// на выражение или одним массивом JSON.
+func runRoll(args []string) int {
+	fs := newFlagSet("roll", "EXPR [EXPR...]")
+	seed := fs.Uint64("seed", 0, "seed for reproducible rolls (0 picks a random seed)")
+	useCrypto := fs.Bool("crypto", false, "use crypto/rand instead of a seeded generator")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q (want text or json)\n", *format)
+		return 2
+	}
+
+	// Сначала разбираем все выражения, чтобы опечатка не оставила половину бросков
+	exprs := make([]*dice.Expr, fs.NArg())
+	for i, src := range fs.Args() {
+		e, err := dice.Parse(src)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		exprs[i] = e
+	}
+
+	rnd := NewSource(*seed, *useCrypto)
+	results := make([]dice.Result, len(exprs))
+	for i, e := range exprs {
+		results[i] = e.Roll(rnd)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+	for _, res := range results {
+		fmt.Fprintln(stdout, res)
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/olegshirko/dice_roller/pkg/fairness"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+)
+
+// runVerify проверяет доказательства честности, переданные в аргументах.
+func runVerify(args []string) int {
+	fs := newFlagSet("verify", "proof.json [proof.json...]")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, path := range fs.Args() {
+		proof, err := fairness.Load(path)
+		if err == nil {
+			err = roll.VerifyProof(proof)
+		}
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: FAIL: %v\n", path, err)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: OK, %d draws follow from seed %d\n", path, len(proof.Draws), *proof.Seed)
+	}
+	return code
+}
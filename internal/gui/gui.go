@@ -0,0 +1,185 @@
+// Package gui — команда gui: окно с костью. Она единственная связана с Ebitengine
+// и диалогами GTK, поэтому команды без экрана собираются без нее (cmd/dice_roller-cli).
+package gui
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/olegshirko/dice_roller/internal/cli"
+	"github.com/olegshirko/dice_roller/pkg/api"
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/events"
+	"github.com/olegshirko/dice_roller/pkg/game"
+	"github.com/olegshirko/dice_roller/pkg/graphics"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/record"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+	"github.com/olegshirko/dice_roller/pkg/webhook"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Run открывает окно с костью и возвращает код завершения процесса. Флаги
+// разбираются общим flag.CommandLine, поскольку это команда по умолчанию.
+func Run(args []string) int {
+	fs := flag.CommandLine
+	configPath := fs.String("config", cli.DefaultConfigPath(), "JSON settings file (env "+config.EnvName("config")+")")
+	applyFlags := config.RegisterFlags(fs)
+	seed := fs.Uint64("seed", 0, "seed for reproducible rolls (0 picks a random seed)")
+	useCrypto := fs.Bool("crypto", false, "use crypto/rand instead of a seeded generator (rolls are not reproducible)")
+	historyPath := fs.String("history", history.DefaultPath(), "file where every result is appended (empty disables history)")
+	policy := fs.String("policy", "uniform", "how the winner is picked: uniform, weighted (by days since last pick) or round-robin")
+	standup := fs.Bool("standup", false, "draw the whole stand-up order at launch")
+	timebox := fs.Duration("timebox", 0, "time each speaker gets after being picked, e.g. 2m (0 disables the timer)")
+	autoNext := fs.Bool("auto-next", false, "move on to the next speaker when the timebox runs out")
+	fairDir := fs.String("fair", "", "directory for commit-reveal fairness proofs (each cycle uses a fresh secret seed)")
+	rollExpr := fs.String("roll", "", "roll a dice expression such as 4d6kh3 at launch instead of picking a face")
+	software := fs.Bool("software-renderer", false, "draw the die on the CPU instead of the GPU (slower, same frames as the golden-image tests)")
+	screenshots := fs.String("screenshots", "", "directory for screenshots and roll recordings (default: current directory)")
+	apiAddr := fs.String("api", "", "serve the HTTP control API on this address, e.g. 127.0.0.1:8765 (empty disables it)")
+	recordAll := fs.Bool("record", false, "record every roll, not only the one after the record key")
+	recordFormat := fs.String("record-format", string(record.GIF), "recording format: gif (one animated file) or png (a directory of numbered frames)")
+	recordScale := fs.Float64("record-scale", game.DefaultRecording().Options.Scale, "recording size relative to the window, in (0, 1]")
+	recordFPS := fs.Int("record-fps", game.DefaultRecording().Options.FPS, "recorded frames per second (0 keeps every frame)")
+	fs.Parse(args)
+
+	// Настройки: значения по умолчанию, файл, переменные окружения и, наконец, флаги
+	cfg, err := config.Load(*configPath, os.LookupEnv)
+	if err != nil {
+		log.Printf("Invalid settings: %v", err)
+		return 1
+	}
+	applyFlags(&cfg)
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Invalid settings:\n%v", err)
+		return 1
+	}
+	format, err := record.ParseFormat(*recordFormat)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	recording := game.Recording{All: *recordAll, Options: record.Options{Format: format, Scale: *recordScale, FPS: *recordFPS}}
+	if err := recording.Options.Validate(); err != nil {
+		log.Println(err)
+		return 1
+	}
+	notifier, err := newNotifier(cfg.Webhook, format)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	if *fairDir != "" && *policy != "uniform" {
+		// Проверка доказательства повторяет только равновероятный выбор.
+		log.Println("Fairness proofs require the uniform selection policy.")
+		return 1
+	}
+
+	die, err := cube.NewPolyhedron(cfg.Cube.Sides)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	rnd := cli.NewSource(*seed, *useCrypto)
+
+	ebiten.SetWindowDecorated(cfg.Window.Decorated)
+	ebiten.SetScreenTransparent(cfg.Window.Transparent)
+	ebiten.SetWindowSize(cfg.Window.Width, cfg.Window.Height)
+	ebiten.SetWindowTitle(cfg.Window.Title)
+
+	assetManager := assets.NewManager(rnd)
+	assetManager.AttendancePath = assets.DefaultAttendancePath()
+	assetManager.LoadAttendance()
+	if cfg.Assets.Roster != "" {
+		assetManager.LoadRoster(cfg.Assets.Roster, cfg.Assets.Dir)
+	} else {
+		assetManager.LoadFromDirectory(cfg.Assets.Dir)
+	}
+
+	g := game.NewGame(assetManager, die, rnd, cfg)
+	store, past := cli.OpenHistory(*historyPath)
+	if store != nil {
+		g.EnableHistory(store)
+	}
+	p, err := selection.New(*policy, past)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	g.StateManager.SetPolicy(p)
+	if *fairDir != "" {
+		if err := g.EnableFairness(*fairDir); err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
+	if *software {
+		g.Renderer = g.Renderer.(*graphics.Renderer).Software()
+	}
+	g.ScreenshotDir = *screenshots
+	g.Record = recording
+	g.Timebox.Duration = *timebox
+	g.Timebox.AutoNext = *autoNext
+
+	if *apiAddr != "" {
+		eventLog := events.NewLog(events.DefaultKeep)
+		g.EnableEvents(eventLog)
+		if _, err := api.Serve(*apiAddr, api.NewHandler(g.Remote(), eventLog)); err != nil {
+			log.Printf("Could not start the API: %v", err)
+			return 1
+		}
+	}
+
+	if notifier != nil {
+		g.EnableWebhook(notifier)
+	}
+
+	if *standup {
+		g.StartStandup(false)
+	}
+	if *rollExpr != "" {
+		if err := g.RollDice(*rollExpr); err != nil {
+			log.Println(err)
+			return 2
+		}
+	}
+
+	err = ebiten.RunGame(g)
+	if notifier != nil {
+		notifier.Wait() // Последний результат не должен потеряться при закрытии окна
+	}
+	if err != nil && err != ebiten.Termination {
+		log.Println(err)
+		return 1
+	}
+	log.Println("Game finished.")
+	return 0
+}
+
+// newNotifier создает уведомления о выбранных участниках по настройкам w или
+// возвращает nil, если они выключены. Анимация броска прикладывается только
+// из записи в формате GIF.
+func newNotifier(w config.Webhook, format record.Format) (*webhook.Notifier, error) {
+	if w.URL == "" && !w.DryRun {
+		return nil, nil
+	}
+	if webhook.Snapshot(w.Snapshot) == webhook.SnapshotGIF && format != record.GIF {
+		return nil, fmt.Errorf("webhook snapshot gif needs -record-format %s", record.GIF)
+	}
+	return webhook.New(webhook.Options{
+		URL:      w.URL,
+		Template: w.Template,
+		Snapshot: webhook.Snapshot(w.Snapshot),
+		Timeout:  time.Duration(w.Timeout * float64(time.Second)),
+		Retries:  w.Retries,
+		Backoff:  time.Duration(w.Backoff * float64(time.Second)),
+		DryRun:   w.DryRun,
+	})
+}
@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"hash/fnv"
 	"image"
+	"image/color"
 	"image/draw"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/font/opentype"
@@ -13,7 +17,7 @@ import (
 )
 
 // AddLabelToImage добавляет текстовую метку на изображение.
-func AddLabelToImage(img image.Image, label string) *ebiten.Image {
+func AddLabelToImage(img image.Image, label string) *image.RGBA {
 	bounds := img.Bounds()
 	newImg := image.NewRGBA(bounds)
 	draw.Draw(newImg, bounds, img, image.Point{}, draw.Src)
@@ -60,5 +64,122 @@ func AddLabelToImage(img image.Image, label string) *ebiten.Image {
 	drawer.Src = image.White
 	drawer.DrawString(label)
 
-	return ebiten.NewImageFromImage(newImg)
+	return newImg
+}
+
+// nameCardPalette — насыщенные цвета фона для карточек с именами.
+var nameCardPalette = []color.RGBA{
+	{0xE5, 0x39, 0x35, 0xFF}, // красный
+	{0xD8, 0x1B, 0x60, 0xFF}, // малиновый
+	{0x8E, 0x24, 0xAA, 0xFF}, // фиолетовый
+	{0x39, 0x49, 0xAB, 0xFF}, // индиго
+	{0x1E, 0x88, 0xE5, 0xFF}, // синий
+	{0x00, 0x89, 0x7B, 0xFF}, // бирюзовый
+	{0x43, 0xA0, 0x47, 0xFF}, // зеленый
+	{0xF4, 0x51, 0x1E, 0xFF}, // оранжевый
+	{0x6D, 0x4C, 0x41, 0xFF}, // коричневый
+	{0x54, 0x6E, 0x7A, 0xFF}, // серо-синий
+}
+
+// Initials возвращает инициалы: первые буквы первых двух слов имени в верхнем регистре.
+func Initials(name string) string {
+	var initials []rune
+	for _, word := range strings.Fields(name) {
+		r, _ := utf8.DecodeRuneInString(word)
+		initials = append(initials, unicode.ToUpper(r))
+		if len(initials) == 2 {
+			break
+		}
+	}
+	return string(initials)
+}
+
+// NameCard рисует квадратную карточку для человека без фотографии: цветной фон,
+// выбранный по имени (у одного и того же имени цвет всегда одинаковый), и крупные инициалы.
+func NameCard(name string, size int) *image.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	bg := nameCardPalette[h.Sum32()%uint32(len(nameCardPalette))]
+
+	card := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(card, card.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	tt, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		log.Fatal(err)
+	}
+	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size: float64(size) * 0.4,
+		DPI:  72,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	drawer := &font.Drawer{
+		Dst:  card,
+		Src:  image.White,
+		Face: face,
+	}
+
+	// Центрируем инициалы по горизонтали и по высоте заглавных букв
+	initials := Initials(name)
+	metrics := face.Metrics()
+	x := (fixed.I(size) - drawer.MeasureString(initials)) / 2
+	y := (fixed.I(size) + metrics.CapHeight) / 2
+	drawer.Dot = fixed.Point26_6{X: x, Y: y}
+	drawer.DrawString(initials)
+
+	return card
+}
+
+// Цвета граней костей для бросков выражений.
+var (
+	dieFaceColor  = color.RGBA{0xF5, 0xF0, 0xE1, 0xFF} // слоновая кость
+	dieLabelColor = color.RGBA{0x21, 0x21, 0x21, 0xFF}
+)
+
+// FaceLabel рисует квадратную текстуру грани кости с подписью text (обычно числом).
+// Подпись центрируется в точке (cx, cy), заданной в долях размера текстуры: у треугольных
+// граней центр тяжести лежит ниже середины текстуры. underline подчеркивает подпись,
+// чтобы отличить 6 от 9 на гранях, которые видны под разными углами.
+func FaceLabel(text string, size int, cx, cy float64, underline bool) *image.RGBA {
+	face := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(face, face.Bounds(), image.NewUniform(dieFaceColor), image.Point{}, draw.Src)
+
+	tt, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Длинные подписи ("100") уменьшаются, чтобы поместиться в узкую часть треугольника
+	fontSize := float64(size) * 0.3
+	if n := utf8.RuneCountInString(text); n > 2 {
+		fontSize = fontSize * 2 / float64(n)
+	}
+	fontFace, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size: fontSize,
+		DPI:  72,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	drawer := &font.Drawer{
+		Dst:  face,
+		Src:  image.NewUniform(dieLabelColor),
+		Face: fontFace,
+	}
+
+	metrics := fontFace.Metrics()
+	width := drawer.MeasureString(text)
+	x := fixed.Int26_6(cx*float64(size)*64) - width/2
+	y := fixed.Int26_6(cy*float64(size)*64) + metrics.CapHeight/2
+	drawer.Dot = fixed.Point26_6{X: x, Y: y}
+	drawer.DrawString(text)
+
+	if underline {
+		thickness := max(1, size/40)
+		top := (y + metrics.Descent/2).Ceil()
+		line := image.Rect(x.Floor(), top, (x + width).Ceil(), top+thickness)
+		draw.Draw(face, line, image.NewUniform(dieLabelColor), image.Point{}, draw.Src)
+	}
+	return face
 }
@@ -1,5 +1,3 @@
-//go:build !ci
-
 package utils
 
 import (
@@ -27,3 +25,81 @@ func TestAddLabelToImage(t *testing.T) {
 		t.Error("AddLabelToImage returned nil")
 	}
 }
+
+func TestInitials(t *testing.T) {
+	cases := map[string]string{
+		"Ivan Petrov":        "IP",
+		"анна мария иванова": "АМ",
+		"  Bob  ":            "B",
+		"":                   "",
+	}
+	for name, want := range cases {
+		if got := Initials(name); got != want {
+			t.Errorf("Initials(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNameCard(t *testing.T) {
+	card := NameCard("Ivan Petrov", 120)
+	if card.Bounds().Dx() != 120 || card.Bounds().Dy() != 120 {
+		t.Fatalf("NameCard size = %v, want 120x120", card.Bounds())
+	}
+
+	// Фон одинаков для одного и того же имени
+	again := NameCard("Ivan Petrov", 120)
+	if card.At(0, 0) != again.At(0, 0) {
+		t.Error("Background color should be stable for the same name")
+	}
+
+	// Инициалы рисуются белым поверх фона
+	white := 0
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 120; x++ {
+			if r, g, b, _ := card.At(x, y).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+				white++
+			}
+		}
+	}
+	if white == 0 {
+		t.Error("Initials should be drawn on the card")
+	}
+}
+
+func TestFaceLabel(t *testing.T) {
+	// darkRows возвращает диапазон строк, в которых есть темные пиксели подписи
+	darkRows := func(img *image.RGBA) (first, last int) {
+		first, last = -1, -1
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				if r, _, _, _ := img.At(x, y).RGBA(); r < 0x8000 {
+					if first < 0 {
+						first = y
+					}
+					last = y
+					break
+				}
+			}
+		}
+		return first, last
+	}
+
+	face := FaceLabel("6", 100, 0.5, 0.5, false)
+	first, last := darkRows(face)
+	if first < 0 {
+		t.Fatal("The number should be drawn on the face")
+	}
+	if mid := (first + last) / 2; mid < 40 || mid > 60 {
+		t.Errorf("The number should be centered, rows %d..%d", first, last)
+	}
+
+	lower := FaceLabel("6", 100, 0.5, 0.7, false)
+	if lowerFirst, _ := darkRows(lower); lowerFirst <= first {
+		t.Error("The number should follow the requested center")
+	}
+
+	underlined := FaceLabel("6", 100, 0.5, 0.5, true)
+	if _, underLast := darkRows(underlined); underLast <= last {
+		t.Error("The underline should be drawn below the number")
+	}
+}
@@ -1,29 +1,12 @@
 package main
 
 import (
-	"github.com/olegshirko/dice_roller/pkg/assets"
-	"github.com/olegshirko/dice_roller/pkg/config"
-	"github.com/olegshirko/dice_roller/pkg/game"
-	"log"
+	"os"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/internal/cli"
+	"github.com/olegshirko/dice_roller/internal/gui"
 )
 
 func main() {
-	ebiten.SetWindowDecorated(false)
-	ebiten.SetScreenTransparent(true)
-	ebiten.SetWindowSize(config.ScreenWidth, config.ScreenHeight)
-	ebiten.SetWindowTitle("Rotating 3D Cube")
-
-	assetManager := assets.NewManager()
-	assetManager.LoadFromDirectory("img")
-
-	g := game.NewGame(assetManager)
-
-	if err := ebiten.RunGame(g); err != nil {
-		if err != ebiten.Termination {
-			log.Fatal(err)
-		}
-	}
-	log.Println("Game finished.")
+	os.Exit(cli.Run(os.Args[1:], gui.Run))
 }
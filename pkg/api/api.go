@@ -0,0 +1,218 @@
+// Package api — локальный HTTP-интерфейс управления игрой для ботов и скриптов
+// на той же машине: бросок, перезагрузка участников, отметки присутствия,
+// состояние броска, журнал результатов и поток событий. Ответы — JSON.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/events"
+	"github.com/olegshirko/dice_roller/pkg/game"
+	"github.com/olegshirko/dice_roller/pkg/history"
+)
+
+// Параметры сервера.
+const (
+	callTimeout  = 2 * time.Second // Сколько ждать, пока игра выполнит команду
+	historyLimit = 20              // Сколько результатов отдает /api/history без limit
+)
+
+// Controller — команды игры, которые выполняет API. *game.Remote передает их
+// в горутину Update.
+type Controller interface {
+	Spin(ctx context.Context) error
+	Reload(ctx context.Context) error
+	Status(ctx context.Context) (game.Status, error)
+	Participants(ctx context.Context) ([]game.Participant, error)
+	SetAbsent(ctx context.Context, label string, absent bool) (game.Participant, error)
+	Toggle(ctx context.Context, label string) (game.Participant, error)
+	History(ctx context.Context, limit int) ([]history.Entry, error)
+}
+
+var _ Controller = (*game.Remote)(nil)
+
+// NewHandler возвращает обработчик HTTP-запросов к игре c:
+//
+//	GET  /api/state                           фаза броска, последний выбранный и цикл
+//	POST /api/roll                            бросить кость, как клавишей
+//	POST /api/reload                          заново загрузить участников из настроек
+//	GET  /api/participants                    участники и отметки присутствия
+//	PUT  /api/participants/{label}            {"absent": true} — отметить отсутствующим
+//	POST /api/participants/{label}/toggle     поменять отметку
+//	GET  /api/history?limit=N                 последние результаты
+//	GET  /api/events                          поток событий броска (Server-Sent Events)
+//
+// Если журнал событий l равен nil, потока событий нет. Запросы принимаются только
+// по адресу локальной машины, а POST и PUT — только с телом в JSON (см. guard).
+func NewHandler(c Controller, l *events.Log) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, f http.HandlerFunc) {
+		mux.Handle(pattern, withTimeout(f))
+	}
+	handle("GET /api/state", func(w http.ResponseWriter, r *http.Request) {
+		status, err := c.Status(r.Context())
+		reply(w, http.StatusOK, status, err)
+	})
+	handle("POST /api/roll", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Spin(r.Context()); err != nil {
+			reply(w, 0, nil, err)
+			return
+		}
+		status, err := c.Status(r.Context())
+		reply(w, http.StatusAccepted, status, err)
+	})
+	handle("POST /api/reload", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.Reload(r.Context()); err != nil {
+			reply(w, 0, nil, err)
+			return
+		}
+		people, err := c.Participants(r.Context())
+		reply(w, http.StatusOK, people, err)
+	})
+	handle("GET /api/participants", func(w http.ResponseWriter, r *http.Request) {
+		people, err := c.Participants(r.Context())
+		reply(w, http.StatusOK, people, err)
+	})
+	handle("PUT /api/participants/{label}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Absent *bool `json:"absent"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Absent == nil {
+			replyError(w, http.StatusBadRequest, `expected a JSON body like {"absent": true}`)
+			return
+		}
+		p, err := c.SetAbsent(r.Context(), r.PathValue("label"), *body.Absent)
+		reply(w, http.StatusOK, p, err)
+	})
+	handle("POST /api/participants/{label}/toggle", func(w http.ResponseWriter, r *http.Request) {
+		p, err := c.Toggle(r.Context(), r.PathValue("label"))
+		reply(w, http.StatusOK, p, err)
+	})
+	handle("GET /api/history", func(w http.ResponseWriter, r *http.Request) {
+		limit := historyLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				replyError(w, http.StatusBadRequest, "limit must be a non-negative number")
+				return
+			}
+			limit = n
+		}
+		entries, err := c.History(r.Context(), limit)
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		reply(w, http.StatusOK, entries, err)
+	})
+	if l != nil {
+		mux.Handle("GET /api/events", eventStream(l))
+	}
+	return guard(mux)
+}
+
+// guard защищает API от страниц в браузере. Имя хоста в запросе, кроме localhost,
+// отклоняется: иначе сайт, имя которого указывает на 127.0.0.1 (DNS rebinding),
+// читал бы ответы. IP-адрес в Host подделать так нельзя, поэтому он принимается.
+// Запросы, меняющие состояние, должны иметь Content-Type: application/json:
+// HTML-форма и простой fetch без предварительного запроса CORS его не ставят.
+func guard(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host) {
+			replyError(w, http.StatusForbidden, "the API accepts only requests to localhost or an IP address")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				replyError(w, http.StatusUnsupportedMediaType, "requests that change state need Content-Type: application/json")
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost сообщает, что заголовок Host указывает на localhost или IP-адрес.
+func allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport // Порт не указан
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	host = strings.ToLower(host)
+	return host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// withTimeout ограничивает ожидание игры: если Update не выполняет команды
+// (окно свернуто на некоторых системах или игра зависла), запрос не висит вечно.
+func withTimeout(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), callTimeout)
+		defer cancel()
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// reply отвечает значением v с кодом status или ошибкой err.
+func reply(w http.ResponseWriter, status int, v any, err error) {
+	if err != nil {
+		replyError(w, errorStatus(err), err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("API: could not write the response: %v", err)
+	}
+}
+
+func replyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// errorStatus подбирает HTTP-код для ошибки игры.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, game.ErrUnknownParticipant):
+		return http.StatusNotFound
+	case errors.Is(err, game.ErrRolling), errors.Is(err, game.ErrNobodyLeft), errors.Is(err, game.ErrDiceMode):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// Serve начинает слушать addr и обслуживает запросы в фоне. Ошибка адреса
+// возвращается сразу; адрес, который слушает сервер, есть в Addr результата.
+func Serve(addr string, h http.Handler) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if host, _, _ := net.SplitHostPort(ln.Addr().String()); !net.ParseIP(host).IsLoopback() {
+		log.Printf("API listens on %s and is reachable from other machines; it has no authentication.", ln.Addr())
+	}
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
+	log.Printf("API listening on http://%s/api/", srv.Addr)
+	return srv, nil
+}
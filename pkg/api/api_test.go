@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/game"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGame выполняет команды сразу, как это делал бы Update.
+type fakeGame struct {
+	status  game.Status
+	people  []game.Participant
+	entries []history.Entry
+	hang    bool // Игра не отвечает
+}
+
+func (f *fakeGame) wait(ctx context.Context) error {
+	if f.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (f *fakeGame) Spin(ctx context.Context) error {
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	if f.status.Phase != roll.PhaseIdle {
+		return game.ErrRolling
+	}
+	f.status.Phase = roll.PhaseRotating
+	return nil
+}
+
+func (f *fakeGame) Reload(ctx context.Context) error {
+	f.status.Cycle++
+	return f.wait(ctx)
+}
+
+func (f *fakeGame) Status(ctx context.Context) (game.Status, error) {
+	return f.status, f.wait(ctx)
+}
+
+func (f *fakeGame) Participants(ctx context.Context) ([]game.Participant, error) {
+	return f.people, f.wait(ctx)
+}
+
+func (f *fakeGame) SetAbsent(ctx context.Context, label string, absent bool) (game.Participant, error) {
+	for i, p := range f.people {
+		if p.Label == label {
+			f.people[i].Absent = absent
+			return f.people[i], f.wait(ctx)
+		}
+	}
+	return game.Participant{}, fmt.Errorf("%w: %s", game.ErrUnknownParticipant, label)
+}
+
+func (f *fakeGame) Toggle(ctx context.Context, label string) (game.Participant, error) {
+	for _, p := range f.people {
+		if p.Label == label {
+			return f.SetAbsent(ctx, label, !p.Absent)
+		}
+	}
+	return game.Participant{}, fmt.Errorf("%w: %s", game.ErrUnknownParticipant, label)
+}
+
+func (f *fakeGame) History(ctx context.Context, limit int) ([]history.Entry, error) {
+	return history.Tail(f.entries, limit), f.wait(ctx)
+}
+
+// call выполняет запрос и разбирает JSON-ответ в out.
+func call(t *testing.T, h http.Handler, method, path, body string, out any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "http://127.0.0.1:8765"+path, strings.NewReader(body))
+	if method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	h.ServeHTTP(rec, req)
+	if out != nil {
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestHandler_Roll(t *testing.T) {
+	f := &fakeGame{status: game.Status{Phase: roll.PhaseIdle, Cycle: 1}}
+	h := NewHandler(f, nil)
+
+	var status game.Status
+	assert.Equal(t, http.StatusAccepted, call(t, h, "POST", "/api/roll", "", &status))
+	assert.Equal(t, roll.PhaseRotating, status.Phase)
+
+	var apiErr struct{ Error string }
+	assert.Equal(t, http.StatusConflict, call(t, h, "POST", "/api/roll", "", &apiErr), "The die is still rolling")
+	assert.Equal(t, game.ErrRolling.Error(), apiErr.Error)
+
+	f.status = game.Status{Phase: roll.PhaseIdle, LastWinner: "Anna", Cycle: 2}
+	var raw map[string]any
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/state", "", &raw))
+	assert.Equal(t, map[string]any{"phase": "idle", "last_winner": "Anna", "cycle": 2.0}, raw)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, call(t, h, "GET", "/api/roll", "", nil))
+}
+
+func TestHandler_Participants(t *testing.T) {
+	f := &fakeGame{people: []game.Participant{{Label: "Anna"}, {Label: "Bob Smith"}}}
+	h := NewHandler(f, nil)
+
+	var p game.Participant
+	assert.Equal(t, http.StatusOK, call(t, h, "PUT", "/api/participants/Bob%20Smith", `{"absent": true}`, &p))
+	assert.Equal(t, game.Participant{Label: "Bob Smith", Absent: true}, p)
+	assert.Equal(t, http.StatusOK, call(t, h, "POST", "/api/participants/Anna/toggle", "", &p))
+	assert.True(t, p.Absent)
+
+	var people []game.Participant
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/participants", "", &people))
+	assert.Equal(t, []game.Participant{{Label: "Anna", Absent: true}, {Label: "Bob Smith", Absent: true}}, people)
+
+	assert.Equal(t, http.StatusNotFound, call(t, h, "POST", "/api/participants/Eve/toggle", "", nil))
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "PUT", "/api/participants/Anna", `{}`, nil))
+
+	assert.Equal(t, http.StatusOK, call(t, h, "POST", "/api/reload", "", &people))
+	assert.Len(t, people, 2)
+}
+
+func TestHandler_History(t *testing.T) {
+	f := &fakeGame{}
+	h := NewHandler(f, nil)
+
+	var entries []history.Entry
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/history", "", &entries))
+	assert.NotNil(t, entries, "An empty history is an empty array, not null")
+
+	for i := 0; i < 30; i++ {
+		f.entries = append(f.entries, history.Entry{Time: time.Unix(int64(i), 0).UTC(), Label: fmt.Sprint("person", i), Cycle: 1})
+	}
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/history", "", &entries))
+	assert.Len(t, entries, historyLimit)
+	assert.Equal(t, http.StatusOK, call(t, h, "GET", "/api/history?limit=2", "", &entries))
+	assert.Equal(t, f.entries[28:], entries)
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "GET", "/api/history?limit=many", "", nil))
+}
+
+func TestHandler_GameNotResponding(t *testing.T) {
+	h := NewHandler(&fakeGame{hang: true}, nil)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "http://localhost:8765/api/state", nil)
+	ctx, cancel := context.WithTimeout(req.Context(), 10*time.Millisecond)
+	defer cancel()
+	h.ServeHTTP(rec, req.WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestHandler_Host(t *testing.T) {
+	h := NewHandler(&fakeGame{}, nil)
+	for host, want := range map[string]int{
+		"127.0.0.1:8765":      http.StatusOK,
+		"[::1]:8765":          http.StatusOK,
+		"localhost:8765":      http.StatusOK,
+		"LOCALHOST":           http.StatusOK,
+		"app.localhost:8765":  http.StatusOK,
+		"192.168.1.5:8765":    http.StatusOK,
+		"evil.example:8765":   http.StatusForbidden, // DNS rebinding: имя указывает на 127.0.0.1
+		"localhost.evil.test": http.StatusForbidden,
+		"":                    http.StatusForbidden,
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/state", nil)
+		req.Host = host
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, host)
+	}
+}
+
+func TestHandler_ContentType(t *testing.T) {
+	f := &fakeGame{status: game.Status{Phase: roll.PhaseIdle}, people: []game.Participant{{Label: "Anna"}}}
+	h := NewHandler(f, nil)
+	send := func(method, path, contentType, body string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "http://localhost:8765"+path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Так отправляет HTML-форма или простой запрос с чужой страницы
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "/api/roll", "", ""))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "/api/roll", "application/x-www-form-urlencoded", "a=1"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "/api/participants/Anna/toggle", "text/plain", ""))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("PUT", "/api/participants/Anna", "text/plain", `{"absent": true}`))
+	assert.Equal(t, roll.PhaseIdle, f.status.Phase, "Rejected requests do not reach the game")
+	assert.False(t, f.people[0].Absent)
+
+	assert.Equal(t, http.StatusAccepted, send("POST", "/api/roll", "application/json; charset=utf-8", ""))
+	assert.Equal(t, http.StatusOK, send("GET", "/api/state", "", ""), "Reading needs no Content-Type")
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/events"
+)
+
+// Параметры потока событий.
+const (
+	heartbeat  = 15 * time.Second // Как часто отправлять комментарий, чтобы прокси не закрыли соединение
+	retryDelay = time.Second      // Через сколько браузер переподключается после обрыва
+)
+
+// eventStream отдает события броска в формате Server-Sent Events. Номер события
+// передается в поле id, поэтому EventSource при переподключении сам присылает
+// Last-Event-ID и получает пропущенные события. Скрипты могут передать тот же
+// номер в параметре after.
+func eventStream(l *events.Log) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after, err := lastEventID(r)
+		if err != nil {
+			replyError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			replyError(w, http.StatusInternalServerError, "streaming is not supported")
+			return
+		}
+
+		missed, ch, cancel := l.Subscribe(after)
+		defer cancel()
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", retryDelay.Milliseconds())
+		for _, e := range missed {
+			writeEvent(w, e)
+		}
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return // Клиент не успевал читать; он переподключится с последним номером
+				}
+				writeEvent(w, e)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// lastEventID возвращает номер последнего полученного клиентом события: из
+// заголовка Last-Event-ID или параметра after; 0 — клиент подключается впервые.
+func lastEventID(r *http.Request) (uint64, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("after")
+	}
+	if id == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return n, nil
+}
+
+// writeEvent записывает событие: вид в поле event, номер в поле id и JSON в поле data.
+func writeEvent(w http.ResponseWriter, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+}
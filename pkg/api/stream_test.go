@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventStream(t *testing.T) {
+	l := events.NewLog(events.DefaultKeep)
+	h := NewHandler(&fakeGame{}, l)
+	first := l.Publish(events.Event{Type: events.SpinStarted, Cycle: 1}).Seq
+	l.Publish(events.Event{Type: events.PhaseChanged, From: "idle", Phase: "rotating", Cycle: 1})
+
+	// Клиент уже получил первое событие и переподключается
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "http://localhost:8765/api/events", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(first, 10))
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(rec, req)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	l.Publish(events.Event{Type: events.WinnerDecided, Label: "Anna", Cycle: 1})
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "retry: 1000\n\n"), body)
+	assert.NotContains(t, body, fmt.Sprintf("id: %d\n", first), "Events the client has are not sent again")
+	assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: phase_changed\ndata: {\"seq\":%[1]d,", first+1))
+	assert.Contains(t, body, `"from":"idle"`)
+	assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: winner_decided\n", first+2))
+	assert.Contains(t, body, `"label":"Anna"`)
+	assert.Less(t, strings.Index(body, fmt.Sprintf("id: %d\n", first+1)), strings.Index(body, fmt.Sprintf("id: %d\n", first+2)))
+}
+
+func TestEventStream_BadID(t *testing.T) {
+	h := NewHandler(&fakeGame{}, events.NewLog(events.DefaultKeep))
+	assert.Equal(t, http.StatusBadRequest, call(t, h, "GET", "/api/events?after=last", "", nil))
+
+	// Без журнала событий потока нет
+	assert.Equal(t, http.StatusNotFound, call(t, NewHandler(&fakeGame{}, nil), "GET", "/api/events", "", nil))
+}
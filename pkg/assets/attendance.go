@@ -0,0 +1,129 @@
+package assets
+
+import (
+	"encoding/json"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// attendanceFile — формат файла, в котором хранятся отметки об отсутствии.
+type attendanceFile struct {
+	Absent []string `json:"absent"`
+}
+
+// DefaultAttendancePath возвращает путь к файлу отметок в пользовательском каталоге настроек.
+func DefaultAttendancePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Could not find user config directory: %v. Attendance will not be saved.", err)
+		return ""
+	}
+	return filepath.Join(dir, "dice_roller", "attendance.json")
+}
+
+// IsAbsent сообщает, отмечен ли участник с таким именем как отсутствующий.
+func (m *Manager) IsAbsent(label string) bool {
+	return m.absent[label]
+}
+
+// SetAbsent отмечает участника отсутствующим или вернувшимся. Текстуры
+// отсутствующего убираются из пула доступных и откладываются; при возвращении
+// отложенные текстуры снова попадают в пул на случайные места.
+// Отметки сохраняются в AttendancePath, если он задан.
+func (m *Manager) SetAbsent(label string, absent bool) {
+	if m.absent == nil {
+		m.absent = map[string]bool{}
+	}
+	if m.absent[label] == absent {
+		return
+	}
+
+	if absent {
+		m.absent[label] = true
+		available := m.AvailableTextures[:0]
+		for _, tex := range m.AvailableTextures {
+			if m.labels[tex] == label {
+				m.benched = append(m.benched, tex)
+			} else {
+				available = append(available, tex)
+			}
+		}
+		m.AvailableTextures = available
+		log.Printf("%s is marked absent.", label)
+	} else {
+		delete(m.absent, label)
+		benched := m.benched[:0]
+		for _, tex := range m.benched {
+			if m.labels[tex] != label {
+				benched = append(benched, tex)
+				continue
+			}
+			i := m.Random.Intn(len(m.AvailableTextures) + 1)
+			m.AvailableTextures = append(m.AvailableTextures, nil)
+			copy(m.AvailableTextures[i+1:], m.AvailableTextures[i:])
+			m.AvailableTextures[i] = tex
+		}
+		m.benched = benched
+		log.Printf("%s is marked present.", label)
+	}
+
+	m.saveAttendance()
+}
+
+// Bench откладывает текстуру, снятую с грани, до возвращения участника.
+func (m *Manager) Bench(tex image.Image) {
+	m.benched = append(m.benched, tex)
+}
+
+// LoadAttendance читает отметки об отсутствии из AttendancePath.
+// Отсутствие файла не считается ошибкой.
+func (m *Manager) LoadAttendance() {
+	if m.AttendancePath == "" {
+		return
+	}
+	data, err := os.ReadFile(m.AttendancePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Could not read attendance from %s: %v", m.AttendancePath, err)
+		}
+		return
+	}
+
+	var file attendanceFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		log.Printf("Could not parse attendance from %s: %v", m.AttendancePath, err)
+		return
+	}
+	m.absent = map[string]bool{}
+	for _, label := range file.Absent {
+		m.absent[label] = true
+	}
+	log.Printf("Loaded attendance: %d people marked absent.", len(m.absent))
+}
+
+// saveAttendance записывает отметки об отсутствии в AttendancePath.
+func (m *Manager) saveAttendance() {
+	if m.AttendancePath == "" {
+		return
+	}
+
+	file := attendanceFile{Absent: []string{}}
+	for label := range m.absent {
+		file.Absent = append(file.Absent, label)
+	}
+	sort.Strings(file.Absent)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(m.AttendancePath), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(m.AttendancePath, data, 0o644)
+	}
+	if err != nil {
+		log.Printf("Could not save attendance to %s: %v", m.AttendancePath, err)
+	}
+}
@@ -0,0 +1,93 @@
+package assets
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAttendanceManager создает менеджер с участниками names в пуле доступных текстур.
+func newAttendanceManager(t *testing.T, names ...string) *Manager {
+	m := newTestManager(&mockTextureLoader{})
+	m.AttendancePath = filepath.Join(t.TempDir(), "attendance.json")
+	for _, name := range names {
+		m.AddTexture(image.NewRGBA(image.Rect(0, 0, 1, 1)), name)
+	}
+	m.prepareAvailableTextures()
+	return m
+}
+
+func poolLabels(m *Manager) []string {
+	labels := []string{}
+	for _, tex := range m.AvailableTextures {
+		labels = append(labels, m.LabelOf(tex))
+	}
+	return labels
+}
+
+func TestSetAbsent_UpdatesPool(t *testing.T) {
+	m := newAttendanceManager(t, "anna", "bob", "carol")
+
+	m.SetAbsent("bob", true)
+	assert.True(t, m.IsAbsent("bob"))
+	assert.ElementsMatch(t, []string{"anna", "carol"}, poolLabels(m), "Absent person should leave the pool")
+
+	m.SetAbsent("bob", true)
+	assert.Len(t, m.AvailableTextures, 2, "Marking twice should change nothing")
+
+	m.SetAbsent("bob", false)
+	assert.False(t, m.IsAbsent("bob"))
+	assert.ElementsMatch(t, []string{"anna", "bob", "carol"}, poolLabels(m), "Returning person should be back in the pool")
+}
+
+func TestSetAbsent_Bench(t *testing.T) {
+	m := newAttendanceManager(t, "anna", "bob")
+	dealt := m.AvailableTextures[len(m.AvailableTextures)-1]
+	m.AvailableTextures = m.AvailableTextures[:len(m.AvailableTextures)-1]
+	label := m.LabelOf(dealt)
+
+	// Текстура снята с грани, пока участник отсутствует
+	m.SetAbsent(label, true)
+	m.Bench(dealt)
+	assert.NotContains(t, poolLabels(m), label)
+
+	m.SetAbsent(label, false)
+	assert.Contains(t, poolLabels(m), label, "Benched texture should return to the pool")
+}
+
+func TestAttendance_Persisted(t *testing.T) {
+	m := newAttendanceManager(t, "anna", "bob", "carol")
+	m.SetAbsent("carol", true)
+	m.SetAbsent("anna", true)
+	m.SetAbsent("anna", false)
+
+	data, err := os.ReadFile(m.AttendancePath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"absent": ["carol"]}`, string(data))
+
+	// Новый запуск: отметки загружаются до текстур, и отсутствующие не попадают в пул
+	next := newTestManager(&mockTextureLoader{})
+	next.AttendancePath = m.AttendancePath
+	next.LoadAttendance()
+	assert.True(t, next.IsAbsent("carol"))
+
+	dir := t.TempDir()
+	for _, name := range []string{"anna", "bob", "carol"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%s.png", name)), nil, 0o644))
+	}
+	require.True(t, next.LoadFromDirectory(dir))
+	assert.Len(t, next.AllTextures, 3)
+	assert.ElementsMatch(t, []string{"anna", "bob"}, poolLabels(next))
+}
+
+func TestLoadAttendance_MissingFile(t *testing.T) {
+	m := newTestManager(&mockTextureLoader{})
+	m.AttendancePath = filepath.Join(t.TempDir(), "missing", "attendance.json")
+	m.LoadAttendance()
+	assert.False(t, m.IsAbsent("anyone"))
+}
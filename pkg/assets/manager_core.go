@@ -4,43 +4,63 @@ import (
 	"github.com/olegshirko/dice_roller/internal/utils"
 	"github.com/olegshirko/dice_roller/pkg/config"
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/random"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
 	"log"
-	"math/rand"
 	"os"
 	"path/filepath"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // textureLoader defines the interface for loading textures.
 // This allows for mocking in tests.
 type textureLoader interface {
-	Load(path string) *ebiten.Image
+	Load(path string) image.Image
+	NameCard(name, photoPath string) image.Image
 }
 
-// ebitenTextureLoader is the concrete implementation that uses Ebiten to load images.
-type ebitenTextureLoader struct{}
+// fileTextureLoader is the concrete implementation that reads images from files.
+type fileTextureLoader struct{}
 
 // Load implements the textureLoader interface.
-func (l *ebitenTextureLoader) Load(path string) *ebiten.Image {
+func (l *fileTextureLoader) Load(path string) image.Image {
 	return loadTextureFromFile(path)
 }
 
+// NameCard implements the textureLoader interface.
+func (l *fileTextureLoader) NameCard(name, photoPath string) image.Image {
+	return nameCardTexture(name, photoPath)
+}
+
+// Orderer задает порядок, в котором участники попадают из пула на грани.
+// Ему соответствует selection.Policy.
+type Orderer interface {
+	// Order возвращает индексы labels в порядке очереди: первым на грань попадает labels[order[0]].
+	Order(labels []string, rnd random.Source) []int
+}
+
 type Manager struct {
-	AllTextures       []*ebiten.Image // Все когда-либо загруженные текстуры
-	AvailableTextures []*ebiten.Image // Текстуры, доступные для использования
+	AllTextures       []image.Image // Все когда-либо загруженные текстуры
+	AvailableTextures []image.Image // Текстуры, доступные для использования
+	Random            random.Source // Источник случайности для перемешивания пула
+	Orderer           Orderer       // Порядок пула; nil перемешивает его равновероятно
 	loader            textureLoader
+	labels            map[image.Image]string // Имена текстур (имя файла без расширения)
+	AttendancePath    string                 // Файл, в котором запоминаются отсутствующие
+	absent            map[string]bool        // Имена отсутствующих участников
+	benched           []image.Image          // Текстуры отсутствующих, убранные из пула
 }
 
 // NewManager создает новый менеджер ассетов.
-func NewManager() *Manager {
+// Пул текстур перемешивается с помощью rnd.
+func NewManager(rnd random.Source) *Manager {
 	return &Manager{
-		AllTextures:       []*ebiten.Image{},
-		AvailableTextures: []*ebiten.Image{},
-		loader:            &ebitenTextureLoader{},
+		AllTextures:       []image.Image{},
+		AvailableTextures: []image.Image{},
+		Random:            rnd,
+		loader:            &fileTextureLoader{},
+		labels:            map[image.Image]string{},
 	}
 }
 
@@ -70,7 +90,7 @@ func (m *Manager) LoadFromDirectory(dir string) bool {
 
 		fullPath := filepath.Join(dir, file.Name())
 		if tex := m.loader.Load(fullPath); tex != nil {
-			m.AllTextures = append(m.AllTextures, tex)
+			m.AddTexture(tex, labelFromPath(fullPath))
 			loaded = true
 		}
 	}
@@ -86,60 +106,115 @@ func (m *Manager) LoadFromDirectory(dir string) bool {
 }
 
 // loadTextureFromFile загружает одну текстуру из файла и добавляет на нее метку.
-func loadTextureFromFile(path string) *ebiten.Image {
-	file, err := os.Open(path)
+func loadTextureFromFile(path string) image.Image {
+	img, err := decodeImage(path)
 	if err != nil {
-		log.Printf("Error opening file %s: %v", path, err)
+		log.Printf("Error loading image %s: %v", path, err)
 		return nil
 	}
-	defer file.Close()
 
-	img, _, err := image.Decode(file)
+	labeledImg := utils.AddLabelToImage(img, labelFromPath(path))
+	log.Printf("Loaded and labeled texture from %s", path)
+	return labeledImg
+}
+
+// decodeImage читает изображение из файла.
+func decodeImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Printf("Error decoding image %s: %v", path, err)
-		return nil
+		return nil, err
 	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
 
+// labelFromPath возвращает имя текстуры: имя файла без расширения.
+func labelFromPath(path string) string {
 	label := filepath.Base(path)
-	ext := filepath.Ext(label)
-	label = label[:len(label)-len(ext)]
+	return label[:len(label)-len(filepath.Ext(label))]
+}
 
-	labeledImg := utils.AddLabelToImage(img, label)
-	log.Printf("Loaded and labeled texture from %s", path)
-	return labeledImg
+// AddTexture добавляет текстуру с заданным именем в список всех текстур.
+// Пул доступных текстур при этом не меняется.
+func (m *Manager) AddTexture(tex image.Image, label string) {
+	if m.labels == nil {
+		m.labels = map[image.Image]string{}
+	}
+	m.AllTextures = append(m.AllTextures, tex)
+	m.labels[tex] = label
 }
 
-// prepareAvailableTextures копирует все загруженные текстуры в пул доступных и перемешивает их.
+// LabelOf возвращает имя текстуры или пустую строку, если текстура неизвестна.
+func (m *Manager) LabelOf(tex image.Image) string {
+	return m.labels[tex]
+}
+
+// Reshuffle заново собирает пул доступных текстур из всех загруженных и перемешивает его.
+func (m *Manager) Reshuffle() {
+	m.prepareAvailableTextures()
+}
+
+// prepareAvailableTextures копирует все загруженные текстуры в пул доступных и перемешивает их
+// или выстраивает в порядке Orderer. Текстуры отсутствующих участников в пул не попадают,
+// а откладываются до их возвращения.
 func (m *Manager) prepareAvailableTextures() {
-	m.AvailableTextures = make([]*ebiten.Image, len(m.AllTextures))
-	copy(m.AvailableTextures, m.AllTextures)
-	rand.Shuffle(len(m.AvailableTextures), func(i, j int) {
-		m.AvailableTextures[i], m.AvailableTextures[j] = m.AvailableTextures[j], m.AvailableTextures[i]
-	})
-	log.Printf("Loaded %d textures. Available pool created and shuffled.", len(m.AvailableTextures))
+	m.AvailableTextures = make([]image.Image, 0, len(m.AllTextures))
+	m.benched = nil
+	for _, tex := range m.AllTextures {
+		if m.absent[m.labels[tex]] {
+			m.benched = append(m.benched, tex)
+		} else {
+			m.AvailableTextures = append(m.AvailableTextures, tex)
+		}
+	}
+	if m.Orderer == nil {
+		m.Random.Shuffle(len(m.AvailableTextures), func(i, j int) {
+			m.AvailableTextures[i], m.AvailableTextures[j] = m.AvailableTextures[j], m.AvailableTextures[i]
+		})
+		log.Printf("Loaded %d textures. Available pool created and shuffled.", len(m.AvailableTextures))
+		return
+	}
+	m.orderPool()
+	log.Printf("Loaded %d textures. Available pool created and ordered by the selection policy.", len(m.AvailableTextures))
 }
 
-// SetInitialTextures устанавливает начальные текстуры на грани куба.
-func (m *Manager) SetInitialTextures(faces *[6]cube.Face, isGrey *[6]bool) {
+// orderPool выстраивает пул в порядке Orderer. Текстуры выдаются с конца пула,
+// поэтому первый в очереди оказывается последним.
+func (m *Manager) orderPool() {
+	pool := m.AvailableTextures
+	labels := make([]string, len(pool))
+	for i, tex := range pool {
+		labels[i] = m.labels[tex]
+	}
+	m.AvailableTextures = make([]image.Image, len(pool))
+	for k, i := range m.Orderer.Order(labels, m.Random) {
+		m.AvailableTextures[len(pool)-1-k] = pool[i]
+	}
+}
+
+// SetInitialTextures устанавливает начальные текстуры на грани кости.
+func (m *Manager) SetInitialTextures(faces []cube.Face, isGrey []bool) {
 	if len(m.AvailableTextures) == 0 {
 		log.Println("No available textures to set on start.")
-		for i := 0; i < 6; i++ {
-			(*faces)[i].Texture = config.EmptyImage
-			(*isGrey)[i] = true
+		for i := range faces {
+			faces[i].Texture = config.EmptyImage
+			isGrey[i] = true
 		}
 		return
 	}
 
-	log.Println("Setting initial textures on cube faces...")
-	for i := 0; i < 6; i++ {
+	log.Println("Setting initial textures on die faces...")
+	for i := range faces {
 		if len(m.AvailableTextures) > 0 {
 			newTex := m.AvailableTextures[len(m.AvailableTextures)-1]
 			m.AvailableTextures = m.AvailableTextures[:len(m.AvailableTextures)-1]
-			(*faces)[i].Texture = newTex
-			(*isGrey)[i] = false
+			faces[i].Texture = newTex
+			isGrey[i] = false
 		} else {
-			(*faces)[i].Texture = config.GreyImage
-			(*isGrey)[i] = true
+			faces[i].Texture = config.GreyImage
+			isGrey[i] = true
 			log.Printf("Available textures ran out. Face %d is set to grey.", i)
 		}
 	}
@@ -147,17 +222,17 @@ func (m *Manager) SetInitialTextures(faces *[6]cube.Face, isGrey *[6]bool) {
 }
 
 // ReplaceFaceTexture заменяет текстуру на указанной грани.
-func (m *Manager) ReplaceFaceTexture(faceIndex int, faces *[6]cube.Face, isGrey *[6]bool) {
-	if faceIndex < 0 || faceIndex >= 6 {
+func (m *Manager) ReplaceFaceTexture(faceIndex int, faces []cube.Face, isGrey []bool) {
+	if faceIndex < 0 || faceIndex >= len(faces) {
 		return
 	}
 
 	if len(m.AvailableTextures) > 0 {
 		newTexture := m.AvailableTextures[len(m.AvailableTextures)-1]
 		m.AvailableTextures = m.AvailableTextures[:len(m.AvailableTextures)-1]
-		(*faces)[faceIndex].Texture = newTexture
-		(*isGrey)[faceIndex] = false
+		faces[faceIndex].Texture = newTexture
+		isGrey[faceIndex] = false
 	} else {
-		(*isGrey)[faceIndex] = true
+		isGrey[faceIndex] = true
 	}
-}
\ No newline at end of file
+}
@@ -1,35 +1,33 @@
-//go:build !ci
-
 package assets
 
 import (
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/random"
 	"image"
 	"image/color"
 	"image/png"
 	"os"
+	"sort"
 	"testing"
 
-	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/stretchr/testify/assert"
 )
 
-
 // TestNewManager проверяет конструктор NewManager.
 func TestNewManager(t *testing.T) {
-	m := NewManager()
+	m := NewManager(random.NewPCG(1))
 	assert.NotNil(t, m, "NewManager should not return nil")
 	assert.NotNil(t, m.AllTextures, "AllTextures should be initialized")
 	assert.Empty(t, m.AllTextures, "AllTextures should be empty")
 	assert.NotNil(t, m.AvailableTextures, "AvailableTextures should be initialized")
 	assert.Empty(t, m.AvailableTextures, "AvailableTextures should be empty")
 	assert.NotNil(t, m.loader, "loader should be initialized")
-	_, ok := m.loader.(*ebitenTextureLoader)
-	assert.True(t, ok, "loader should be of type ebitenTextureLoader")
+	_, ok := m.loader.(*fileTextureLoader)
+	assert.True(t, ok, "loader should be of type fileTextureLoader")
 }
 
-// TestEbitenTextureLoader_Load проверяет реальную загрузку текстуры.
-func TestEbitenTextureLoader_Load(t *testing.T) {
+// TestFileTextureLoader_Load проверяет реальную загрузку текстуры.
+func TestFileTextureLoader_Load(t *testing.T) {
 	// Создаем временный PNG файл
 	tmpFile, err := os.CreateTemp("", "test_*.png")
 	assert.NoError(t, err)
@@ -41,9 +39,9 @@ func TestEbitenTextureLoader_Load(t *testing.T) {
 	assert.NoError(t, err)
 	tmpFile.Close()
 
-	loader := &ebitenTextureLoader{}
-	ebitenImg := loader.Load(tmpFile.Name())
-	assert.NotNil(t, ebitenImg, "Load should return a non-nil image for a valid file")
+	loader := &fileTextureLoader{}
+	tex := loader.Load(tmpFile.Name())
+	assert.NotNil(t, tex, "Load should return a non-nil image for a valid file")
 }
 
 // TestLoadTextureFromFile_Success проверяет успешную загрузку из файла.
@@ -60,14 +58,14 @@ func TestLoadTextureFromFile_Success(t *testing.T) {
 	assert.NoError(t, err)
 	tmpFile.Close()
 
-	ebitenImg := loadTextureFromFile(path)
-	assert.NotNil(t, ebitenImg, "Should successfully load a valid image file")
+	tex := loadTextureFromFile(path)
+	assert.NotNil(t, tex, "Should successfully load a valid image file")
 }
 
 // TestLoadTextureFromFile_FileNotExist проверяет случай, когда файл не существует.
 func TestLoadTextureFromFile_FileNotExist(t *testing.T) {
-	ebitenImg := loadTextureFromFile("non_existent_file.png")
-	assert.Nil(t, ebitenImg, "Should return nil for a non-existent file")
+	tex := loadTextureFromFile("non_existent_file.png")
+	assert.Nil(t, tex, "Should return nil for a non-existent file")
 }
 
 // TestLoadTextureFromFile_InvalidImage проверяет обработку некорректного формата изображения.
@@ -81,21 +79,21 @@ func TestLoadTextureFromFile_InvalidImage(t *testing.T) {
 	assert.NoError(t, err)
 	tmpFile.Close()
 
-	ebitenImg := loadTextureFromFile(path)
-	assert.Nil(t, ebitenImg, "Should return nil for a file that is not a valid image")
+	tex := loadTextureFromFile(path)
+	assert.Nil(t, tex, "Should return nil for a file that is not a valid image")
 }
 
 // TestSetInitialTextures_WithAvailableTextures проверяет установку начальных текстур.
 func TestSetInitialTextures_WithAvailableTextures(t *testing.T) {
-	m := NewManager()
-	tex1 := ebiten.NewImage(1, 1)
-	tex2 := ebiten.NewImage(1, 1)
-	m.AvailableTextures = []*ebiten.Image{tex1, tex2}
+	m := NewManager(random.NewPCG(1))
+	tex1 := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	tex2 := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	m.AvailableTextures = []image.Image{tex1, tex2}
 
-	var faces [6]cube.Face
-	var isGrey [6]bool
+	faces := make([]cube.Face, 6)
+	isGrey := make([]bool, 6)
 
-	m.SetInitialTextures(&faces, &isGrey)
+	m.SetInitialTextures(faces, isGrey)
 
 	assert.Equal(t, tex2, faces[0].Texture, "Face 0 should have the last available texture")
 	assert.Equal(t, tex1, faces[1].Texture, "Face 1 should have the second to last available texture")
@@ -104,13 +102,43 @@ func TestSetInitialTextures_WithAvailableTextures(t *testing.T) {
 	assert.Empty(t, m.AvailableTextures, "AvailableTextures should be empty after setting initial textures")
 }
 
+// reverseOrderer выстраивает участников в обратном порядке по имени.
+type reverseOrderer struct{}
+
+func (reverseOrderer) Order(labels []string, _ random.Source) []int {
+	order := make([]int, len(labels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return labels[order[a]] > labels[order[b]] })
+	return order
+}
+
+// TestReshuffle_Orderer проверяет, что Orderer задает порядок пула и начальных граней.
+func TestReshuffle_Orderer(t *testing.T) {
+	m := NewManager(random.NewPCG(1))
+	m.Orderer = reverseOrderer{}
+	for _, label := range []string{"anna", "bob", "carol", "dave"} {
+		m.AddTexture(image.NewRGBA(image.Rect(0, 0, 1, 1)), label)
+	}
+	m.Reshuffle()
+
+	faces := make([]cube.Face, 2)
+	isGrey := make([]bool, 2)
+	m.SetInitialTextures(faces, isGrey)
+
+	assert.Equal(t, "dave", m.LabelOf(faces[0].Texture), "The first in the order goes on the die first")
+	assert.Equal(t, "carol", m.LabelOf(faces[1].Texture))
+	assert.Equal(t, "bob", m.LabelOf(m.AvailableTextures[len(m.AvailableTextures)-1]), "The pool continues the order")
+}
+
 // TestSetInitialTextures_NoAvailableTextures проверяет установку, когда нет доступных текстур.
 func TestSetInitialTextures_NoAvailableTextures(t *testing.T) {
-	m := NewManager()
-	var faces [6]cube.Face
-	var isGrey [6]bool
+	m := NewManager(random.NewPCG(1))
+	faces := make([]cube.Face, 6)
+	isGrey := make([]bool, 6)
 
-	m.SetInitialTextures(&faces, &isGrey)
+	m.SetInitialTextures(faces, isGrey)
 
 	for i := 0; i < 6; i++ {
 		assert.NotNil(t, faces[i].Texture, "Face %d should have a texture", i)
@@ -120,15 +148,15 @@ func TestSetInitialTextures_NoAvailableTextures(t *testing.T) {
 
 // TestReplaceFaceTexture_WithAvailableTextures проверяет замену текстуры грани.
 func TestReplaceFaceTexture_WithAvailableTextures(t *testing.T) {
-	m := NewManager()
-	newTex := ebiten.NewImage(1, 1)
-	m.AvailableTextures = []*ebiten.Image{newTex}
+	m := NewManager(random.NewPCG(1))
+	newTex := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	m.AvailableTextures = []image.Image{newTex}
 
-	var faces [6]cube.Face
-	var isGrey [6]bool
+	faces := make([]cube.Face, 6)
+	isGrey := make([]bool, 6)
 	faceIndex := 2
 
-	m.ReplaceFaceTexture(faceIndex, &faces, &isGrey)
+	m.ReplaceFaceTexture(faceIndex, faces, isGrey)
 
 	assert.Equal(t, newTex, faces[faceIndex].Texture, "Face texture should be replaced")
 	assert.False(t, isGrey[faceIndex], "Face should not be grey after replacement")
@@ -137,14 +165,14 @@ func TestReplaceFaceTexture_WithAvailableTextures(t *testing.T) {
 
 // TestReplaceFaceTexture_NoAvailableTextures проверяет замену, когда нет доступных текстур.
 func TestReplaceFaceTexture_NoAvailableTextures(t *testing.T) {
-	m := NewManager()
-	var faces [6]cube.Face
-	var isGrey [6]bool
+	m := NewManager(random.NewPCG(1))
+	faces := make([]cube.Face, 6)
+	isGrey := make([]bool, 6)
 	faceIndex := 3
-	faces[faceIndex].Texture = ebiten.NewImage(1, 1) // Изначальная текстура
+	faces[faceIndex].Texture = image.NewRGBA(image.Rect(0, 0, 1, 1)) // Изначальная текстура
 	isGrey[faceIndex] = false
 
-	m.ReplaceFaceTexture(faceIndex, &faces, &isGrey)
+	m.ReplaceFaceTexture(faceIndex, faces, isGrey)
 
 	assert.NotNil(t, faces[faceIndex].Texture, "Face texture should not be nil")
 	assert.True(t, isGrey[faceIndex], "Face should become grey")
@@ -152,13 +180,13 @@ func TestReplaceFaceTexture_NoAvailableTextures(t *testing.T) {
 
 // TestReplaceFaceTexture_InvalidIndex проверяет замену с неверным индексом.
 func TestReplaceFaceTexture_InvalidIndex(t *testing.T) {
-	m := NewManager()
-	var faces [6]cube.Face
-	var isGrey [6]bool
-	originalFaces := faces
-	originalIsGrey := isGrey
+	m := NewManager(random.NewPCG(1))
+	faces := make([]cube.Face, 6)
+	isGrey := make([]bool, 6)
+	originalFaces := append([]cube.Face(nil), faces...)
+	originalIsGrey := append([]bool(nil), isGrey...)
 
-	m.ReplaceFaceTexture(10, &faces, &isGrey) // Неверный индекс
+	m.ReplaceFaceTexture(10, faces, isGrey) // Неверный индекс
 
 	assert.Equal(t, originalFaces, faces, "Faces should not change for invalid index")
 	assert.Equal(t, originalIsGrey, isGrey, "isGrey should not change for invalid index")
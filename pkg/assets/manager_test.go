@@ -2,40 +2,54 @@ package assets
 
 import (
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/pkg/random"
 )
 
 // mockTextureLoader is a mock implementation of the textureLoader interface for testing.
 type mockTextureLoader struct {
 	// failOnLoad can be used to simulate errors during texture loading.
 	failOnLoad bool
+	// photos maps a person's name to the photo passed to NameCard.
+	photos map[string]string
 }
 
 // Load implements the textureLoader interface for the mock.
-// It returns a dummy ebiten.Image for .png files and nil for others,
+// It returns a dummy image for .png files and nil for others,
 // or if failOnLoad is true.
-func (m *mockTextureLoader) Load(path string) *ebiten.Image {
+func (m *mockTextureLoader) Load(path string) image.Image {
 	if m.failOnLoad {
 		return nil
 	}
 	if strings.HasSuffix(path, ".png") || strings.HasSuffix(path, ".jpg") || strings.HasSuffix(path, ".jpeg") {
 		// Return a non-nil dummy image to simulate successful loading.
 		// The image itself doesn't need to be valid for this test.
-		return ebiten.NewImage(1, 1)
+		return image.NewRGBA(image.Rect(0, 0, 1, 1))
 	}
 	return nil
 }
 
+// NameCard implements the textureLoader interface for the mock.
+// It records which people got a photo so tests can check the matching.
+func (m *mockTextureLoader) NameCard(name, photoPath string) image.Image {
+	if m.photos == nil {
+		m.photos = map[string]string{}
+	}
+	m.photos[name] = photoPath
+	return image.NewRGBA(image.Rect(0, 0, 1, 1))
+}
+
 // newTestManager creates a Manager with a mock loader for testing.
 func newTestManager(mockLoader textureLoader) *Manager {
 	return &Manager{
-		AllTextures:       []*ebiten.Image{},
-		AvailableTextures: []*ebiten.Image{},
+		AllTextures:       []image.Image{},
+		AvailableTextures: []image.Image{},
+		Random:            random.NewPCG(1),
 		loader:            mockLoader,
 	}
 }
@@ -71,6 +85,13 @@ func TestLoadFromDirectory_Success(t *testing.T) {
 	if len(m.AllTextures) != 6 {
 		t.Errorf("Expected 6 textures to be loaded, but got %d", len(m.AllTextures))
 	}
+
+	// Имя каждой текстуры берется из имени файла
+	for _, tex := range m.AllTextures {
+		if label := m.LabelOf(tex); !strings.HasPrefix(label, "face") || strings.Contains(label, ".") {
+			t.Errorf("Unexpected texture label %q", label)
+		}
+	}
 }
 
 func TestLoadFromDirectory_DirNotFound(t *testing.T) {
@@ -110,4 +131,4 @@ func TestLoadFromDirectory_NoImages(t *testing.T) {
 	if len(m.AllTextures) != 0 {
 		t.Errorf("Expected 0 textures, but got %d", len(m.AllTextures))
 	}
-}
\ No newline at end of file
+}
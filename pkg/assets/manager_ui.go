@@ -1,40 +1,27 @@
 package assets
 
-import (
-	"github.com/olegshirko/dice_roller/pkg/ui"
-	"github.com/sqweek/dialog"
-	"log"
-)
-
-// LoadTextures предлагает пользователю выбрать один или несколько файлов текстур.
-func (m *Manager) LoadTextures() {
-	log.Println("Opening file dialog to select textures...")
-	filenames, err := ui.ShowFilePicker()
-	if err != nil {
-		if err == dialog.ErrCancelled {
-			log.Println("Texture selection cancelled.")
-		} else {
-			log.Printf("Error selecting file(s): %v", err)
-		}
-		return
-	}
+import "image"
 
+// LoadFiles заменяет текстуры загруженными из filenames. Пустой список ничего
+// не меняет; результат сообщает, были ли текстуры заменены.
+func (m *Manager) LoadFiles(filenames []string) bool {
 	if len(filenames) == 0 {
-		log.Println("No files were selected.")
-		return
+		return false
 	}
 
 	// Очищаем старые текстуры только если выбраны новые
 	m.AllTextures = nil
 	m.AvailableTextures = nil
+	m.labels = map[image.Image]string{}
 
 	for _, filename := range filenames {
 		if tex := m.loader.Load(filename); tex != nil {
-			m.AllTextures = append(m.AllTextures, tex)
+			m.AddTexture(tex, labelFromPath(filename))
 		}
 	}
 
 	if len(m.AllTextures) > 0 {
 		m.prepareAvailableTextures()
 	}
-}
\ No newline at end of file
+	return true
+}
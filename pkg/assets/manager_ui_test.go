@@ -4,9 +4,10 @@ package assets
 
 import (
 	"errors"
+	"image"
 	"testing"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/pkg/random"
 	"github.com/olegshirko/dice_roller/pkg/ui"
 	"github.com/sqweek/dialog"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ func TestLoadTextures_Success(t *testing.T) {
 	manager := newTestManager(&mockTextureLoader{})
 
 	// Вызываем тестируемую функцию
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Проверяем результат
 	assert.Len(t, manager.AllTextures, 2, "Должно быть загружено 2 текстуры")
@@ -41,10 +42,10 @@ func TestLoadTextures_Cancelled(t *testing.T) {
 		return nil, dialog.ErrCancelled
 	}
 
-	manager := NewManager()
-	manager.AllTextures = []*ebiten.Image{ebiten.NewImage(1, 1)} // Предварительно заполняем
+	manager := NewManager(random.NewPCG(1))
+	manager.AllTextures = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1))} // Предварительно заполняем
 
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Текстуры не должны быть очищены
 	assert.Len(t, manager.AllTextures, 1, "Текстуры не должны были измениться после отмены")
@@ -59,10 +60,10 @@ func TestLoadTextures_GenericError(t *testing.T) {
 		return nil, errors.New("some generic error")
 	}
 
-	manager := NewManager()
-	manager.AllTextures = []*ebiten.Image{ebiten.NewImage(1, 1)} // Предварительно заполняем
+	manager := NewManager(random.NewPCG(1))
+	manager.AllTextures = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1))} // Предварительно заполняем
 
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Текстуры не должны быть очищены
 	assert.Len(t, manager.AllTextures, 1, "Текстуры не должны были измениться при ошибке")
@@ -77,10 +78,10 @@ func TestLoadTextures_NoFilesSelected(t *testing.T) {
 		return []string{}, nil
 	}
 
-	manager := NewManager()
-	manager.AllTextures = []*ebiten.Image{ebiten.NewImage(1, 1)} // Предварительно заполняем
+	manager := NewManager(random.NewPCG(1))
+	manager.AllTextures = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1))} // Предварительно заполняем
 
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Текстуры не должны быть очищены
 	assert.Len(t, manager.AllTextures, 1, "Текстуры не должны были измениться, если файлы не выбраны")
@@ -97,10 +98,10 @@ func TestLoadTextures_ClearsOldTextures(t *testing.T) {
 
 	// Создаем менеджер с мок-загрузчиком и "старыми" текстурами
 	manager := newTestManager(&mockTextureLoader{})
-	manager.AllTextures = []*ebiten.Image{ebiten.NewImage(1, 1), ebiten.NewImage(1, 1)}
-	manager.AvailableTextures = []*ebiten.Image{ebiten.NewImage(1, 1)}
+	manager.AllTextures = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1)), image.NewRGBA(image.Rect(0, 0, 1, 1))}
+	manager.AvailableTextures = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1))}
 
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Проверяем, что старые текстуры заменены новыми
 	assert.Len(t, manager.AllTextures, 1, "Старые текстуры должны быть заменены одной новой")
@@ -119,9 +120,9 @@ func TestLoadTextures_LoaderReturnsNil(t *testing.T) {
 	// Создаем менеджер с мок-загрузчиком, который иногда возвращает nil
 	manager := newTestManager(&mockTextureLoader{failOnLoad: true})
 
-	manager.LoadTextures()
+	manager.LoadFiles(ui.PickTextures())
 
 	// Только одна текстура должна была быть успешно загружена
 	assert.Len(t, manager.AllTextures, 0, "Только успешно загруженные текстуры должны быть добавлены")
 	assert.Len(t, manager.AvailableTextures, 0, "Только успешно загруженные текстуры должны быть доступны")
-}
\ No newline at end of file
+}
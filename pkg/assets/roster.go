@@ -0,0 +1,171 @@
+package assets
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olegshirko/dice_roller/internal/utils"
+	"github.com/olegshirko/dice_roller/pkg/config"
+)
+
+// nameCardSize — размер синтезированной текстуры с именем в пикселях.
+const nameCardSize = 2 * config.CubeSize
+
+// ReadRoster читает список участников из файла. Формат определяется по расширению:
+//   - .txt — одно имя на строку, пустые строки и строки с # пропускаются;
+//   - .csv — имя в первом столбце, заголовок "name" пропускается;
+//   - .json — массив строк или массив объектов с полем "name".
+func ReadRoster(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		r := csv.NewReader(strings.NewReader(string(data)))
+		r.FieldsPerRecord = -1
+		r.Comment = '#'
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("parse roster %s: %w", path, err)
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "name") {
+				continue
+			}
+			names = append(names, record[0])
+		}
+	case ".json":
+		var plain []string
+		if err := json.Unmarshal(data, &plain); err == nil {
+			names = plain
+			break
+		}
+		var people []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &people); err != nil {
+			return nil, fmt.Errorf("parse roster %s: %w", path, err)
+		}
+		for _, p := range people {
+			names = append(names, p.Name)
+		}
+	default:
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			names = append(names, line)
+		}
+	}
+
+	// Убираем пробелы по краям, пустые имена и повторы
+	seen := map[string]bool{}
+	roster := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		roster = append(roster, name)
+	}
+	return roster, nil
+}
+
+// LoadRoster создает текстуры для участников из файла roster. Если в каталоге
+// photoDir есть фотография с тем же именем (без учета регистра и разделителей),
+// используется она, иначе синтезируется карточка с инициалами.
+func (m *Manager) LoadRoster(path, photoDir string) bool {
+	names, err := ReadRoster(path)
+	if err != nil {
+		log.Printf("Could not read roster %s: %v", path, err)
+		return false
+	}
+	if len(names) == 0 {
+		log.Printf("Roster %s is empty.", path)
+		return false
+	}
+
+	photos := findPhotos(photoDir)
+	for _, name := range names {
+		if tex := m.loader.NameCard(name, photos[normalizeName(name)]); tex != nil {
+			m.AddTexture(tex, name)
+		}
+	}
+	log.Printf("Loaded %d people from roster %s (%d photos available).", len(names), path, len(photos))
+	m.prepareAvailableTextures()
+	return true
+}
+
+// Reload заново загружает участников: из списка roster, если он задан, иначе
+// из каталога dir. Прежние текстуры заменяются, только если загрузка удалась.
+func (m *Manager) Reload(dir, roster string) bool {
+	all, available, labels, benched := m.AllTextures, m.AvailableTextures, m.labels, m.benched
+	m.AllTextures, m.AvailableTextures, m.labels, m.benched = nil, nil, map[image.Image]string{}, nil
+
+	var loaded bool
+	if roster != "" {
+		loaded = m.LoadRoster(roster, dir)
+	} else {
+		loaded = m.LoadFromDirectory(dir)
+	}
+	if !loaded {
+		m.AllTextures, m.AvailableTextures, m.labels, m.benched = all, available, labels, benched
+	}
+	return loaded
+}
+
+// findPhotos собирает изображения из каталога, индексируя их по нормализованному имени.
+func findPhotos(dir string) map[string]string {
+	photos := map[string]string{}
+	if dir == "" {
+		return photos
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Could not read photo directory %s: %v", dir, err)
+		}
+		return photos
+	}
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if file.IsDir() || (ext != ".png" && ext != ".jpg" && ext != ".jpeg") {
+			continue
+		}
+		photos[normalizeName(labelFromPath(file.Name()))] = filepath.Join(dir, file.Name())
+	}
+	return photos
+}
+
+// normalizeName приводит имя к виду для сравнения: "Ivan_Petrov" и "ivan petrov" совпадают.
+func normalizeName(name string) string {
+	name = strings.NewReplacer("_", " ", "-", " ", ".", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// nameCardTexture создает текстуру участника: фотографию, если она задана и читается,
+// или цветную карточку с инициалами. Полное имя подписывается внизу.
+func nameCardTexture(name, photoPath string) image.Image {
+	var img image.Image
+	if photoPath != "" {
+		var err error
+		if img, err = decodeImage(photoPath); err != nil {
+			log.Printf("Could not use photo %s for %s: %v", photoPath, name, err)
+			img = nil
+		}
+	}
+	if img == nil {
+		img = utils.NameCard(name, nameCardSize)
+	}
+	return utils.AddLabelToImage(img, name)
+}
@@ -0,0 +1,98 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestReadRoster(t *testing.T) {
+	dir := t.TempDir()
+	want := []string{"Ivan Petrov", "Anna", "Bob Smith"}
+
+	cases := map[string]string{
+		"team.txt":     "# stand-up\nIvan Petrov\n\n  Anna  \nBob Smith\nAnna\n",
+		"team.csv":     "name,email\nIvan Petrov,ivan@example.com\nAnna,anna@example.com\n\"Bob Smith\",bob@example.com\n",
+		"team.json":    `["Ivan Petrov", "Anna", "Bob Smith"]`,
+		"people.json":  `[{"name": "Ivan Petrov"}, {"name": "Anna", "team": "qa"}, {"name": "Bob Smith"}]`,
+		"team.list":    "Ivan Petrov\r\nAnna\r\nBob Smith\r\n",
+		"noheader.csv": "Ivan Petrov\nAnna\nBob Smith\n",
+		"padded.json":  `[" Ivan Petrov ", "", "Anna", "Bob Smith"]`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			names, err := ReadRoster(writeFile(t, dir, name, content))
+			require.NoError(t, err)
+			assert.Equal(t, want, names)
+		})
+	}
+
+	_, err := ReadRoster(writeFile(t, dir, "broken.json", `{"name": "x"}`))
+	assert.Error(t, err, "A JSON object is not a roster")
+
+	_, err = ReadRoster(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestLoadRoster_MatchesPhotos(t *testing.T) {
+	dir := t.TempDir()
+	roster := writeFile(t, dir, "team.txt", "Ivan Petrov\nAnna\nBob Smith\n")
+
+	photoDir := filepath.Join(dir, "photos")
+	require.NoError(t, os.Mkdir(photoDir, 0o755))
+	ivan := writeFile(t, photoDir, "ivan_petrov.jpg", "")
+	writeFile(t, photoDir, "notes.txt", "")
+
+	loader := &mockTextureLoader{}
+	m := newTestManager(loader)
+	assert.True(t, m.LoadRoster(roster, photoDir))
+
+	assert.Len(t, m.AllTextures, 3)
+	assert.Len(t, m.AvailableTextures, 3, "Roster textures should go to the available pool")
+	assert.Equal(t, ivan, loader.photos["Ivan Petrov"], "Photo should match regardless of case and separators")
+	assert.Empty(t, loader.photos["Anna"], "People without a photo get a synthesized card")
+
+	labels := []string{}
+	for _, tex := range m.AllTextures {
+		labels = append(labels, m.LabelOf(tex))
+	}
+	assert.ElementsMatch(t, []string{"Ivan Petrov", "Anna", "Bob Smith"}, labels)
+}
+
+func TestLoadRoster_Empty(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(&mockTextureLoader{})
+	assert.False(t, m.LoadRoster(writeFile(t, dir, "team.txt", "\n# nobody\n"), ""))
+	assert.False(t, m.LoadRoster(filepath.Join(dir, "missing.txt"), ""))
+	assert.Empty(t, m.AllTextures)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	roster := writeFile(t, dir, "team.txt", "Ivan Petrov\nAnna\n")
+	m := newTestManager(&mockTextureLoader{})
+	require.True(t, m.LoadRoster(roster, ""))
+
+	writeFile(t, dir, "team.txt", "Anna\nBob Smith\nEve\n")
+	assert.True(t, m.Reload("", roster))
+	labels := []string{}
+	for _, tex := range m.AllTextures {
+		labels = append(labels, m.LabelOf(tex))
+	}
+	assert.ElementsMatch(t, []string{"Anna", "Bob Smith", "Eve"}, labels, "Old people are replaced, not kept")
+	assert.Len(t, m.AvailableTextures, 3)
+
+	assert.False(t, m.Reload(filepath.Join(dir, "missing"), ""))
+	assert.Len(t, m.AllTextures, 3, "A failed reload keeps the loaded people")
+	assert.Equal(t, "Eve", m.LabelOf(m.AllTextures[2]))
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action — действие, которое вызывается клавишей или кнопкой геймпада.
+type Action int
+
+const (
+	ActionSpin Action = iota
+	ActionLoad
+	ActionResetCycle
+	ActionUndo
+	ActionRedo
+	ActionAttendance
+	ActionHistory
+	ActionStandup
+	ActionStandupFast
+	ActionNext
+	ActionStopTimer
+	ActionDice
+	ActionHelp
+	ActionFullscreen
+	ActionScreenshot
+	ActionRecord
+	ActionQuit
+)
+
+// actionInfo описывает действие: имя поля в настройках, строку справки и привязки.
+type actionInfo struct {
+	name   string
+	help   string
+	key    func(k *Keys) *Key
+	button func(g *Gamepad) *GamepadButton
+}
+
+// actions — все действия в порядке показа в справке; индекс совпадает с Action.
+var actions = []actionInfo{
+	ActionSpin:        {"spin", "roll the die", func(k *Keys) *Key { return &k.Spin }, func(g *Gamepad) *GamepadButton { return &g.Spin }},
+	ActionLoad:        {"load", "load face images", func(k *Keys) *Key { return &k.Load }, func(g *Gamepad) *GamepadButton { return &g.Load }},
+	ActionResetCycle:  {"reset_cycle", "return everyone to the pool and reshuffle", func(k *Keys) *Key { return &k.ResetCycle }, func(g *Gamepad) *GamepadButton { return &g.ResetCycle }},
+	ActionUndo:        {"undo", "undo the last pick or cycle reset", func(k *Keys) *Key { return &k.Undo }, func(g *Gamepad) *GamepadButton { return &g.Undo }},
+	ActionRedo:        {"redo", "redo what was undone", func(k *Keys) *Key { return &k.Redo }, func(g *Gamepad) *GamepadButton { return &g.Redo }},
+	ActionAttendance:  {"attendance", "mark who is absent", func(k *Keys) *Key { return &k.Attendance }, func(g *Gamepad) *GamepadButton { return &g.Attendance }},
+	ActionHistory:     {"history", "show recent results", func(k *Keys) *Key { return &k.History }, func(g *Gamepad) *GamepadButton { return &g.History }},
+	ActionStandup:     {"standup", "draw the stand-up order", func(k *Keys) *Key { return &k.Standup }, func(g *Gamepad) *GamepadButton { return &g.Standup }},
+	ActionStandupFast: {"standup_fast", "draw the stand-up order without animation", func(k *Keys) *Key { return &k.StandupFast }, func(g *Gamepad) *GamepadButton { return &g.StandupFast }},
+	ActionNext:        {"next", "next speaker", func(k *Keys) *Key { return &k.Next }, func(g *Gamepad) *GamepadButton { return &g.Next }},
+	ActionStopTimer:   {"stop_timer", "stop the speaker timer", func(k *Keys) *Key { return &k.StopTimer }, func(g *Gamepad) *GamepadButton { return &g.StopTimer }},
+	ActionDice:        {"dice", "roll a dice expression like 4d6kh3", func(k *Keys) *Key { return &k.Dice }, func(g *Gamepad) *GamepadButton { return &g.Dice }},
+	ActionHelp:        {"help", "show or hide this help", func(k *Keys) *Key { return &k.Help }, func(g *Gamepad) *GamepadButton { return &g.Help }},
+	ActionFullscreen:  {"fullscreen", "toggle fullscreen", func(k *Keys) *Key { return &k.Fullscreen }, func(g *Gamepad) *GamepadButton { return &g.Fullscreen }},
+	ActionScreenshot:  {"screenshot", "save a screenshot", func(k *Keys) *Key { return &k.Screenshot }, func(g *Gamepad) *GamepadButton { return &g.Screenshot }},
+	ActionRecord:      {"record", "record the next roll as a GIF or PNG frames", func(k *Keys) *Key { return &k.Record }, func(g *Gamepad) *GamepadButton { return &g.Record }},
+	ActionQuit:        {"quit", "quit", func(k *Keys) *Key { return &k.Quit }, func(g *Gamepad) *GamepadButton { return &g.Quit }},
+}
+
+// Actions возвращает все действия в порядке показа в справке.
+func Actions() []Action {
+	all := make([]Action, len(actions))
+	for i := range all {
+		all[i] = Action(i)
+	}
+	return all
+}
+
+// String возвращает имя действия в настройках: "spin", "standup_fast".
+func (a Action) String() string {
+	return actions[a].name
+}
+
+// Help возвращает описание действия для экранной справки.
+func (a Action) Help() string {
+	return actions[a].help
+}
+
+// Key возвращает клавишу действия a.
+func (c Config) Key(a Action) Key {
+	return *actions[a].key(&c.Keys)
+}
+
+// Button возвращает кнопку геймпада действия a или NoButton.
+func (c Config) Button(a Action) GamepadButton {
+	return *actions[a].button(&c.Gamepad)
+}
+
+// GamepadButton — кнопка геймпада со стандартной раскладкой. В настройках задается
+// именем кнопки Ebitengine без префикса: "RightBottom" (A на Xbox, крестик на
+// PlayStation), "RightRight" (B), "CenterRight" (Start) и т.д., или "None".
+type GamepadButton int
+
+// NoButton означает, что кнопка геймпада для действия не назначена.
+const NoButton GamepadButton = -1
+
+// Кнопки стандартной раскладки; значения совпадают с ebiten.StandardGamepadButton.
+const (
+	ButtonRightBottom GamepadButton = iota
+	ButtonRightRight
+	ButtonRightLeft
+	ButtonRightTop
+	ButtonFrontTopLeft
+	ButtonFrontTopRight
+	ButtonFrontBottomLeft
+	ButtonFrontBottomRight
+	ButtonCenterLeft
+	ButtonCenterRight
+	ButtonLeftStick
+	ButtonRightStick
+	ButtonLeftTop
+	ButtonLeftBottom
+	ButtonLeftLeft
+	ButtonLeftRight
+	ButtonCenterCenter
+)
+
+// buttonNames — имена кнопок в порядке их значений.
+var buttonNames = []string{
+	"RightBottom", "RightRight", "RightLeft", "RightTop",
+	"FrontTopLeft", "FrontTopRight", "FrontBottomLeft", "FrontBottomRight",
+	"CenterLeft", "CenterRight", "LeftStick", "RightStick",
+	"LeftTop", "LeftBottom", "LeftLeft", "LeftRight", "CenterCenter",
+}
+
+func (b GamepadButton) String() string {
+	if b < 0 || int(b) >= len(buttonNames) {
+		return "None"
+	}
+	return buttonNames[b]
+}
+
+func (b GamepadButton) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *GamepadButton) UnmarshalText(text []byte) error {
+	name := string(text)
+	if name == "" || strings.EqualFold(name, "None") {
+		*b = NoButton
+		return nil
+	}
+	for i, n := range buttonNames {
+		if strings.EqualFold(name, n) {
+			*b = GamepadButton(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown gamepad button %q (expected one of %s or None)", name, strings.Join(buttonNames, ", "))
+}
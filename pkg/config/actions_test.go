@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActions(t *testing.T) {
+	c := Default()
+	names := map[string]bool{}
+	for _, a := range Actions() {
+		assert.False(t, names[a.String()], "Action names must be unique: %s", a)
+		names[a.String()] = true
+		assert.NotEmpty(t, a.Help(), a.String())
+	}
+	assert.Equal(t, KeyS, c.Key(ActionSpin))
+	assert.Equal(t, KeyF1, c.Key(ActionHelp))
+	assert.Equal(t, ButtonRightBottom, c.Button(ActionSpin))
+	assert.Equal(t, ButtonRightLeft, c.Button(ActionUndo))
+	assert.Equal(t, NoButton, c.Button(ActionRedo))
+}
+
+func TestKey_Text(t *testing.T) {
+	assert.Equal(t, "F11", KeyF11.String())
+	assert.Equal(t, "ArrowRight", KeyArrowRight.String())
+
+	var k Key
+	require.NoError(t, k.UnmarshalText([]byte("space")))
+	assert.Equal(t, KeySpace, k, "Names are case-insensitive")
+	require.NoError(t, k.UnmarshalText([]byte("Right")))
+	assert.Equal(t, KeyArrowRight, k, "Old Ebitengine names are accepted")
+	assert.Error(t, k.UnmarshalText([]byte("NoSuchKey")))
+
+	data, err := json.Marshal(Default().Keys)
+	require.NoError(t, err)
+	var back Keys
+	require.NoError(t, json.Unmarshal(data, &back))
+	assert.Equal(t, Default().Keys, back)
+}
+
+func TestGamepadButton_Text(t *testing.T) {
+	assert.Equal(t, "RightBottom", ButtonRightBottom.String())
+	assert.Equal(t, "CenterCenter", ButtonCenterCenter.String(), "Names follow the Ebitengine order")
+	assert.Equal(t, "None", NoButton.String())
+
+	var g Gamepad
+	require.NoError(t, json.Unmarshal([]byte(`{"spin": "centerright", "next": "None"}`), &g))
+	assert.Equal(t, ButtonCenterRight, g.Spin, "Names are case-insensitive")
+	assert.Equal(t, NoButton, g.Next)
+
+	data, err := json.Marshal(Default().Gamepad)
+	require.NoError(t, err)
+	var back Gamepad
+	require.NoError(t, json.Unmarshal(data, &back))
+	assert.Equal(t, Default().Gamepad, back)
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"spin": "Triangle"}`), &g), "Triangle")
+}
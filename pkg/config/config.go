@@ -1,11 +1,8 @@
 package config
 
 import (
+	"image"
 	"image/color"
-	"math/rand"
-	"time"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const (
@@ -14,17 +11,19 @@ const (
 	CubeSize     = 150
 )
 
+// Текстуры граней без участника: пустая грань до загрузки и серая грань
+// выбывшего. Это обычные изображения, чтобы модель кости не зависела от Ebitengine.
 var (
-	EmptyImage *ebiten.Image
-	GreyImage  *ebiten.Image
+	EmptyImage = uniformImage(3, color.White)
+	GreyImage  = uniformImage(CubeSize, color.Gray{Y: 128})
 )
 
-func init() {
-	EmptyImage = ebiten.NewImage(3, 3)
-	EmptyImage.Fill(color.White)
-
-	GreyImage = ebiten.NewImage(CubeSize, CubeSize)
-	GreyImage.Fill(color.Gray{Y: 128})
-
-	rand.Seed(time.Now().UnixNano())
+func uniformImage(size int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
 }
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key — клавиша клавиатуры. Значения и имена совпадают с ebiten.Key, поэтому
+// настройки не зависят от Ebitengine, а окно переводит клавишу простым
+// преобразованием типа. В настройках клавиша задается именем Ebitengine:
+// "S", "F1", "Space", "ArrowRight"; регистр не важен.
+type Key int
+
+// Клавиши, назначенные по умолчанию.
+const (
+	KeyA Key = iota
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+)
+
+const (
+	KeyArrowRight Key = 30
+	KeyF1         Key = 57
+	KeyF11        Key = 67
+	KeySpace      Key = 116
+)
+
+// keyNames — имена клавиш в порядке ebiten.Key.
+var keyNames = []string{
+	"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S",
+	"T", "U", "V", "W", "X", "Y", "Z", "AltLeft", "AltRight", "ArrowDown", "ArrowLeft", "ArrowRight",
+	"ArrowUp", "Backquote", "Backslash", "Backspace", "BracketLeft", "BracketRight", "CapsLock",
+	"Comma", "ContextMenu", "ControlLeft", "ControlRight", "Delete", "Digit0", "Digit1", "Digit2",
+	"Digit3", "Digit4", "Digit5", "Digit6", "Digit7", "Digit8", "Digit9", "End", "Enter", "Equal",
+	"Escape", "F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12", "F13", "F14",
+	"F15", "F16", "F17", "F18", "F19", "F20", "F21", "F22", "F23", "F24", "Home", "Insert",
+	"IntlBackslash", "MetaLeft", "MetaRight", "Minus", "NumLock", "Numpad0", "Numpad1", "Numpad2",
+	"Numpad3", "Numpad4", "Numpad5", "Numpad6", "Numpad7", "Numpad8", "Numpad9", "NumpadAdd",
+	"NumpadDecimal", "NumpadDivide", "NumpadEnter", "NumpadEqual", "NumpadMultiply", "NumpadSubtract",
+	"PageDown", "PageUp", "Pause", "Period", "PrintScreen", "Quote", "ScrollLock", "Semicolon",
+	"ShiftLeft", "ShiftRight", "Slash", "Space", "Tab", "Alt", "Control", "Shift", "Meta",
+}
+
+// keyAliases — устаревшие имена клавиш, которые Ebitengine тоже принимает.
+var keyAliases = map[string]string{
+	"0": "Digit0", "1": "Digit1", "2": "Digit2", "3": "Digit3", "4": "Digit4", "5": "Digit5",
+	"6": "Digit6", "7": "Digit7", "8": "Digit8", "9": "Digit9", "apostrophe": "Quote",
+	"down": "ArrowDown", "graveaccent": "Backquote", "kp0": "Numpad0", "kp1": "Numpad1",
+	"kp2": "Numpad2", "kp3": "Numpad3", "kp4": "Numpad4", "kp5": "Numpad5", "kp6": "Numpad6",
+	"kp7": "Numpad7", "kp8": "Numpad8", "kp9": "Numpad9", "kpadd": "NumpadAdd",
+	"kpdecimal": "NumpadDecimal", "kpdivide": "NumpadDivide", "kpenter": "NumpadEnter",
+	"kpequal": "NumpadEqual", "kpmultiply": "NumpadMultiply", "kpsubtract": "NumpadSubtract",
+	"left": "ArrowLeft", "leftbracket": "BracketLeft", "menu": "ContextMenu", "right": "ArrowRight",
+	"rightbracket": "BracketRight", "up": "ArrowUp",
+}
+
+// KeyCount — число клавиш; Key(0)…Key(KeyCount-1) — все клавиши.
+const KeyCount = 122
+
+func (k Key) String() string {
+	if k < 0 || int(k) >= len(keyNames) {
+		return ""
+	}
+	return keyNames[k]
+}
+
+func (k Key) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *Key) UnmarshalText(text []byte) error {
+	name := string(text)
+	if alias, ok := keyAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	for i, n := range keyNames {
+		if strings.EqualFold(name, n) {
+			*k = Key(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown key %q", string(text))
+}
@@ -0,0 +1,513 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix — префикс переменных окружения, переопределяющих настройки.
+const EnvPrefix = "DICE_ROLLER_"
+
+// Config — настройки приложения. Слои применяются по порядку: значения по умолчанию,
+// файл настроек, переменные окружения, флаги командной строки.
+type Config struct {
+	Window    Window    `json:"window"`
+	Cube      Cube      `json:"cube"`
+	Camera    Camera    `json:"camera"`
+	Lighting  Lighting  `json:"lighting"`
+	Animation Animation `json:"animation"`
+	Assets    Assets    `json:"assets"`
+	Keys      Keys      `json:"keys"`
+	Gamepad   Gamepad   `json:"gamepad"`
+	Webhook   Webhook   `json:"webhook"`
+}
+
+// Window — параметры окна.
+type Window struct {
+	Width       int    `json:"width"`  // Логическая ширина экрана в пикселях
+	Height      int    `json:"height"` // Логическая высота экрана в пикселях
+	Title       string `json:"title"`
+	Decorated   bool   `json:"decorated"`   // Рамка и заголовок окна
+	Transparent bool   `json:"transparent"` // Прозрачный фон вокруг кости
+}
+
+// Cube — параметры кости.
+type Cube struct {
+	Sides   int     `json:"sides"`   // Число граней: 4, 6, 8, 10, 12 или 20
+	Size    float64 `json:"size"`    // Размер кости на экране (ребро d6) в пикселях
+	Physics bool    `json:"physics"` // Физический режим броска
+}
+
+// Scale возвращает масштаб проекции: во сколько раз кость на экране больше модели.
+func (c Cube) Scale() float64 {
+	return c.Size / CubeSize
+}
+
+// Camera — камера, через которую видна кость. Координаты — пиксели от центра экрана:
+// X вправо, Y вниз, Z от зрителя.
+type Camera struct {
+	FOV      float64    `json:"fov"`      // Угол обзора по вертикали в градусах; 0 — ортографическая проекция
+	Distance float64    `json:"distance"` // Расстояние от камеры до экрана в пикселях; 0 — при котором кость в плоскости экрана сохраняет размер
+	LookAt   [3]float64 `json:"look_at"`  // Точка, на которую смотрит камера
+}
+
+// Lighting — освещение граней кости. Цвета задаются долями от 0 до 1 по каналам R, G, B.
+type Lighting struct {
+	Enabled   bool       `json:"enabled"`   // Выключенное освещение рисует грани плоскими, как есть
+	Direction [3]float64 `json:"direction"` // Направление на источник света: X вправо, Y вниз, Z от зрителя
+	Ambient   [3]float64 `json:"ambient"`   // Рассеянный свет
+	Diffuse   [3]float64 `json:"diffuse"`   // Направленный свет
+	Specular  [3]float64 `json:"specular"`  // Цвет блика; нули отключают блик
+	Shininess float64    `json:"shininess"` // Чем больше, тем меньше и резче блик
+}
+
+// Animation — параметры анимации броска в режиме с заранее выбранным победителем.
+// Скорости задаются в радианах (или пикселях) за тик.
+type Animation struct {
+	Decay          float64 `json:"decay"`           // Затухание скорости вращения за тик
+	StopSpeed      float64 `json:"stop_speed"`      // Скорость, ниже которой вращение переходит в доводку
+	JumpVelocity   float64 `json:"jump_velocity"`   // Начальная вертикальная скорость прыжка (вверх — отрицательная)
+	Gravity        float64 `json:"gravity"`         // Ускорение прыжка за тик
+	Bounce         float64 `json:"bounce"`          // Доля скорости, сохраняемая при отскоке
+	SnapSpeed      float64 `json:"snap_speed"`      // Доля пути до выигравшей грани за тик доводки
+	ShakeSpeed     float64 `json:"shake_speed"`     // Скорость дрожания, когда граней не осталось
+	ShakeMagnitude float64 `json:"shake_magnitude"` // Амплитуда дрожания в радианах
+	IdleSpeedX     float64 `json:"idle_speed_x"`    // Скорость фонового вращения вокруг оси X
+	IdleSpeedY     float64 `json:"idle_speed_y"`    // Скорость фонового вращения вокруг оси Y
+}
+
+// Assets — откуда берутся изображения граней.
+type Assets struct {
+	Dir    string `json:"dir"`    // Каталог с изображениями граней и фотографиями участников
+	Roster string `json:"roster"` // Список команды; если задан, грани создаются по именам
+}
+
+// Webhook — уведомление о выбранном участнике запросом POST на внешний адрес.
+type Webhook struct {
+	URL      string  `json:"url"`      // Куда отправлять уведомление; пустой — уведомления выключены
+	Template string  `json:"template"` // Шаблон тела (text/template); пустой — JSON по умолчанию
+	Snapshot string  `json:"snapshot"` // Приложить изображение: "png" — кадр с результатом, "gif" — запись броска
+	Timeout  float64 `json:"timeout"`  // Сколько секунд ждать ответа на одну попытку
+	Retries  int     `json:"retries"`  // Сколько раз повторить неудачную отправку
+	Backoff  float64 `json:"backoff"`  // Пауза перед первым повтором в секундах; каждая следующая вдвое длиннее
+	DryRun   bool    `json:"dry_run"`  // Только выводить уведомления в лог, ничего не отправляя
+}
+
+// Keys — клавиши действий. В файле и переменных окружения задаются именами
+// клавиш Ebitengine: "S", "Space", "ArrowUp" и т.п.
+type Keys struct {
+	Load        Key `json:"load"`
+	Spin        Key `json:"spin"`
+	ResetCycle  Key `json:"reset_cycle"`
+	Undo        Key `json:"undo"`
+	Redo        Key `json:"redo"`
+	Attendance  Key `json:"attendance"`
+	History     Key `json:"history"`
+	Standup     Key `json:"standup"`
+	StandupFast Key `json:"standup_fast"`
+	Next        Key `json:"next"`
+	StopTimer   Key `json:"stop_timer"`
+	Dice        Key `json:"dice"` // Ввод выражения с костями: "4d6kh3"
+	Help        Key `json:"help"`
+	Fullscreen  Key `json:"fullscreen"`
+	Screenshot  Key `json:"screenshot"`
+	Record      Key `json:"record"` // Записать следующий бросок
+	Quit        Key `json:"quit"`
+}
+
+// Gamepad — кнопки геймпада для тех же действий, что и Keys.
+type Gamepad struct {
+	Load        GamepadButton `json:"load"`
+	Spin        GamepadButton `json:"spin"`
+	ResetCycle  GamepadButton `json:"reset_cycle"`
+	Undo        GamepadButton `json:"undo"`
+	Redo        GamepadButton `json:"redo"`
+	Attendance  GamepadButton `json:"attendance"`
+	History     GamepadButton `json:"history"`
+	Standup     GamepadButton `json:"standup"`
+	StandupFast GamepadButton `json:"standup_fast"`
+	Next        GamepadButton `json:"next"`
+	StopTimer   GamepadButton `json:"stop_timer"`
+	Dice        GamepadButton `json:"dice"`
+	Help        GamepadButton `json:"help"`
+	Fullscreen  GamepadButton `json:"fullscreen"`
+	Screenshot  GamepadButton `json:"screenshot"`
+	Record      GamepadButton `json:"record"`
+	Quit        GamepadButton `json:"quit"`
+}
+
+// Default возвращает настройки по умолчанию.
+func Default() Config {
+	return Config{
+		Window: Window{
+			Width:       ScreenWidth,
+			Height:      ScreenHeight,
+			Title:       "Rotating 3D Cube",
+			Transparent: true,
+		},
+		Cube: Cube{
+			Sides: 6,
+			Size:  CubeSize * 1.5,
+		},
+		Camera: Camera{
+			FOV: 30,
+		},
+		Lighting: Lighting{
+			Enabled:   true,
+			Direction: [3]float64{-0.4, -0.6, -1}, // Слева сверху из-за спины зрителя
+			Ambient:   [3]float64{0.5, 0.5, 0.5},
+			Diffuse:   [3]float64{0.6, 0.6, 0.6},
+			Specular:  [3]float64{0.2, 0.2, 0.2},
+			Shininess: 32,
+		},
+		Animation: Animation{
+			Decay:          0.99,
+			StopSpeed:      0.01,
+			JumpVelocity:   -20,
+			Gravity:        1.0,
+			Bounce:         0.6,
+			SnapSpeed:      0.1,
+			ShakeSpeed:     0.5,
+			ShakeMagnitude: 0.06,
+			IdleSpeedX:     0.005,
+			IdleSpeedY:     0.01,
+		},
+		Assets: Assets{
+			Dir: "img",
+		},
+		Webhook: Webhook{
+			Timeout: 5,
+			Retries: 3,
+			Backoff: 1,
+		},
+		Keys: Keys{
+			Load:        KeyL,
+			Spin:        KeyS,
+			ResetCycle:  KeyC,
+			Undo:        KeyU,
+			Redo:        KeyY,
+			Attendance:  KeyA,
+			History:     KeyH,
+			Standup:     KeyO,
+			StandupFast: KeyF,
+			Next:        KeyN,
+			StopTimer:   KeyT,
+			Dice:        KeyR,
+			Help:        KeyF1,
+			Fullscreen:  KeyF11,
+			Screenshot:  KeyP,
+			Record:      KeyV,
+			Quit:        KeyQ,
+		},
+		Gamepad: Gamepad{
+			Load:        NoButton,
+			Spin:        ButtonRightBottom,
+			ResetCycle:  NoButton,
+			Undo:        ButtonRightLeft,
+			Redo:        NoButton,
+			Attendance:  NoButton,
+			History:     ButtonRightTop,
+			Standup:     NoButton,
+			StandupFast: NoButton,
+			Next:        ButtonRightRight,
+			StopTimer:   NoButton,
+			Dice:        NoButton,
+			Help:        ButtonCenterRight,
+			Fullscreen:  NoButton,
+			Screenshot:  NoButton,
+			Record:      NoButton,
+			Quit:        NoButton,
+		},
+	}
+}
+
+// DefaultPath возвращает путь к файлу настроек в пользовательском каталоге настроек.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dir, "dice_roller", "config.json")
+}
+
+// ReadFile накладывает на c настройки из JSON-файла path. В файле достаточно указать
+// только меняемые поля; неизвестные поля считаются ошибкой, чтобы опечатка не прошла незамеченной.
+func (c *Config) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Load собирает настройки из значений по умолчанию, файла path и переменных окружения.
+// Отсутствующий файл по пути DefaultPath не считается ошибкой.
+func Load(path string, lookupEnv func(string) (string, bool)) (Config, error) {
+	c := Default()
+	if path != "" {
+		err := c.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && path == DefaultPath() {
+			err = nil
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+	if err := c.ApplyEnv(lookupEnv); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// setting описывает настройку, которую можно переопределить переменной окружения
+// EnvPrefix+NAME и, если flag установлен, флагом -name.
+type setting struct {
+	name    string
+	usage   string
+	flag    bool // Есть ли у настройки флаг командной строки
+	boolean bool // Флаг можно указать без значения: -physics
+	set     func(c *Config, value string) error
+}
+
+func intSetting(name, usage string, field func(c *Config) *int) setting {
+	return setting{name: name, usage: usage, flag: true, set: func(c *Config, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func floatSetting(name, usage string, field func(c *Config) *float64) setting {
+	return setting{name: name, usage: usage, flag: true, set: func(c *Config, value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func boolSetting(name, usage string, field func(c *Config) *bool) setting {
+	return setting{name: name, usage: usage, flag: true, boolean: true, set: func(c *Config, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(c) = v
+		return nil
+	}}
+}
+
+func stringSetting(name, usage string, field func(c *Config) *string) setting {
+	return setting{name: name, usage: usage, flag: true, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+// actionSettings возвращает настройки key-* и button-* для всех действий. Они задаются
+// только в файле и переменных окружения: флаг на каждую клавишу лишь загромоздил бы справку.
+func actionSettings() []setting {
+	var list []setting
+	for _, a := range Actions() {
+		name := strings.ReplaceAll(a.String(), "_", "-")
+		list = append(list,
+			setting{name: "key-" + name, set: func(c *Config, value string) error {
+				return actions[a].key(&c.Keys).UnmarshalText([]byte(value))
+			}},
+			setting{name: "button-" + name, set: func(c *Config, value string) error {
+				return actions[a].button(&c.Gamepad).UnmarshalText([]byte(value))
+			}})
+	}
+	return list
+}
+
+// settings — все настройки, которые можно переопределить без файла.
+var settings = append([]setting{
+	intSetting("width", "logical screen width in pixels", func(c *Config) *int { return &c.Window.Width }),
+	intSetting("height", "logical screen height in pixels", func(c *Config) *int { return &c.Window.Height }),
+	stringSetting("title", "window title", func(c *Config) *string { return &c.Window.Title }),
+	boolSetting("decorated", "show the window frame and title bar", func(c *Config) *bool { return &c.Window.Decorated }),
+	boolSetting("transparent", "transparent background around the die", func(c *Config) *bool { return &c.Window.Transparent }),
+	intSetting("sides", "number of die faces: 4, 6, 8, 10, 12 or 20", func(c *Config) *int { return &c.Cube.Sides }),
+	floatSetting("size", "on-screen die size in pixels (edge of a d6)", func(c *Config) *float64 { return &c.Cube.Size }),
+	boolSetting("physics", "throw the die onto a virtual table instead of picking the winner up front", func(c *Config) *bool { return &c.Cube.Physics }),
+	floatSetting("fov", "vertical field of view of the camera in degrees (0 is an orthographic view)", func(c *Config) *float64 { return &c.Camera.FOV }),
+	floatSetting("camera-distance", "distance from the camera to the screen in pixels (0 keeps the die at its size)", func(c *Config) *float64 { return &c.Camera.Distance }),
+	boolSetting("lighting", "shade the die faces with ambient, directional and specular light", func(c *Config) *bool { return &c.Lighting.Enabled }),
+	floatSetting("decay", "spin speed kept after each tick", func(c *Config) *float64 { return &c.Animation.Decay }),
+	floatSetting("stop-speed", "spin speed below which the die snaps to the winner", func(c *Config) *float64 { return &c.Animation.StopSpeed }),
+	floatSetting("jump-velocity", "initial vertical speed of the jump (negative is up)", func(c *Config) *float64 { return &c.Animation.JumpVelocity }),
+	floatSetting("gravity", "jump acceleration per tick", func(c *Config) *float64 { return &c.Animation.Gravity }),
+	floatSetting("bounce", "share of the jump speed kept after a bounce", func(c *Config) *float64 { return &c.Animation.Bounce }),
+	floatSetting("snap-speed", "share of the way to the winning face covered per tick", func(c *Config) *float64 { return &c.Animation.SnapSpeed }),
+	floatSetting("shake-speed", "speed of the shake when no faces are left", func(c *Config) *float64 { return &c.Animation.ShakeSpeed }),
+	floatSetting("shake-magnitude", "amplitude of the shake in radians", func(c *Config) *float64 { return &c.Animation.ShakeMagnitude }),
+	floatSetting("idle-speed-x", "idle rotation speed around the X axis", func(c *Config) *float64 { return &c.Animation.IdleSpeedX }),
+	floatSetting("idle-speed-y", "idle rotation speed around the Y axis", func(c *Config) *float64 { return &c.Animation.IdleSpeedY }),
+	stringSetting("dir", "directory with face images and photos matched to roster names", func(c *Config) *string { return &c.Assets.Dir }),
+	stringSetting("roster", "team roster file (.txt, .csv or .json); faces are generated from names instead of images", func(c *Config) *string { return &c.Assets.Roster }),
+	stringSetting("webhook", "URL that gets a POST request with every picked participant", func(c *Config) *string { return &c.Webhook.URL }),
+	stringSetting("webhook-template", "text/template for the webhook body (default: a JSON object with text, label, time and cycle)", func(c *Config) *string { return &c.Webhook.Template }),
+	stringSetting("webhook-snapshot", "attach an image to the webhook: png (the result frame) or gif (the roll recording)", func(c *Config) *string { return &c.Webhook.Snapshot }),
+	floatSetting("webhook-timeout", "seconds to wait for the webhook to answer", func(c *Config) *float64 { return &c.Webhook.Timeout }),
+	intSetting("webhook-retries", "how many times a failed webhook is retried", func(c *Config) *int { return &c.Webhook.Retries }),
+	boolSetting("webhook-dry-run", "log webhook payloads instead of sending them", func(c *Config) *bool { return &c.Webhook.DryRun }),
+}, actionSettings()...)
+
+// EnvName возвращает имя переменной окружения для настройки name.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ApplyEnv переопределяет настройки переменными окружения DICE_ROLLER_*.
+func (c *Config) ApplyEnv(lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	for _, s := range settings {
+		value, ok := lookupEnv(EnvName(s.name))
+		if !ok {
+			continue
+		}
+		if err := s.set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvName(s.name), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterFlags регистрирует в fs флаги настроек и возвращает функцию, которая после
+// разбора командной строки применяет к конфигурации только явно заданные флаги.
+// Некорректные значения отвергаются уже при разборе.
+func RegisterFlags(fs *flag.FlagSet) func(c *Config) {
+	var set []func(c *Config)
+	for _, s := range settings {
+		if !s.flag {
+			continue
+		}
+		parse := func(value string) error {
+			// Проверяем значение на черновике, чтобы ошибка указала на флаг
+			if err := s.set(&Config{}, value); err != nil {
+				return err
+			}
+			set = append(set, func(c *Config) { s.set(c, value) })
+			return nil
+		}
+		usage := fmt.Sprintf("%s (env %s)", s.usage, EnvName(s.name))
+		if s.boolean {
+			fs.BoolFunc(s.name, usage, parse)
+		} else {
+			fs.Func(s.name, usage, parse)
+		}
+	}
+	return func(c *Config) {
+		for _, apply := range set {
+			apply(c)
+		}
+	}
+}
+
+// validSides — формы костей, для которых есть модель.
+var validSides = []int{4, 6, 8, 10, 12, 20}
+
+// Validate проверяет настройки и возвращает все найденные ошибки сразу.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Window.Width >= 200 && c.Window.Width <= 8192, "window.width", "must be between 200 and 8192, got %d", c.Window.Width)
+	check(c.Window.Height >= 200 && c.Window.Height <= 8192, "window.height", "must be between 200 and 8192, got %d", c.Window.Height)
+
+	sidesOK := false
+	for _, n := range validSides {
+		sidesOK = sidesOK || c.Cube.Sides == n
+	}
+	check(sidesOK, "cube.sides", "must be one of 4, 6, 8, 10, 12 or 20, got %d", c.Cube.Sides)
+	check(c.Cube.Size > 0, "cube.size", "must be positive, got %g", c.Cube.Size)
+	if c.Cube.Size > 0 {
+		check(c.Cube.Size*2 <= float64(min(c.Window.Width, c.Window.Height)), "cube.size",
+			"%g does not fit the %dx%d window", c.Cube.Size, c.Window.Width, c.Window.Height)
+	}
+
+	check(c.Camera.FOV >= 0 && c.Camera.FOV < 150, "camera.fov", "must be in [0, 150), got %g", c.Camera.FOV)
+	if c.Camera.FOV > 0 && c.Camera.Distance != 0 {
+		// Камера не должна оказаться внутри кости, стоящей в центре экрана
+		check(c.Camera.Distance > c.Cube.Size, "camera.distance", "must be 0 or greater than cube.size (%g), got %g", c.Cube.Size, c.Camera.Distance)
+	}
+
+	if l := c.Lighting; l.Enabled {
+		check(l.Direction != [3]float64{}, "lighting.direction", "must not be zero")
+		for _, v := range []struct {
+			name string
+			rgb  [3]float64
+		}{{"ambient", l.Ambient}, {"diffuse", l.Diffuse}, {"specular", l.Specular}} {
+			check(min(v.rgb[0], v.rgb[1], v.rgb[2]) >= 0 && max(v.rgb[0], v.rgb[1], v.rgb[2]) <= 1, "lighting."+v.name, "channels must be between 0 and 1, got %v", v.rgb)
+		}
+		check(l.Shininess > 0, "lighting.shininess", "must be positive, got %g", l.Shininess)
+	}
+
+	a := c.Animation
+	check(a.Decay > 0 && a.Decay < 1, "animation.decay", "must be between 0 and 1 exclusive, got %g", a.Decay)
+	check(a.StopSpeed > 0, "animation.stop_speed", "must be positive, got %g", a.StopSpeed)
+	check(a.JumpVelocity <= 0, "animation.jump_velocity", "must not be positive (negative is up), got %g", a.JumpVelocity)
+	check(a.Gravity > 0, "animation.gravity", "must be positive, got %g", a.Gravity)
+	check(a.Bounce >= 0 && a.Bounce < 1, "animation.bounce", "must be in [0, 1), got %g", a.Bounce)
+	check(a.SnapSpeed > 0 && a.SnapSpeed <= 1, "animation.snap_speed", "must be in (0, 1], got %g", a.SnapSpeed)
+	check(a.ShakeSpeed > 0, "animation.shake_speed", "must be positive, got %g", a.ShakeSpeed)
+	check(a.ShakeMagnitude >= 0, "animation.shake_magnitude", "must not be negative, got %g", a.ShakeMagnitude)
+
+	check(c.Assets.Dir != "" || c.Assets.Roster != "", "assets.dir", "must be set when there is no roster")
+
+	if w := c.Webhook; w.URL != "" || w.DryRun {
+		if w.URL != "" {
+			u, err := url.Parse(w.URL)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "webhook.url", "must be an http or https URL, got %q", w.URL)
+		}
+		check(w.Snapshot == "" || w.Snapshot == "png" || w.Snapshot == "gif", "webhook.snapshot", "must be empty, png or gif, got %q", w.Snapshot)
+		check(w.Timeout > 0, "webhook.timeout", "must be positive, got %g", w.Timeout)
+		check(w.Retries >= 0, "webhook.retries", "must not be negative, got %d", w.Retries)
+		check(w.Backoff >= 0, "webhook.backoff", "must not be negative, got %g", w.Backoff)
+	}
+
+	// Одна клавиша или кнопка не может запускать два действия
+	keys := map[Key]Action{}
+	buttons := map[GamepadButton]Action{}
+	for _, a := range Actions() {
+		if other, ok := keys[c.Key(a)]; ok {
+			check(false, "keys."+a.String(), "%s is already bound to %s", c.Key(a), other)
+		} else {
+			keys[c.Key(a)] = a
+		}
+		b := c.Button(a)
+		if b == NoButton {
+			continue
+		}
+		if other, ok := buttons[b]; ok {
+			check(false, "gamepad."+a.String(), "%s is already bound to %s", b, other)
+		} else {
+			buttons[b] = a
+		}
+	}
+
+	return errors.Join(errs...)
+}
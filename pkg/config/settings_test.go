@@ -0,0 +1,144 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env возвращает функцию поиска переменных окружения по словарю vars.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	c := Default()
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, 1.5, c.Cube.Scale(), "Default size keeps the original projection scale")
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"window": {"width": 1280},
+		"cube": {"sides": 20},
+		"animation": {"decay": 0.95},
+		"keys": {"spin": "Space"},
+		"gamepad": {"next": "LeftTop"}
+	}`), 0o644))
+
+	c, err := Load(path, env(map[string]string{
+		"DICE_ROLLER_SIDES":       "12",
+		"DICE_ROLLER_PHYSICS":     "true",
+		"DICE_ROLLER_KEY_NEXT":    "ArrowRight",
+		"DICE_ROLLER_BUTTON_SPIN": "none",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1280, c.Window.Width, "The file overrides defaults")
+	assert.Equal(t, ScreenHeight, c.Window.Height, "Fields missing from the file keep defaults")
+	assert.Equal(t, 0.95, c.Animation.Decay)
+	assert.Equal(t, KeySpace, c.Keys.Spin)
+	assert.Equal(t, 12, c.Cube.Sides, "The environment overrides the file")
+	assert.True(t, c.Cube.Physics)
+	assert.Equal(t, KeyArrowRight, c.Keys.Next)
+	assert.Equal(t, ButtonLeftTop, c.Button(ActionNext))
+	assert.Equal(t, NoButton, c.Button(ActionSpin), "A button can be unbound")
+	assert.NoError(t, c.Validate())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.json"), env(nil))
+	assert.Error(t, err, "An explicitly given file must exist")
+
+	typo := filepath.Join(dir, "typo.json")
+	require.NoError(t, os.WriteFile(typo, []byte(`{"window": {"widht": 800}}`), 0o644))
+	_, err = Load(typo, env(nil))
+	assert.ErrorContains(t, err, "widht", "Unknown fields should be reported")
+
+	_, err = Load("", env(map[string]string{"DICE_ROLLER_WIDTH": "wide"}))
+	assert.ErrorContains(t, err, "DICE_ROLLER_WIDTH")
+
+	_, err = Load("", env(map[string]string{"DICE_ROLLER_KEY_SPIN": "NoSuchKey"}))
+	assert.ErrorContains(t, err, "DICE_ROLLER_KEY_SPIN")
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	apply := RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-physics", "-size", "300", "-dir", "photos"}))
+
+	c := Default()
+	c.Window.Width = 1024 // Например, из файла: флаг не задан, значение сохраняется
+	apply(&c)
+
+	assert.True(t, c.Cube.Physics)
+	assert.Equal(t, 300.0, c.Cube.Size)
+	assert.Equal(t, "photos", c.Assets.Dir)
+	assert.Equal(t, 1024, c.Window.Width)
+	assert.Nil(t, fs.Lookup("key-spin"), "Keys are configured only in the file and the environment")
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+	RegisterFlags(fs)
+	assert.Error(t, fs.Parse([]string{"-sides", "six"}), "Malformed values are rejected while parsing")
+}
+
+func TestValidate(t *testing.T) {
+	c := Default()
+	c.Window.Width = 0
+	c.Cube.Sides = 7
+	c.Camera.Distance = 10
+	c.Lighting.Ambient[1] = 2
+	c.Animation.Decay = 1.5
+	c.Animation.SnapSpeed = 0
+	c.Keys.History = c.Keys.Spin
+	c.Gamepad.Dice = c.Gamepad.Spin
+	c.Gamepad.Quit = NoButton // Несколько действий без кнопки — не ошибка
+	c.Webhook.URL = "chat.example.com/hook"
+	c.Webhook.Snapshot = "jpeg"
+
+	err := c.Validate()
+	require.Error(t, err)
+	for _, field := range []string{"window.width", "cube.sides", "camera.distance", "lighting.ambient", "animation.decay", "animation.snap_speed", "keys.history", "gamepad.dice", "webhook.url", "webhook.snapshot"} {
+		assert.ErrorContains(t, err, field, "All problems should be reported at once")
+	}
+
+	// Ошибки освещения перечисляются в одном и том же порядке
+	c = Default()
+	c.Lighting.Ambient[0] = -1
+	c.Lighting.Diffuse[1] = 2
+	c.Lighting.Specular[2] = 3
+	first := c.Validate()
+	require.Error(t, first)
+	msg := first.Error()
+	assert.Less(t, strings.Index(msg, "lighting.ambient"), strings.Index(msg, "lighting.diffuse"))
+	assert.Less(t, strings.Index(msg, "lighting.diffuse"), strings.Index(msg, "lighting.specular"))
+	for i := 0; i < 10; i++ {
+		assert.EqualError(t, c.Validate(), msg)
+	}
+
+	c = Default()
+	c.Cube.Size = 500
+	assert.ErrorContains(t, c.Validate(), "cube.size", "The die must fit the window")
+
+	c = Default()
+	c.Webhook.Retries = -1
+	assert.NoError(t, c.Validate(), "Webhook settings are not checked while it is off")
+	c.Webhook.DryRun = true
+	assert.ErrorContains(t, c.Validate(), "webhook.retries")
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
@@ -1,11 +1,10 @@
 package cube
 
 import (
+	"image"
 	"math"
 
 	"github.com/olegshirko/dice_roller/pkg/config"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // Point3D представляет собой вершину в 3D пространстве.
@@ -13,23 +12,70 @@ type Point3D struct {
 	X, Y, Z float64
 }
 
-// Face представляет грань куба.
+// Add возвращает сумму векторов.
+func (p Point3D) Add(o Point3D) Point3D {
+	return Point3D{X: p.X + o.X, Y: p.Y + o.Y, Z: p.Z + o.Z}
+}
+
+// Sub возвращает разность векторов.
+func (p Point3D) Sub(o Point3D) Point3D {
+	return Point3D{X: p.X - o.X, Y: p.Y - o.Y, Z: p.Z - o.Z}
+}
+
+// Scale умножает вектор на скаляр.
+func (p Point3D) Scale(k float64) Point3D {
+	return Point3D{X: p.X * k, Y: p.Y * k, Z: p.Z * k}
+}
+
+// Dot возвращает скалярное произведение.
+func (p Point3D) Dot(o Point3D) float64 {
+	return p.X*o.X + p.Y*o.Y + p.Z*o.Z
+}
+
+// Cross возвращает векторное произведение.
+func (p Point3D) Cross(o Point3D) Point3D {
+	return Point3D{
+		X: p.Y*o.Z - p.Z*o.Y,
+		Y: p.Z*o.X - p.X*o.Z,
+		Z: p.X*o.Y - p.Y*o.X,
+	}
+}
+
+// Length возвращает длину вектора.
+func (p Point3D) Length() float64 {
+	return math.Sqrt(p.Dot(p))
+}
+
+// Normalize возвращает единичный вектор того же направления.
+// Нулевой вектор возвращается без изменений.
+func (p Point3D) Normalize() Point3D {
+	l := p.Length()
+	if l == 0 {
+		return p
+	}
+	return p.Scale(1 / l)
+}
+
+// Face представляет грань многогранника.
 type Face struct {
-	Indices [4]int        // Индексы вершин, образующих грань
-	Texture *ebiten.Image // Текстура грани
-	UVs     [4][2]float32 // UV-координаты для каждой вершины
+	Indices []int        // Индексы вершин, образующих грань
+	Texture image.Image  // Текстура грани
+	UVs     [][2]float32 // UV-координаты для каждой вершины
+	Normal  Point3D      // Внешняя единичная нормаль грани
+	Up      Point3D      // Направление "верха" текстуры в плоскости грани
 }
 
-// Cube содержит геометрию куба.
-type Cube struct {
-	Vertices [8]Point3D
-	Faces    [6]Face
+// Polyhedron содержит геометрию кости: вершины и грани произвольного количества.
+type Polyhedron struct {
+	Name     string // Обозначение кости, например "d6"
+	Vertices []Point3D
+	Faces    []Face
 }
 
 // NewCube создает новый экземпляр куба с определенными вершинами и гранями.
-func NewCube() *Cube {
+func NewCube() *Polyhedron {
 	// Определяем 8 вершин куба
-	vertices := [8]Point3D{
+	vertices := []Point3D{
 		{-config.CubeSize / 2, -config.CubeSize / 2, -config.CubeSize / 2}, // 0
 		{config.CubeSize / 2, -config.CubeSize / 2, -config.CubeSize / 2},  // 1
 		{config.CubeSize / 2, config.CubeSize / 2, -config.CubeSize / 2},   // 2
@@ -41,79 +87,29 @@ func NewCube() *Cube {
 	}
 
 	// Определяем 6 граней куба
-	faces := [6]Face{
-		{Indices: [4]int{0, 1, 2, 3}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Задняя
-		{Indices: [4]int{5, 4, 7, 6}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Передняя
-		{Indices: [4]int{1, 5, 6, 2}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Правая
-		{Indices: [4]int{4, 0, 3, 7}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Левая
-		{Indices: [4]int{3, 2, 6, 7}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Верхняя
-		{Indices: [4]int{4, 5, 1, 0}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Нижняя
+	faces := [][]int{
+		{0, 1, 2, 3}, // Задняя
+		{5, 4, 7, 6}, // Передняя
+		{1, 5, 6, 2}, // Правая
+		{4, 0, 3, 7}, // Левая
+		{3, 2, 6, 7}, // Верхняя
+		{4, 5, 1, 0}, // Нижняя
 	}
 
-	return &Cube{
-		Vertices: vertices,
-		Faces:    faces,
-	}
-}
-
-// GetTargetAnglesForFace вычисляет целевые углы X и Y для ориентации на грань.
-func GetTargetAnglesForFace(faceIndex int) (float64, float64) {
-	pi_2 := math.Pi / 2
-	switch faceIndex {
-	case 0:
-		return 0, math.Pi
-	case 1:
-		return 0, 0
-	case 2:
-		return 0, -pi_2
-	case 3:
-		return 0, pi_2
-	case 4:
-		return pi_2, 0
-	case 5:
-		return -pi_2, 0
-	default:
-		return 0, 0
-	}
+	return newPolyhedron("d6", vertices, faces)
 }
 
-// CalculateAlignmentAngle вычисляет угол, на который нужно довернуть куб для выравнивания.
-func CalculateAlignmentAngle(faceIndex int, targetAngleX, targetAngleY float64) float64 {
-	var upVector Point3D
-	switch faceIndex {
-	case 0, 1, 2, 3:
-		upVector = Point3D{X: 0, Y: -1, Z: 0}
-	case 4:
-		upVector = Point3D{X: 0, Y: 0, Z: 1}
-	case 5:
-		upVector = Point3D{X: 0, Y: 0, Z: -1}
-	default:
-		return 0
+// TargetOrientation вычисляет ориентацию, при которой грань faceIndex обращена
+// к зрителю (ее нормаль смотрит в -Z), а ее "верх" направлен вверх экрана (-Y).
+func (p *Polyhedron) TargetOrientation(faceIndex int) Quaternion {
+	if faceIndex < 0 || faceIndex >= len(p.Faces) {
+		return IdentityQuaternion()
 	}
+	normal := p.Faces[faceIndex].Normal
+	up := p.Faces[faceIndex].Up
+	right := up.Cross(normal)
 
-	cosX, sinX := math.Cos(targetAngleX), math.Sin(targetAngleX)
-	cosY, sinY := math.Cos(targetAngleY), math.Sin(targetAngleY)
-
-	rotatedY := Point3D{
-		X: upVector.X*cosY - upVector.Z*sinY,
-		Y: upVector.Y,
-		Z: upVector.X*sinY + upVector.Z*cosY,
-	}
-	rotatedX := Point3D{
-		X: rotatedY.X,
-		Y: rotatedY.Y*cosX - rotatedY.Z*sinX,
-		Z: rotatedY.Y*sinX + rotatedY.Z*cosX,
-	}
-
-	currentAngle := math.Atan2(rotatedX.Y, rotatedX.X)
-	targetScreenAngle := -math.Pi / 2
-	alignmentAngle := targetScreenAngle - currentAngle
-
-	for alignmentAngle <= -math.Pi {
-		alignmentAngle += 2 * math.Pi
-	}
-	for alignmentAngle > math.Pi {
-		alignmentAngle -= 2 * math.Pi
-	}
-	return alignmentAngle
+	// Строки матрицы поворота переводят базис грани (right, up, normal)
+	// в экранный базис (+X, -Y, -Z).
+	return quaternionFromRows(right, up.Scale(-1), normal.Scale(-1))
 }
@@ -1,6 +1,7 @@
 package cube
 
 import (
+	"fmt"
 	"math"
 	"testing"
 
@@ -18,10 +19,11 @@ func TestNewCube(t *testing.T) {
 	c := NewCube()
 
 	assert.NotNil(t, c, "NewCube should not return nil")
+	assert.Equal(t, "d6", c.Name)
 	assert.Len(t, c.Vertices, 8, "Cube should have 8 vertices")
 	assert.Len(t, c.Faces, 6, "Cube should have 6 faces")
 
-	expectedVertices := [8]Point3D{
+	expectedVertices := []Point3D{
 		{-config.CubeSize / 2, -config.CubeSize / 2, -config.CubeSize / 2},
 		{config.CubeSize / 2, -config.CubeSize / 2, -config.CubeSize / 2},
 		{config.CubeSize / 2, config.CubeSize / 2, -config.CubeSize / 2},
@@ -33,67 +35,97 @@ func TestNewCube(t *testing.T) {
 	}
 	assert.Equal(t, expectedVertices, c.Vertices, "Vertices should be initialized correctly")
 
-	expectedFaces := [6]Face{
-		{Indices: [4]int{0, 1, 2, 3}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Back
-		{Indices: [4]int{5, 4, 7, 6}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Front
-		{Indices: [4]int{1, 5, 6, 2}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Right
-		{Indices: [4]int{4, 0, 3, 7}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Left
-		{Indices: [4]int{3, 2, 6, 7}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Top
-		{Indices: [4]int{4, 5, 1, 0}, UVs: [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, // Bottom
+	expectedUVs := [][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	expectedFaces := []struct {
+		indices []int
+		normal  Point3D
+	}{
+		{[]int{0, 1, 2, 3}, Point3D{0, 0, -1}}, // Back
+		{[]int{5, 4, 7, 6}, Point3D{0, 0, 1}},  // Front
+		{[]int{1, 5, 6, 2}, Point3D{1, 0, 0}},  // Right
+		{[]int{4, 0, 3, 7}, Point3D{-1, 0, 0}}, // Left
+		{[]int{3, 2, 6, 7}, Point3D{0, 1, 0}},  // Top
+		{[]int{4, 5, 1, 0}, Point3D{0, -1, 0}}, // Bottom
 	}
 
 	for i, face := range c.Faces {
-		assert.Equal(t, expectedFaces[i].Indices, face.Indices, "Face %d indices should be correct", i)
-		assert.Equal(t, expectedFaces[i].UVs, face.UVs, "Face %d UVs should be correct", i)
+		assert.Equal(t, expectedFaces[i].indices, face.Indices, "Face %d indices should be correct", i)
+		assert.Equal(t, expectedUVs, face.UVs, "Face %d UVs should be correct", i)
+		assert.True(t, almostEqualPoint(expectedFaces[i].normal, face.Normal), "Face %d normal: expected %v, got %v", i, expectedFaces[i].normal, face.Normal)
 	}
 }
 
-func TestGetTargetAnglesForFace(t *testing.T) {
-	pi_2 := math.Pi / 2
+func almostEqualPoint(a, b Point3D) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
+
+func TestNewPolyhedron(t *testing.T) {
 	cases := []struct {
-		name      string
-		faceIndex int
-		expectedX float64
-		expectedY float64
+		sides    int
+		vertices int
 	}{
-		{"Face 0 (Back)", 0, 0, math.Pi},
-		{"Face 1 (Front)", 1, 0, 0},
-		{"Face 2 (Right)", 2, 0, -pi_2},
-		{"Face 3 (Left)", 3, 0, pi_2},
-		{"Face 4 (Top)", 4, pi_2, 0},
-		{"Face 5 (Bottom)", 5, -pi_2, 0},
-		{"Invalid face index (-1)", -1, 0, 0},
-		{"Invalid face index (6)", 6, 0, 0},
+		{4, 4},
+		{6, 8},
+		{8, 6},
+		{10, 12},
+		{12, 20},
+		{20, 12},
 	}
 
 	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			x, y := GetTargetAnglesForFace(tc.faceIndex)
-			assert.True(t, almostEqual(x, tc.expectedX), "face %d: expected X: %f, got %f", tc.faceIndex, tc.expectedX, x)
-			assert.True(t, almostEqual(y, tc.expectedY), "face %d: expected Y: %f, got %f", tc.faceIndex, tc.expectedY, y)
+		t.Run(fmt.Sprintf("d%d", tc.sides), func(t *testing.T) {
+			p, err := NewPolyhedron(tc.sides)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("d%d", tc.sides), p.Name)
+			assert.Len(t, p.Faces, tc.sides)
+			assert.Len(t, p.Vertices, tc.vertices)
+
+			for i, face := range p.Faces {
+				assert.Len(t, face.UVs, len(face.Indices), "Face %d should have a UV per vertex", i)
+				assert.True(t, almostEqual(face.Normal.Length(), 1), "Face %d normal should be unit length", i)
+				assert.True(t, almostEqual(face.Up.Length(), 1), "Face %d up should be unit length", i)
+				assert.True(t, math.Abs(face.Up.Dot(face.Normal)) < 1e-9, "Face %d up should lie in the face plane", i)
+
+				// Рендерер отсекает грани по направлению обхода: нормаль по первым
+				// трем вершинам должна смотреть внутрь, как у куба.
+				v0 := p.Vertices[face.Indices[0]]
+				winding := p.Vertices[face.Indices[1]].Sub(v0).Cross(p.Vertices[face.Indices[2]].Sub(v0))
+				assert.Less(t, winding.Dot(face.Normal), 0.0, "Face %d should be wound like the cube faces", i)
+
+				for _, idx := range face.Indices {
+					d := p.Vertices[idx].Sub(p.Vertices[face.Indices[0]]).Dot(face.Normal)
+					assert.True(t, math.Abs(d) < 1e-6, "Face %d should be planar", i)
+				}
+			}
 		})
 	}
+
+	_, err := NewPolyhedron(7)
+	assert.Error(t, err, "Unsupported number of sides should return an error")
 }
 
-func TestCalculateAlignmentAngle(t *testing.T) {
-	cases := []struct {
-		name          string
-		faceIndex     int
-		targetAngleX  float64
-		targetAngleY  float64
-		expectedAngle float64
-	}{
-		{"Face 0, no rotation", 0, 0, 0, 0},
-		{"Face 1, 45 deg rotation", 1, math.Pi / 4, math.Pi / 4, 0},
-		{"Face 4, top face", 4, 0, 0, -math.Pi / 2},
-		{"Face 5, bottom face", 5, 0, 0, -math.Pi / 2},
-		{"Invalid face index", -1, 0, 0, 0},
+func TestTargetOrientation(t *testing.T) {
+	// Для каждой грани каждой кости целевая ориентация должна разворачивать грань
+	// к зрителю (нормаль в -Z) и ставить ее "верх" вверх экрана (-Y).
+	for _, sides := range SupportedSides {
+		p, err := NewPolyhedron(sides)
+		assert.NoError(t, err)
+
+		for i, face := range p.Faces {
+			q := p.TargetOrientation(i)
+			normal := q.Rotate(face.Normal)
+			up := q.Rotate(face.Up)
+
+			assert.True(t, almostEqualPoint(Point3D{0, 0, -1}, normal), "%s face %d: normal should face the viewer, got %v", p.Name, i, normal)
+			assert.True(t, almostEqualPoint(Point3D{0, -1, 0}, up), "%s face %d: up should point up the screen, got %v", p.Name, i, up)
+		}
 	}
+}
 
-	for _, tc := range cases {
-		t.Run(tc.name, func(t *testing.T) {
-			angle := CalculateAlignmentAngle(tc.faceIndex, tc.targetAngleX, tc.targetAngleY)
-			assert.True(t, almostEqual(angle, tc.expectedAngle), "test '%s': expected angle %f, got %f", tc.name, tc.expectedAngle, angle)
-		})
+func TestTargetOrientation_InvalidIndex(t *testing.T) {
+	c := NewCube()
+
+	for _, index := range []int{-1, 6} {
+		assert.Equal(t, IdentityQuaternion(), c.TargetOrientation(index), "face %d: expected identity orientation", index)
 	}
 }
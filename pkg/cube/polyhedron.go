@@ -0,0 +1,310 @@
+package cube
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+)
+
+// geometryEpsilon — допуск при сравнении координат вершин единичных многогранников.
+const geometryEpsilon = 1e-6
+
+// SupportedSides перечисляет количества граней, для которых есть модель кости.
+var SupportedSides = []int{4, 6, 8, 10, 12, 20}
+
+// NewPolyhedron создает кость с указанным количеством граней (d4, d6, d8, d10, d12, d20).
+func NewPolyhedron(sides int) (*Polyhedron, error) {
+	switch sides {
+	case 4:
+		return NewTetrahedron(), nil
+	case 6:
+		return NewCube(), nil
+	case 8:
+		return NewOctahedron(), nil
+	case 10:
+		return NewTrapezohedron(), nil
+	case 12:
+		return NewDodecahedron(), nil
+	case 20:
+		return NewIcosahedron(), nil
+	default:
+		return nil, fmt.Errorf("unsupported number of sides: %d (supported: %v)", sides, SupportedSides)
+	}
+}
+
+// NewTetrahedron создает четырехгранную кость (d4).
+func NewTetrahedron() *Polyhedron {
+	vertices := []Point3D{
+		{1, 1, 1},
+		{1, -1, -1},
+		{-1, 1, -1},
+		{-1, -1, 1},
+	}
+	return newPolyhedron("d4", scaleToCubeRadius(vertices), hullFaces(vertices))
+}
+
+// NewOctahedron создает восьмигранную кость (d8).
+func NewOctahedron() *Polyhedron {
+	vertices := []Point3D{
+		{1, 0, 0}, {-1, 0, 0},
+		{0, 1, 0}, {0, -1, 0},
+		{0, 0, 1}, {0, 0, -1},
+	}
+	return newPolyhedron("d8", scaleToCubeRadius(vertices), hullFaces(vertices))
+}
+
+// NewTrapezohedron создает десятигранную кость (d10) в форме пятиугольного трапецоэдра.
+func NewTrapezohedron() *Polyhedron {
+	// Вершины пояса попеременно подняты и опущены на ringZ. Высота полюсов
+	// подобрана так, чтобы каждая грань-"дельтоид" была плоской.
+	const ringZ = 0.1
+	cos36 := math.Cos(math.Pi / 5)
+	poleZ := ringZ * (1 + cos36) / (1 - cos36)
+
+	vertices := []Point3D{{0, 0, poleZ}, {0, 0, -poleZ}} // 0 — верхний полюс, 1 — нижний
+	for k := 0; k < 10; k++ {
+		angle := float64(k) * math.Pi / 5
+		z := ringZ
+		if k%2 == 1 {
+			z = -ringZ
+		}
+		vertices = append(vertices, Point3D{X: math.Cos(angle), Y: math.Sin(angle), Z: z})
+	}
+
+	ring := func(k int) int { return 2 + (k+10)%10 }
+	faces := make([][]int, 0, 10)
+	for j := 0; j < 5; j++ {
+		// Полюс идет первым: он задает "верх" грани.
+		faces = append(faces, []int{0, ring(2 * j), ring(2*j + 1), ring(2*j + 2)})
+		faces = append(faces, []int{1, ring(2*j + 1), ring(2*j + 2), ring(2*j + 3)})
+	}
+	return newPolyhedron("d10", scaleToCubeRadius(vertices), faces)
+}
+
+// NewDodecahedron создает двенадцатигранную кость (d12).
+func NewDodecahedron() *Polyhedron {
+	phi := (1 + math.Sqrt(5)) / 2
+	vertices := []Point3D{}
+	for _, x := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, z := range []float64{-1, 1} {
+				vertices = append(vertices, Point3D{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	for _, a := range []float64{-1 / phi, 1 / phi} {
+		for _, b := range []float64{-phi, phi} {
+			vertices = append(vertices,
+				Point3D{X: 0, Y: a, Z: b},
+				Point3D{X: a, Y: b, Z: 0},
+				Point3D{X: b, Y: 0, Z: a},
+			)
+		}
+	}
+	return newPolyhedron("d12", scaleToCubeRadius(vertices), hullFaces(vertices))
+}
+
+// NewIcosahedron создает двадцатигранную кость (d20).
+func NewIcosahedron() *Polyhedron {
+	phi := (1 + math.Sqrt(5)) / 2
+	vertices := []Point3D{}
+	for _, a := range []float64{-1, 1} {
+		for _, b := range []float64{-phi, phi} {
+			vertices = append(vertices,
+				Point3D{X: 0, Y: a, Z: b},
+				Point3D{X: a, Y: b, Z: 0},
+				Point3D{X: b, Y: 0, Z: a},
+			)
+		}
+	}
+	return newPolyhedron("d20", scaleToCubeRadius(vertices), hullFaces(vertices))
+}
+
+// newPolyhedron собирает многогранник из вершин и списков индексов граней,
+// вычисляя для каждой грани нормаль, направление "верха" и UV-координаты.
+func newPolyhedron(name string, vertices []Point3D, faceIndices [][]int) *Polyhedron {
+	faces := make([]Face, len(faceIndices))
+	for i, indices := range faceIndices {
+		faces[i] = newFace(vertices, indices)
+	}
+	return &Polyhedron{
+		Name:     name,
+		Vertices: vertices,
+		Faces:    faces,
+	}
+}
+
+// newFace вычисляет геометрические параметры грани.
+// Обход вершин приводится к тому же направлению, что и у куба (нормаль по
+// правилу правой руки смотрит внутрь), на это рассчитано отсечение в рендерере.
+func newFace(vertices []Point3D, indices []int) Face {
+	indices = append([]int(nil), indices...)
+	center := faceCenter(vertices, indices)
+
+	// Нормаль по методу Ньюэлла устойчива для любых плоских многоугольников.
+	var newell Point3D
+	for i := range indices {
+		cur := vertices[indices[i]]
+		next := vertices[indices[(i+1)%len(indices)]]
+		newell.X += (cur.Y - next.Y) * (cur.Z + next.Z)
+		newell.Y += (cur.Z - next.Z) * (cur.X + next.X)
+		newell.Z += (cur.X - next.X) * (cur.Y + next.Y)
+	}
+	normal := newell.Normalize()
+	if normal.Dot(center) < 0 {
+		normal = normal.Scale(-1)
+	} else {
+		// Обход против часовой стрелки снаружи: разворачиваем, сохраняя первую вершину.
+		for l, r := 1, len(indices)-1; l < r; l, r = l+1, r-1 {
+			indices[l], indices[r] = indices[r], indices[l]
+		}
+	}
+
+	up := faceUp(vertices, indices, center, normal)
+	right := up.Cross(normal)
+
+	// UV-координаты растягивают текстуру по габаритам грани в базисе (right, up),
+	// так что верхний край текстуры совпадает с "верхом" грани.
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	local := make([][2]float64, len(indices))
+	for i, idx := range indices {
+		d := vertices[idx].Sub(center)
+		local[i] = [2]float64{d.Dot(right), d.Dot(up)}
+		minX, maxX = math.Min(minX, local[i][0]), math.Max(maxX, local[i][0])
+		minY, maxY = math.Min(minY, local[i][1]), math.Max(maxY, local[i][1])
+	}
+	uvs := make([][2]float32, len(indices))
+	for i, l := range local {
+		uvs[i] = [2]float32{
+			float32((l[0] - minX) / (maxX - minX)),
+			float32((maxY - l[1]) / (maxY - minY)),
+		}
+	}
+
+	return Face{
+		Indices: indices,
+		UVs:     uvs,
+		Normal:  normal,
+		Up:      up,
+	}
+}
+
+// faceUp выбирает направление "верха" грани. У правильных многоугольников с
+// четным числом вершин верхом служит середина первого ребра (как у квадрата),
+// в остальных случаях — первая вершина (вершина треугольника, полюс дельтоида).
+func faceUp(vertices []Point3D, indices []int, center, normal Point3D) Point3D {
+	target := vertices[indices[0]]
+	if len(indices)%2 == 0 && isRegular(vertices, indices, center) {
+		target = vertices[indices[0]].Add(vertices[indices[1]]).Scale(0.5)
+	}
+	up := target.Sub(center)
+	// Убираем возможную составляющую вдоль нормали.
+	up = up.Sub(normal.Scale(up.Dot(normal)))
+	return up.Normalize()
+}
+
+// isRegular проверяет, что все вершины грани равноудалены от ее центра.
+func isRegular(vertices []Point3D, indices []int, center Point3D) bool {
+	r := vertices[indices[0]].Sub(center).Length()
+	for _, idx := range indices[1:] {
+		if math.Abs(vertices[idx].Sub(center).Length()-r) > geometryEpsilon*math.Max(1, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// faceCenter возвращает среднее арифметическое вершин грани.
+func faceCenter(vertices []Point3D, indices []int) Point3D {
+	var c Point3D
+	for _, idx := range indices {
+		c = c.Add(vertices[idx])
+	}
+	return c.Scale(1 / float64(len(indices)))
+}
+
+// hullFaces находит грани выпуклой оболочки набора вершин, центрированного в начале координат.
+// Вершины каждой грани упорядочены по обходу многоугольника.
+func hullFaces(vertices []Point3D) [][]int {
+	var faces [][]int
+	var normals []Point3D
+
+	for i := 0; i < len(vertices); i++ {
+		for j := i + 1; j < len(vertices); j++ {
+			for k := j + 1; k < len(vertices); k++ {
+				n := vertices[j].Sub(vertices[i]).Cross(vertices[k].Sub(vertices[i]))
+				if n.Length() < geometryEpsilon {
+					continue
+				}
+				n = n.Normalize()
+				if n.Dot(vertices[i]) < 0 {
+					n = n.Scale(-1)
+				}
+				d := n.Dot(vertices[i])
+
+				onPlane := []int{}
+				supporting := true
+				for idx, v := range vertices {
+					s := n.Dot(v) - d
+					if s > geometryEpsilon {
+						supporting = false
+						break
+					}
+					if s > -geometryEpsilon {
+						onPlane = append(onPlane, idx)
+					}
+				}
+				if !supporting || containsNormal(normals, n) {
+					continue
+				}
+				normals = append(normals, n)
+				faces = append(faces, sortAroundCenter(vertices, onPlane, n))
+			}
+		}
+	}
+	return faces
+}
+
+// containsNormal проверяет, встречалась ли уже плоскость с такой нормалью.
+func containsNormal(normals []Point3D, n Point3D) bool {
+	for _, m := range normals {
+		if m.Sub(n).Length() < geometryEpsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// sortAroundCenter упорядочивает вершины грани по углу вокруг ее центра.
+func sortAroundCenter(vertices []Point3D, indices []int, normal Point3D) []int {
+	center := faceCenter(vertices, indices)
+	axisX := vertices[indices[0]].Sub(center).Normalize()
+	axisY := normal.Cross(axisX)
+	angle := func(idx int) float64 {
+		d := vertices[idx].Sub(center)
+		return math.Atan2(d.Dot(axisY), d.Dot(axisX))
+	}
+	sorted := append([]int(nil), indices...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return angle(sorted[a]) < angle(sorted[b])
+	})
+	return sorted
+}
+
+// scaleToCubeRadius масштабирует вершины так, чтобы радиус описанной сферы
+// совпадал с радиусом описанной сферы куба, — кости разных типов выглядят соразмерно.
+func scaleToCubeRadius(vertices []Point3D) []Point3D {
+	maxR := 0.0
+	for _, v := range vertices {
+		maxR = math.Max(maxR, v.Length())
+	}
+	k := config.CubeSize / 2 * math.Sqrt(3) / maxR
+	scaled := make([]Point3D, len(vertices))
+	for i, v := range vertices {
+		scaled[i] = v.Scale(k)
+	}
+	return scaled
+}
@@ -0,0 +1,119 @@
+package cube
+
+import "math"
+
+// Quaternion описывает ориентацию кости в пространстве (единичный кватернион).
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// IdentityQuaternion возвращает кватернион без поворота.
+func IdentityQuaternion() Quaternion {
+	return Quaternion{W: 1}
+}
+
+// QuaternionFromAxisAngle создает кватернион поворота на угол angle (в радианах) вокруг оси axis.
+func QuaternionFromAxisAngle(axis Point3D, angle float64) Quaternion {
+	axis = axis.Normalize()
+	s := math.Sin(angle / 2)
+	return Quaternion{
+		W: math.Cos(angle / 2),
+		X: axis.X * s,
+		Y: axis.Y * s,
+		Z: axis.Z * s,
+	}
+}
+
+// quaternionFromRows создает кватернион из матрицы поворота, заданной строками.
+func quaternionFromRows(r0, r1, r2 Point3D) Quaternion {
+	trace := r0.X + r1.Y + r2.Z
+	var q Quaternion
+	switch {
+	case trace > 0:
+		s := math.Sqrt(trace+1) * 2
+		q = Quaternion{W: s / 4, X: (r2.Y - r1.Z) / s, Y: (r0.Z - r2.X) / s, Z: (r1.X - r0.Y) / s}
+	case r0.X > r1.Y && r0.X > r2.Z:
+		s := math.Sqrt(1+r0.X-r1.Y-r2.Z) * 2
+		q = Quaternion{W: (r2.Y - r1.Z) / s, X: s / 4, Y: (r0.Y + r1.X) / s, Z: (r0.Z + r2.X) / s}
+	case r1.Y > r2.Z:
+		s := math.Sqrt(1+r1.Y-r0.X-r2.Z) * 2
+		q = Quaternion{W: (r0.Z - r2.X) / s, X: (r0.Y + r1.X) / s, Y: s / 4, Z: (r1.Z + r2.Y) / s}
+	default:
+		s := math.Sqrt(1+r2.Z-r0.X-r1.Y) * 2
+		q = Quaternion{W: (r1.X - r0.Y) / s, X: (r0.Z + r2.X) / s, Y: (r1.Z + r2.Y) / s, Z: s / 4}
+	}
+	return q.Normalize()
+}
+
+// Mul возвращает композицию поворотов: сначала r, затем q.
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+// Conjugate возвращает обратный поворот.
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Dot возвращает скалярное произведение кватернионов.
+func (q Quaternion) Dot(r Quaternion) float64 {
+	return q.W*r.W + q.X*r.X + q.Y*r.Y + q.Z*r.Z
+}
+
+// Normalize приводит кватернион к единичной длине, компенсируя накопленную погрешность.
+func (q Quaternion) Normalize() Quaternion {
+	l := math.Sqrt(q.Dot(q))
+	if l == 0 {
+		return IdentityQuaternion()
+	}
+	return Quaternion{W: q.W / l, X: q.X / l, Y: q.Y / l, Z: q.Z / l}
+}
+
+// Rotate поворачивает точку.
+func (q Quaternion) Rotate(v Point3D) Point3D {
+	u := Point3D{X: q.X, Y: q.Y, Z: q.Z}
+	t := u.Cross(v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
+
+// AngleTo возвращает угол кратчайшего поворота от q к r в радианах.
+func (q Quaternion) AngleTo(r Quaternion) float64 {
+	d := math.Min(math.Abs(q.Dot(r)), 1)
+	return 2 * math.Acos(d)
+}
+
+// Slerp выполняет сферическую интерполяцию от a к b по кратчайшей дуге, t от 0 до 1.
+func Slerp(a, b Quaternion, t float64) Quaternion {
+	d := a.Dot(b)
+	if d < 0 {
+		// q и -q задают одну ориентацию: идем по короткому пути.
+		b = Quaternion{W: -b.W, X: -b.X, Y: -b.Y, Z: -b.Z}
+		d = -d
+	}
+
+	if d > 0.9995 {
+		// Почти совпадающие ориентации: линейной интерполяции достаточно.
+		return Quaternion{
+			W: a.W + (b.W-a.W)*t,
+			X: a.X + (b.X-a.X)*t,
+			Y: a.Y + (b.Y-a.Y)*t,
+			Z: a.Z + (b.Z-a.Z)*t,
+		}.Normalize()
+	}
+
+	theta := math.Acos(d)
+	sinTheta := math.Sin(theta)
+	wa := math.Sin((1-t)*theta) / sinTheta
+	wb := math.Sin(t*theta) / sinTheta
+	return Quaternion{
+		W: a.W*wa + b.W*wb,
+		X: a.X*wa + b.X*wb,
+		Y: a.Y*wa + b.Y*wb,
+		Z: a.Z*wa + b.Z*wb,
+	}
+}
@@ -0,0 +1,60 @@
+package cube
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuaternionFromAxisAngle(t *testing.T) {
+	cases := []struct {
+		name     string
+		axis     Point3D
+		angle    float64
+		point    Point3D
+		expected Point3D
+	}{
+		{"Identity", Point3D{0, 0, 1}, 0, Point3D{1, 2, 3}, Point3D{1, 2, 3}},
+		{"90 deg around Z", Point3D{0, 0, 1}, math.Pi / 2, Point3D{1, 0, 0}, Point3D{0, 1, 0}},
+		{"90 deg around X", Point3D{1, 0, 0}, math.Pi / 2, Point3D{0, 1, 0}, Point3D{0, 0, 1}},
+		{"180 deg around Y", Point3D{0, 1, 0}, math.Pi, Point3D{1, 0, 0}, Point3D{-1, 0, 0}},
+		{"Non-normalized axis", Point3D{0, 0, 5}, math.Pi / 2, Point3D{1, 0, 0}, Point3D{0, 1, 0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := QuaternionFromAxisAngle(tc.axis, tc.angle).Rotate(tc.point)
+			assert.True(t, almostEqualPoint(tc.expected, got), "expected %v, got %v", tc.expected, got)
+		})
+	}
+}
+
+func TestQuaternionMul(t *testing.T) {
+	rotX := QuaternionFromAxisAngle(Point3D{1, 0, 0}, math.Pi/2)
+	rotZ := QuaternionFromAxisAngle(Point3D{0, 0, 1}, math.Pi/2)
+
+	// Сначала поворот вокруг X, затем вокруг Z.
+	composed := rotZ.Mul(rotX)
+	p := Point3D{0, 1, 0}
+	expected := rotZ.Rotate(rotX.Rotate(p))
+
+	assert.True(t, almostEqualPoint(expected, composed.Rotate(p)), "composition should match sequential rotations")
+	assert.True(t, almostEqualPoint(p, rotX.Conjugate().Rotate(rotX.Rotate(p))), "conjugate should undo the rotation")
+}
+
+func TestSlerp(t *testing.T) {
+	a := IdentityQuaternion()
+	b := QuaternionFromAxisAngle(Point3D{0, 1, 0}, math.Pi/2)
+
+	assert.True(t, almostEqual(0, Slerp(a, b, 0).AngleTo(a)), "t=0 should return the start")
+	assert.True(t, almostEqual(0, Slerp(a, b, 1).AngleTo(b)), "t=1 should return the end")
+
+	mid := Slerp(a, b, 0.5)
+	assert.True(t, almostEqual(math.Pi/4, mid.AngleTo(a)), "midpoint should be halfway from the start")
+	assert.True(t, almostEqual(math.Pi/4, mid.AngleTo(b)), "midpoint should be halfway from the end")
+
+	// -b задает ту же ориентацию, интерполяция должна идти по короткой дуге.
+	negB := Quaternion{W: -b.W, X: -b.X, Y: -b.Y, Z: -b.Z}
+	assert.True(t, almostEqual(math.Pi/4, Slerp(a, negB, 0.5).AngleTo(a)), "slerp should take the shortest path")
+}
@@ -0,0 +1,145 @@
+// Package dice разбирает и бросает выражения в нотации настольных игр:
+// "3d6", "d20+5", "4d6kh3", "2d20kl1+5", "3d6!", "d%".
+//
+// Грамматика:
+//
+//	expr     = [ "+" | "-" ] term { ( "+" | "-" ) term }
+//	term     = number | roll
+//	roll     = [ number ] "d" ( number | "%" ) { modifier }
+//	modifier = "!" | ( "kh" | "kl" | "k" | "dh" | "dl" ) number
+//
+// "!" — взрывающиеся кости: выпавший максимум добавляет еще одну кость.
+// "khN"/"klN" оставляют N наибольших или наименьших костей ("kN" — то же, что "khN"),
+// "dhN"/"dlN" отбрасывают N наибольших или наименьших. "d%" — то же, что "d100".
+package dice
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ограничения выражения, чтобы опечатка вроде "3000000d6" не заняла всю память.
+const (
+	MaxCount      = 1000    // Костей в одном броске
+	MaxSides      = 1000    // Граней у кости
+	MaxNumber     = 1000000 // Наибольшая числовая константа
+	MaxExplosions = 100     // Дополнительных костей от взрывов в одном броске
+)
+
+// Node — узел синтаксического дерева выражения.
+type Node interface {
+	// String возвращает узел в каноническом виде.
+	String() string
+	// eval вычисляет узел, дописывая броски и расшифровку в res.
+	eval(r *roller) int
+}
+
+// Number — числовая константа.
+type Number struct {
+	Value int
+}
+
+// String реализует Node.
+func (n *Number) String() string {
+	return fmt.Sprint(n.Value)
+}
+
+// Selection — какие кости броска учитываются в сумме.
+type Selection struct {
+	Drop   bool // Отбросить N костей, иначе оставить N
+	Lowest bool // Наименьшие кости, иначе наибольшие
+	N      int
+}
+
+// String возвращает модификатор в нотации: "kh3", "dl1".
+func (s *Selection) String() string {
+	op, side := "k", "h"
+	if s.Drop {
+		op = "d"
+	}
+	if s.Lowest {
+		side = "l"
+	}
+	return fmt.Sprintf("%s%s%d", op, side, s.N)
+}
+
+// Dice — бросок Count костей с Sides гранями.
+type Dice struct {
+	Count      int
+	Sides      int
+	Percentile bool       // Записано как "d%"
+	Explode    bool       // Максимум добавляет еще одну кость
+	Select     *Selection // Какие кости учитываются; nil — все
+}
+
+// String реализует Node.
+func (d *Dice) String() string {
+	var b strings.Builder
+	if d.Percentile {
+		fmt.Fprintf(&b, "%dd%%", d.Count)
+	} else {
+		fmt.Fprintf(&b, "%dd%d", d.Count, d.Sides)
+	}
+	if d.Explode {
+		b.WriteString("!")
+	}
+	if d.Select != nil {
+		b.WriteString(d.Select.String())
+	}
+	return b.String()
+}
+
+// Binary — сумма или разность двух выражений.
+type Binary struct {
+	Op          byte // '+' или '-'
+	Left, Right Node
+}
+
+// String реализует Node.
+func (b *Binary) String() string {
+	return fmt.Sprintf("%s%c%s", b.Left, b.Op, b.Right)
+}
+
+// Negate — выражение с минусом перед первым слагаемым: "-1+d6".
+type Negate struct {
+	X Node
+}
+
+// String реализует Node.
+func (n *Negate) String() string {
+	return "-" + n.X.String()
+}
+
+// Expr — разобранное выражение.
+type Expr struct {
+	Root Node
+}
+
+// String возвращает выражение в каноническом виде.
+func (e *Expr) String() string {
+	return e.Root.String()
+}
+
+// MarshalText записывает выражение в JSON и другие текстовые форматы в каноническом виде.
+func (e *Expr) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// Dice возвращает все броски выражения слева направо.
+func (e *Expr) Dice() []*Dice {
+	var dice []*Dice
+	var walk func(n Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Dice:
+			dice = append(dice, n)
+		case *Binary:
+			walk(n.Left)
+			walk(n.Right)
+		case *Negate:
+			walk(n.X)
+		}
+	}
+	walk(e.Root)
+	return dice
+}
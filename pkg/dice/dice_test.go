@@ -0,0 +1,115 @@
+package dice
+
+import (
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for src, want := range map[string]string{
+		"3d6":        "3d6",
+		"d20":        "1d20",
+		"2D8 + 3":    "2d8+3",
+		"1d4-1":      "1d4-1",
+		"4d6kh3":     "4d6kh3",
+		"4d6k3":      "4d6kh3",
+		"2d20kl1+5":  "2d20kl1+5",
+		"3d6!":       "3d6!",
+		"5d10!dl2":   "5d10!dl2",
+		"4d6dh1!":    "4d6!dh1",
+		"d%":         "1d%",
+		"-1+d6":      "-1+1d6",
+		"d6+d8-2+d4": "1d6+1d8-2+1d4",
+		"  2d6 + 1 ": "2d6+1",
+	} {
+		e, err := Parse(src)
+		require.NoError(t, err, src)
+		assert.Equal(t, want, e.String(), src)
+	}
+
+	d, err := Parse("2d20kl1+5")
+	require.NoError(t, err)
+	bin, ok := d.Root.(*Binary)
+	require.True(t, ok, "Sums should parse into a binary node")
+	assert.Equal(t, &Dice{Count: 2, Sides: 20, Select: &Selection{Lowest: true, N: 1}}, bin.Left)
+	assert.Equal(t, &Number{Value: 5}, bin.Right)
+}
+
+func TestParse_Errors(t *testing.T) {
+	for src, pos := range map[string]int{
+		"":         1,
+		"3x6":      2,
+		"3d":       3,
+		"3d1":      3,
+		"0d6":      3,
+		"5000d6":   6,
+		"3d6+":     5,
+		"4d6kh":    6,
+		"4d6kh0":   4,
+		"4d6kh3k1": 7,
+		"3d6!!":    5,
+		"2d6 3":    5,
+	} {
+		_, err := Parse(src)
+		var syntax *SyntaxError
+		require.ErrorAs(t, err, &syntax, "%q should not parse", src)
+		assert.Equal(t, pos, syntax.Pos, "%q: %v", src, err)
+	}
+}
+
+func TestRoll(t *testing.T) {
+	e, err := Parse("3d6+1")
+	require.NoError(t, err)
+
+	res := e.Roll(random.NewScripted([]int{3, 1, 5}, nil))
+	assert.Equal(t, 13, res.Total)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, 12, res.Groups[0].Sum)
+	assert.Equal(t, "3d6+1: 3d6[4, 2, 6] + 1 = 13", res.String())
+
+	// Один и тот же генератор с одним зерном дает один и тот же бросок
+	assert.Equal(t, e.Roll(random.NewPCG(7)), e.Roll(random.NewPCG(7)))
+}
+
+func TestRoll_KeepDrop(t *testing.T) {
+	for src, want := range map[string]string{
+		"4d6kh3":    "4d6kh3: 4d6kh3[2, (1), 6, 2] = 10",
+		"4d6kl1+5":  "4d6kl1+5: 4d6kl1[(2), 1, (6), (2)] + 5 = 6",
+		"4d6dh1":    "4d6dh1: 4d6dh1[2, 1, (6), 2] = 5",
+		"4d6dl2":    "4d6dl2: 4d6dl2[(2), (1), 6, 2] = 8", // При равенстве раньше отбрасывается ранняя кость
+		"4d6kh9":    "4d6kh9: 4d6kh9[2, 1, 6, 2] = 11",
+		"-4d6dl3+1": "-4d6dl3+1: -4d6dl3[(2), (1), 6, (2)] + 1 = -5",
+	} {
+		e, err := Parse(src)
+		require.NoError(t, err, src)
+		res := e.Roll(random.NewScripted([]int{1, 0, 5, 1}, nil))
+		assert.Equal(t, want, res.String(), src)
+	}
+}
+
+func TestRoll_Explode(t *testing.T) {
+	e, err := Parse("3d6!")
+	require.NoError(t, err)
+
+	// 6 взрывается дважды, затем выпадает 3; потом 2 и 4
+	res := e.Roll(random.NewScripted([]int{5, 5, 2, 1, 3}, nil))
+	assert.Equal(t, "3d6!: 3d6![6!, 6!, 3, 2, 4] = 21", res.String())
+	assert.Len(t, res.Groups[0].Dice, 5)
+	assert.True(t, res.Groups[0].Dice[0].Exploded)
+
+	// Кость, которая всегда выпадает максимумом, взрывается не бесконечно
+	res = e.Roll(random.NewScripted([]int{5}, nil))
+	assert.Len(t, res.Groups[0].Dice, 3+MaxExplosions)
+}
+
+func TestRoll_Percentile(t *testing.T) {
+	e, err := Parse("d%")
+	require.NoError(t, err)
+	res := e.Roll(random.NewScripted([]int{99}, nil))
+	assert.Equal(t, 100, res.Total)
+	assert.Equal(t, 100, res.Groups[0].Dice[0].Sides)
+	assert.Len(t, e.Dice(), 1)
+}
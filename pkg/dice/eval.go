@@ -0,0 +1,126 @@
+package dice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/olegshirko/dice_roller/pkg/random"
+)
+
+// Die — одна брошенная кость.
+type Die struct {
+	Sides    int  `json:"sides"`
+	Value    int  `json:"value"`
+	Dropped  bool `json:"dropped,omitempty"`  // Не учитывается в сумме из-за модификатора keep/drop
+	Exploded bool `json:"exploded,omitempty"` // Выпал максимум, и следом брошена еще одна кость
+}
+
+// Group — кости одного броска выражения, например "4d6kh3".
+type Group struct {
+	Term string `json:"term"`
+	Dice []Die  `json:"dice"`
+	Sum  int    `json:"sum"` // Сумма учтенных костей
+}
+
+// Result — итог броска выражения.
+type Result struct {
+	Expr      *Expr   `json:"expr"`
+	Groups    []Group `json:"groups"`
+	Total     int     `json:"total"`
+	Breakdown string  `json:"breakdown"` // Расшифровка по костям: "4d6kh3[6, 5, 3, (2)] + 5"
+}
+
+// String возвращает результат с расшифровкой: "4d6kh3+5: 4d6kh3[6, 5, 3, (2)] + 5 = 19".
+func (res Result) String() string {
+	return fmt.Sprintf("%s: %s = %d", res.Expr, res.Breakdown, res.Total)
+}
+
+// roller вычисляет выражение, накапливая броски и расшифровку.
+type roller struct {
+	rnd       random.Source
+	groups    []Group
+	breakdown strings.Builder
+}
+
+// Roll бросает кости выражения, беря случайные числа из rnd.
+func (e *Expr) Roll(rnd random.Source) Result {
+	r := &roller{rnd: rnd}
+	total := e.Root.eval(r)
+	return Result{Expr: e, Groups: r.groups, Total: total, Breakdown: r.breakdown.String()}
+}
+
+func (n *Number) eval(r *roller) int {
+	r.breakdown.WriteString(n.String())
+	return n.Value
+}
+
+func (b *Binary) eval(r *roller) int {
+	left := b.Left.eval(r)
+	fmt.Fprintf(&r.breakdown, " %c ", b.Op)
+	right := b.Right.eval(r)
+	if b.Op == '-' {
+		return left - right
+	}
+	return left + right
+}
+
+func (n *Negate) eval(r *roller) int {
+	r.breakdown.WriteString("-")
+	return -n.X.eval(r)
+}
+
+func (d *Dice) eval(r *roller) int {
+	g := Group{Term: d.String()}
+	explosions := 0
+	for i := 0; i < d.Count; i++ {
+		die := Die{Sides: d.Sides, Value: r.rnd.Intn(d.Sides) + 1}
+		for d.Explode && die.Value == d.Sides && explosions < MaxExplosions {
+			die.Exploded = true
+			g.Dice = append(g.Dice, die)
+			die = Die{Sides: d.Sides, Value: r.rnd.Intn(d.Sides) + 1}
+			explosions++
+		}
+		g.Dice = append(g.Dice, die)
+	}
+	if d.Select != nil {
+		d.Select.apply(g.Dice)
+	}
+
+	parts := make([]string, len(g.Dice))
+	for i, die := range g.Dice {
+		parts[i] = fmt.Sprint(die.Value)
+		if die.Exploded {
+			parts[i] += "!"
+		}
+		if die.Dropped {
+			parts[i] = "(" + parts[i] + ")"
+		} else {
+			g.Sum += die.Value
+		}
+	}
+	fmt.Fprintf(&r.breakdown, "%s[%s]", g.Term, strings.Join(parts, ", "))
+	r.groups = append(r.groups, g)
+	return g.Sum
+}
+
+// apply отмечает кости, не попавшие в сумму. При равных значениях первыми
+// оставляются (или отбрасываются) кости, брошенные раньше.
+func (s *Selection) apply(dice []Die) {
+	order := make([]int, len(dice))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if s.Lowest {
+			return dice[order[a]].Value < dice[order[b]].Value
+		}
+		return dice[order[a]].Value > dice[order[b]].Value
+	})
+
+	// order начинается с костей, которые модификатор оставляет (k) или отбрасывает (d)
+	n := min(s.N, len(dice))
+	for rank, i := range order {
+		dice[i].Dropped = (rank < n) == s.Drop
+	}
+}
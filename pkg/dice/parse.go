@@ -0,0 +1,200 @@
+package dice
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError — ошибка разбора с позицией в исходном выражении.
+type SyntaxError struct {
+	Expr string
+	Pos  int // Позиция в символах, начиная с 1
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%q: at %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// parser — разбор рекурсивным спуском. Пробелы между частями выражения игнорируются.
+type parser struct {
+	src string
+	pos int
+}
+
+// Parse разбирает выражение с костями.
+func Parse(src string) (*Expr, error) {
+	p := &parser{src: strings.ToLower(src)}
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return &Expr{Root: root}, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Expr: strings.TrimSpace(p.src), Pos: p.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek возвращает следующий значимый символ или 0 в конце выражения.
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// accept съедает prefix, если выражение продолжается им.
+func (p *parser) accept(prefix string) bool {
+	if strings.HasPrefix(p.src[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expr() (Node, error) {
+	negate := false
+	switch p.peek() {
+	case '-':
+		negate = true
+		p.pos++
+	case '+':
+		p.pos++
+	}
+
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		left = &Negate{X: left}
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) term() (Node, error) {
+	c := p.peek()
+	if c == 0 {
+		return nil, p.errorf("expected a number or dice like 3d6")
+	}
+	if c != 'd' && !isDigit(c) {
+		return nil, p.errorf("unexpected %q", c)
+	}
+
+	count, hasCount := 1, false
+	if isDigit(c) {
+		n, err := p.number(MaxNumber)
+		if err != nil {
+			return nil, err
+		}
+		count, hasCount = n, true
+	}
+	if !p.accept("d") {
+		return &Number{Value: count}, nil
+	}
+	if hasCount && (count < 1 || count > MaxCount) {
+		return nil, p.errorf("number of dice must be between 1 and %d", MaxCount)
+	}
+	return p.roll(count)
+}
+
+// roll разбирает часть броска после "d": число граней и модификаторы.
+func (p *parser) roll(count int) (Node, error) {
+	d := &Dice{Count: count}
+	switch {
+	case p.accept("%"):
+		d.Sides, d.Percentile = 100, true
+	case p.pos < len(p.src) && isDigit(p.src[p.pos]):
+		start := p.pos
+		n, err := p.number(MaxSides)
+		if err != nil {
+			return nil, err
+		}
+		if n < 2 {
+			p.pos = start
+			return nil, p.errorf("a die needs at least 2 sides")
+		}
+		d.Sides = n
+	default:
+		return nil, p.errorf(`expected the number of sides or "%%" after "d"`)
+	}
+
+	for {
+		start := p.pos
+		switch {
+		case p.accept("!"):
+			if d.Explode {
+				p.pos = start
+				return nil, p.errorf(`"!" is given twice`)
+			}
+			d.Explode = true
+		case p.accept("kh"), p.accept("kl"), p.accept("k"), p.accept("dh"), p.accept("dl"):
+			if d.Select != nil {
+				p.pos = start
+				return nil, p.errorf("only one keep or drop modifier is allowed")
+			}
+			op := p.src[start:p.pos]
+			sel := &Selection{Drop: op[0] == 'd', Lowest: op == "kl" || op == "dl"}
+			if p.pos >= len(p.src) || !isDigit(p.src[p.pos]) {
+				verb := "keep"
+				if sel.Drop {
+					verb = "drop"
+				}
+				return nil, p.errorf("expected how many dice to %s after %q", verb, op)
+			}
+			n, err := p.number(MaxCount)
+			if err != nil {
+				return nil, err
+			}
+			if n < 1 {
+				p.pos = start
+				return nil, p.errorf("%q needs at least one die", op)
+			}
+			sel.N = n
+			d.Select = sel
+		default:
+			return d, nil
+		}
+	}
+}
+
+// number разбирает целое число, не превосходящее max.
+func (p *parser) number(max int) (int, error) {
+	start := p.pos
+	n := 0
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		n = n*10 + int(p.src[p.pos]-'0')
+		p.pos++
+		if n > max {
+			p.pos = start
+			return 0, p.errorf("number is too large (at most %d)", max)
+		}
+	}
+	return n, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
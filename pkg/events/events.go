@@ -0,0 +1,121 @@
+// Package events хранит события броска для внешних программ: бросок начался,
+// фаза сменилась, выбран участник, цикл исчерпан, участники перезагружены.
+// Каждое событие получает возрастающий номер, поэтому переподключившийся
+// подписчик получает пропущенные события по номеру последнего полученного.
+// Нумерация начинается со времени запуска в микросекундах, поэтому номера
+// событий после перезапуска игры больше номеров прежнего запуска.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type — вид события.
+type Type string
+
+const (
+	SpinStarted      Type = "spin_started"      // Кость брошена
+	PhaseChanged     Type = "phase_changed"     // Фаза броска сменилась: From → Phase
+	WinnerDecided    Type = "winner_decided"    // Кость остановилась на участнике Label
+	CycleExhausted   Type = "cycle_exhausted"   // Все грани цикла Cycle выиграли
+	TexturesReloaded Type = "textures_reloaded" // Загружены Participants участников
+)
+
+// Event — событие в том виде, в котором оно отдается в JSON. Поля, не относящиеся
+// к виду события, пустые и в JSON не попадают.
+type Event struct {
+	Seq          uint64    `json:"seq"` // Номер события; растет и между запусками
+	Type         Type      `json:"type"`
+	Time         time.Time `json:"time"`
+	Cycle        int       `json:"cycle,omitempty"`
+	Phase        string    `json:"phase,omitempty"` // Новая фаза для phase_changed
+	From         string    `json:"from,omitempty"`  // Прежняя фаза для phase_changed
+	Label        string    `json:"label,omitempty"` // Выбранный участник для winner_decided
+	Participants int       `json:"participants,omitempty"`
+}
+
+// Параметры журнала.
+const (
+	DefaultKeep = 256 // Сколько последних событий хранится для переподключившихся
+	subscriberQ = 64  // Сколько событий может ждать подписчика, прежде чем он будет отключен
+)
+
+// Log — журнал последних событий с подписчиками. Методы можно вызывать из разных горутин.
+type Log struct {
+	mu          sync.Mutex
+	keep        int
+	recent      []Event
+	seq         uint64
+	subscribers map[chan Event]struct{}
+	now         func() time.Time
+}
+
+// NewLog создает журнал, хранящий keep последних событий.
+func NewLog(keep int) *Log {
+	return newLog(keep, time.Now)
+}
+
+// newLog создает журнал, нумерация которого продолжается с времени now() в
+// микросекундах: события приходят намного реже, поэтому номер следующего
+// запуска больше любого номера прежнего.
+func newLog(keep int, now func() time.Time) *Log {
+	return &Log{
+		keep:        max(keep, 1),
+		seq:         uint64(max(now().UnixMicro(), 0)),
+		subscribers: map[chan Event]struct{}{},
+		now:         now,
+	}
+}
+
+// Publish нумерует событие, отмечает время и рассылает его подписчикам.
+// Подписчик, который не успевает читать, отключается: его канал закрывается,
+// и он может переподключиться с номером последнего полученного события.
+func (l *Log) Publish(e Event) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	e.Seq = l.seq
+	e.Time = l.now()
+	l.recent = append(l.recent, e)
+	if len(l.recent) > l.keep {
+		l.recent = l.recent[len(l.recent)-l.keep:]
+	}
+	for ch := range l.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return e
+}
+
+// Subscribe возвращает хранящиеся события с номерами больше after и канал
+// следующих событий. cancel отписывает и закрывает канал. Если события после
+// after уже вытеснены, пропуск виден по номеру первого полученного события.
+// Номер больше последнего выданного пришел из другого запуска (например, часы
+// перевели назад), поэтому такой подписчик получает все хранящиеся события.
+func (l *Log) Subscribe(after uint64) (missed []Event, ch <-chan Event, cancel func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if after > l.seq {
+		after = 0
+	}
+	for _, e := range l.recent {
+		if e.Seq > after {
+			missed = append(missed, e)
+		}
+	}
+	c := make(chan Event, subscriberQ)
+	l.subscribers[c] = struct{}{}
+	return missed, c, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.subscribers[c]; ok {
+			delete(l.subscribers, c)
+			close(c)
+		}
+	}
+}
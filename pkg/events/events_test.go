@@ -0,0 +1,88 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_Replay(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	l := newLog(3, func() time.Time { return at })
+	start := uint64(at.UnixMicro())
+
+	for i := 0; i < 5; i++ {
+		e := l.Publish(Event{Type: SpinStarted, Cycle: 1})
+		assert.Equal(t, start+uint64(i+1), e.Seq)
+		assert.Equal(t, at, e.Time)
+	}
+
+	missed, _, cancel := l.Subscribe(start + 3)
+	defer cancel()
+	require.Len(t, missed, 2)
+	assert.Equal(t, []uint64{start + 4, start + 5}, []uint64{missed[0].Seq, missed[1].Seq})
+
+	missed, _, cancel2 := l.Subscribe(0)
+	defer cancel2()
+	assert.Equal(t, start+3, missed[0].Seq, "Only the last events are kept; the gap shows in the numbers")
+}
+
+func TestLog_Restart(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	before := newLog(DefaultKeep, func() time.Time { return at })
+	var last Event
+	for i := 0; i < 500; i++ {
+		last = before.Publish(Event{Type: SpinStarted})
+	}
+
+	// Игру перезапустили через секунду; дашборд переподключается с последним номером
+	l := newLog(DefaultKeep, func() time.Time { return at.Add(time.Second) })
+	e := l.Publish(Event{Type: TexturesReloaded, Participants: 3})
+	assert.Greater(t, e.Seq, last.Seq, "Numbers keep growing across restarts")
+	missed, _, cancel := l.Subscribe(last.Seq)
+	defer cancel()
+	assert.Equal(t, []Event{e}, missed)
+
+	// Номер из будущего (часы перевели назад) не прячет события
+	missed, _, cancel2 := l.Subscribe(e.Seq + 1000)
+	defer cancel2()
+	assert.Equal(t, []Event{e}, missed)
+}
+
+func TestLog_Subscribe(t *testing.T) {
+	l := NewLog(DefaultKeep)
+	start := l.seq
+	missed, ch, cancel := l.Subscribe(0)
+	assert.Empty(t, missed)
+
+	l.Publish(Event{Type: WinnerDecided, Label: "Anna", Cycle: 2})
+	e := <-ch
+	assert.Equal(t, Event{Seq: start + 1, Type: WinnerDecided, Time: e.Time, Label: "Anna", Cycle: 2}, e)
+
+	cancel()
+	_, open := <-ch
+	assert.False(t, open)
+	cancel() // Повторная отписка ничего не ломает
+	l.Publish(Event{Type: SpinStarted})
+}
+
+func TestLog_SlowSubscriber(t *testing.T) {
+	l := NewLog(DefaultKeep)
+	start := l.seq
+	_, ch, cancel := l.Subscribe(0)
+	defer cancel()
+	for i := 0; i < subscriberQ+1; i++ {
+		l.Publish(Event{Type: SpinStarted})
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, subscriberQ, n, "A subscriber that falls behind is disconnected")
+	missed, _, cancel2 := l.Subscribe(start + uint64(n))
+	defer cancel2()
+	assert.Len(t, missed, 1, "and gets the rest after reconnecting")
+}
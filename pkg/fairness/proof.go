@@ -0,0 +1,117 @@
+// Package fairness реализует схему "обязательство — раскрытие" для доказательства
+// честности розыгрыша: до начала цикла публикуется хеш зерна и исходного состояния,
+// а после цикла раскрывается само зерно, и любой может повторить все броски.
+package fairness
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// FaceState описывает грань кости в момент начала цикла.
+type FaceState struct {
+	Label  string `json:"label,omitempty"` // Имя текстуры на грани
+	Grey   bool   `json:"grey,omitempty"`  // Грань не участвует в розыгрыше
+	Winner bool   `json:"winner,omitempty"`
+}
+
+// Draw — результат одного броска.
+type Draw struct {
+	Face  int    `json:"face"`  // Индекс выигравшей грани (WinningFaceIndex)
+	Label string `json:"label"` // Имя текстуры на выигравшей грани
+	// Положение кости в момент броска. От него зависит только физический бросок,
+	// но оно записывается всегда, чтобы повтор не зависел от режима.
+	Orientation [4]float64 `json:"orientation"`
+	Position    [3]float64 `json:"position"`
+}
+
+// Proof — доказательство честности одного цикла розыгрыша.
+// До раскрытия поле Seed пусто, опубликовать можно только Commitment.
+type Proof struct {
+	Commitment string      `json:"commitment"`
+	Seed       *uint64     `json:"seed,omitempty"`
+	Sides      int         `json:"sides"`
+	Physics    bool        `json:"physics"`
+	Faces      []FaceState `json:"faces"`
+	Pool       []string    `json:"pool"` // Порядок AvailableTextures после перемешивания (последняя выдается первой)
+	Draws      []Draw      `json:"draws"`
+
+	secret uint64 // Зерно до раскрытия
+}
+
+// NewProof создает доказательство для цикла, начинающегося с заданного состояния,
+// и вычисляет обязательство. Зерно хранится скрыто до вызова Reveal.
+func NewProof(seed uint64, sides int, physics bool, faces []FaceState, pool []string) *Proof {
+	p := &Proof{
+		Sides:   sides,
+		Physics: physics,
+		Faces:   faces,
+		Pool:    pool,
+		Draws:   []Draw{},
+	}
+	p.Commitment = Commit(seed, p)
+	p.secret = seed
+	return p
+}
+
+// Commit вычисляет обязательство: SHA-256 от зерна и исходного состояния цикла.
+// Броски в обязательство не входят — они следуют из зерна и состояния.
+func Commit(seed uint64, p *Proof) string {
+	state, _ := json.Marshal(struct {
+		Sides   int         `json:"sides"`
+		Physics bool        `json:"physics"`
+		Faces   []FaceState `json:"faces"`
+		Pool    []string    `json:"pool"`
+	}{p.Sides, p.Physics, p.Faces, p.Pool})
+
+	h := sha256.New()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seed)
+	h.Write(b[:])
+	h.Write(state)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Reveal раскрывает зерно после завершения цикла.
+func (p *Proof) Reveal() {
+	seed := p.secret
+	p.Seed = &seed
+}
+
+// CheckCommitment проверяет, что раскрытое зерно и состояние соответствуют обязательству.
+func (p *Proof) CheckCommitment() error {
+	if p.Seed == nil {
+		return errors.New("seed is not revealed yet")
+	}
+	if got := Commit(*p.Seed, p); got != p.Commitment {
+		return fmt.Errorf("commitment mismatch: published %s, recomputed %s", p.Commitment, got)
+	}
+	return nil
+}
+
+// Save записывает доказательство в файл в формате JSON.
+func (p *Proof) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load читает доказательство из файла.
+func Load(path string) (*Proof, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Proof
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse proof %s: %w", path, err)
+	}
+	return &p, nil
+}
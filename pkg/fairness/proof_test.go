@@ -0,0 +1,55 @@
+package fairness
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProof(seed uint64) *Proof {
+	faces := []FaceState{{Label: "alice"}, {Label: "bob"}, {Grey: true}}
+	return NewProof(seed, 6, false, faces, []string{"carol", "dave"})
+}
+
+func TestNewProof_HidesSeed(t *testing.T) {
+	p := newTestProof(42)
+
+	assert.Nil(t, p.Seed, "Seed should stay hidden until revealed")
+	assert.Len(t, p.Commitment, 64, "Commitment should be a hex SHA-256")
+	assert.Error(t, p.CheckCommitment(), "Commitment cannot be checked before reveal")
+
+	p.Reveal()
+	require.NotNil(t, p.Seed)
+	assert.Equal(t, uint64(42), *p.Seed)
+	assert.NoError(t, p.CheckCommitment())
+}
+
+func TestCommit_BindsSeedAndState(t *testing.T) {
+	base := newTestProof(42)
+
+	assert.NotEqual(t, base.Commitment, newTestProof(43).Commitment, "Different seeds should give different commitments")
+
+	reordered := NewProof(42, 6, false, base.Faces, []string{"dave", "carol"})
+	assert.NotEqual(t, base.Commitment, reordered.Commitment, "Pool order should be part of the commitment")
+
+	base.Reveal()
+	base.Pool = []string{"dave", "carol"}
+	assert.Error(t, base.CheckCommitment(), "Tampering with the pool should be detected")
+}
+
+func TestSaveLoad(t *testing.T) {
+	p := newTestProof(7)
+	p.Draws = append(p.Draws, Draw{Face: 1, Label: "bob", Orientation: [4]float64{1, 0, 0, 0}})
+	p.Reveal()
+
+	path := filepath.Join(t.TempDir(), "proof.json")
+	require.NoError(t, p.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, p.Commitment, loaded.Commitment)
+	assert.Equal(t, p.Draws, loaded.Draws)
+	assert.NoError(t, loaded.CheckCommitment())
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Размеры окна справки в пикселях экрана.
+const (
+	helpRowHeight = 16
+	helpCharWidth = 6 // Ширина символа отладочного шрифта
+	helpPadding   = 10
+)
+
+// pressed сообщает, что действие a вызвано в этом тике клавишей или кнопкой
+// любого подключенного геймпада. Клавиши и кнопки настроек нумеруются так же,
+// как в Ebitengine.
+func (g *Game) pressed(a config.Action) bool {
+	if inpututil.IsKeyJustPressed(ebiten.Key(g.Config.Key(a))) {
+		return true
+	}
+	button := g.Config.Button(a)
+	if button == config.NoButton {
+		return false
+	}
+	for _, id := range g.gamepads {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButton(button)) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateGlobal обрабатывает действия, доступные в любом режиме: справку, полноэкранный
+// режим, снимок экрана, запись броска и выход. Возвращает ebiten.Termination, если пора выйти.
+func (g *Game) updateGlobal() error {
+	g.gamepads = ebiten.AppendGamepadIDs(g.gamepads[:0])
+	if g.dice.typing {
+		// Пока набирается выражение, буквы — это текст, а не команды
+		return nil
+	}
+
+	if g.pressed(config.ActionQuit) {
+		log.Println("Quit requested.")
+		return ebiten.Termination
+	}
+	if g.pressed(config.ActionHelp) {
+		g.helpVisible = !g.helpVisible
+	}
+	if g.pressed(config.ActionFullscreen) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+	if g.pressed(config.ActionScreenshot) {
+		g.screenshotPending = true // Снимок делается в Draw, когда кадр готов
+	}
+	if g.pressed(config.ActionRecord) {
+		g.toggleRecording()
+	}
+	return nil
+}
+
+// helpLines возвращает строки справки, собранные из текущих привязок клавиш и кнопок.
+func helpLines(c config.Config) []string {
+	lines := []string{"Controls (key / gamepad button):", ""}
+	for _, a := range config.Actions() {
+		binding := c.Key(a).String()
+		if b := c.Button(a); b != config.NoButton {
+			binding += " / " + b.String()
+		}
+		lines = append(lines, fmt.Sprintf("%-20s %s", binding, a.Help()))
+	}
+	return append(lines, fmt.Sprintf("%-20s %s", "Mouse / touch", "click or fling the die to roll it"))
+}
+
+// drawHelp рисует справку по управлению в центре экрана.
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	lines := helpLines(g.Config)
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line)*helpCharWidth)
+	}
+	height := len(lines) * helpRowHeight
+	left := (screen.Bounds().Dx() - width) / 2
+	top := (screen.Bounds().Dy() - height) / 2
+	vector.DrawFilledRect(screen, float32(left-helpPadding), float32(top-helpPadding),
+		float32(width+2*helpPadding), float32(height+2*helpPadding), color.RGBA{A: 0xE0}, false)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, left, top+i*helpRowHeight)
+	}
+}
+
+// saveScreenshot сохраняет кадр в PNG-файл в каталоге ScreenshotDir.
+// Файл пишется в фоне, чтобы не задерживать следующий кадр.
+func (g *Game) saveScreenshot(screen *ebiten.Image) {
+	b := screen.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	screen.ReadPixels(img.Pix)
+	path := filepath.Join(g.ScreenshotDir, time.Now().Format("dice_roller-20060102-150405.000.png"))
+
+	go func() {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Printf("Could not save screenshot: %v", err)
+			return
+		}
+		defer f.Close()
+		if err := png.Encode(f, img); err != nil {
+			log.Printf("Could not save screenshot %s: %v", path, err)
+			return
+		}
+		log.Printf("Screenshot saved to %s", path)
+	}()
+}
@@ -0,0 +1,61 @@
+//go:build !ci
+
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelpLines(t *testing.T) {
+	c := config.Default()
+	c.Keys.Spin = config.KeySpace
+	c.Gamepad.Quit = config.ButtonCenterLeft
+
+	text := strings.Join(helpLines(c), "\n")
+	for _, a := range config.Actions() {
+		assert.Contains(t, text, a.Help(), "Every action should be described")
+	}
+	assert.Contains(t, text, "Space / RightBottom", "Help should follow the actual bindings")
+	assert.Contains(t, text, "Q / CenterLeft")
+	assert.NotContains(t, text, "None", "Unbound buttons are not shown")
+}
+
+func TestBindings_MatchEbiten(t *testing.T) {
+	for k := config.Key(0); k < config.KeyCount; k++ {
+		assert.Equal(t, ebiten.Key(k).String(), k.String(), "Key %d", int(k))
+	}
+	assert.Equal(t, ebiten.KeyMax, ebiten.Key(config.KeyCount-1), "Every Ebitengine key should have a name")
+	assert.Equal(t, ebiten.StandardGamepadButtonMax, ebiten.StandardGamepadButton(config.ButtonCenterCenter))
+}
+
+func TestResetCycle(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	sm := g.StateManager
+	pool := len(g.AssetManager.AvailableTextures)
+	for i := 0; i < 4; i++ {
+		spin(t, g)
+	}
+	require.Contains(t, sm.IsWinner, true)
+	cycle := sm.Cycle
+
+	sm.Rotating = true
+	g.ResetCycle()
+	assert.Equal(t, cycle, sm.Cycle, "The cycle is not reset while the die is rolling")
+	sm.Rotating = false
+
+	g.ResetCycle()
+	assert.Equal(t, cycle+1, sm.Cycle)
+	assert.Equal(t, -1, sm.LastWinnerIndex)
+	for i := range sm.IsWinner {
+		assert.False(t, sm.IsWinner[i], "Nobody has won in the new cycle")
+		assert.False(t, sm.IsGrey[i])
+	}
+	assert.Len(t, g.AssetManager.AvailableTextures, pool, "Everyone who is not on the die is back in the pool")
+}
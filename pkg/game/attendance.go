@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Размеры панели отметки присутствующих в пикселях экрана.
+const (
+	attendanceX         = 10
+	attendanceY         = 30
+	attendanceWidth     = 280
+	attendanceRowHeight = 16
+	attendanceRows      = 38 // Сколько участников помещается на панели без прокрутки
+)
+
+// attendancePanel — оверлей со списком участников, в котором можно отметить отсутствующих.
+type attendancePanel struct {
+	Visible bool
+	cursor  int
+	scroll  int
+}
+
+// move перемещает курсор на delta строк, прокручивая список, чтобы курсор оставался видимым.
+func (p *attendancePanel) move(delta, count int) {
+	if count == 0 {
+		p.cursor, p.scroll = 0, 0
+		return
+	}
+	p.cursor = min(max(p.cursor+delta, 0), count-1)
+	if p.cursor < p.scroll {
+		p.scroll = p.cursor
+	}
+	if p.cursor >= p.scroll+attendanceRows {
+		p.scroll = p.cursor - attendanceRows + 1
+	}
+}
+
+// clamp возвращает курсор и прокрутку в пределы списка из count участников:
+// после перезагрузки участников список может стать короче.
+func (p *attendancePanel) clamp(count int) {
+	p.scroll = min(p.scroll, max(count-attendanceRows, 0))
+	p.move(0, count)
+}
+
+// rowAt возвращает индекс участника под точкой экрана (x, y) или -1.
+func (p *attendancePanel) rowAt(x, y, count int) int {
+	top := attendanceY + attendanceRowHeight // Первая строка — заголовок
+	if x < attendanceX || x >= attendanceX+attendanceWidth || y < top {
+		return -1
+	}
+	row := (y-top)/attendanceRowHeight + p.scroll
+	if row >= count || row >= p.scroll+attendanceRows {
+		return -1
+	}
+	return row
+}
+
+// people возвращает имена всех загруженных участников в порядке загрузки.
+func (g *Game) people() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, tex := range g.AssetManager.AllTextures {
+		label := g.AssetManager.LabelOf(tex)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		names = append(names, label)
+	}
+	return names
+}
+
+// updateAttendance обрабатывает клавиатуру и мышь для панели присутствующих:
+// клавиша Keys.Attendance открывает и закрывает панель, стрелки двигают курсор, пробел, Enter или
+// щелчок мыши меняют отметку.
+func (g *Game) updateAttendance() {
+	if g.pressed(config.ActionAttendance) {
+		g.attendance.Visible = !g.attendance.Visible
+	}
+	if !g.attendance.Visible {
+		return
+	}
+
+	people := g.people()
+	g.attendance.clamp(len(people))
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.attendance.move(-1, len(people))
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.attendance.move(1, len(people))
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace), inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if g.attendance.cursor < len(people) {
+			g.toggleAttendance(people[g.attendance.cursor])
+		}
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		x, y := ebiten.CursorPosition()
+		if row := g.attendance.rowAt(x, y, len(people)); row >= 0 {
+			g.attendance.cursor = row
+			g.toggleAttendance(people[row])
+		}
+	}
+}
+
+// toggleAttendance меняет отметку участника на противоположную.
+func (g *Game) toggleAttendance(label string) {
+	g.setAbsent(label, !g.AssetManager.IsAbsent(label))
+}
+
+// setAbsent отмечает участника и сразу обновляет грани кости: грань отсутствующего,
+// еще не выигравшая в этом цикле, получает следующую текстуру из пула, а
+// освободившиеся серые грани заполняются вернувшимися участниками.
+// Во время броска отметки не меняются, чтобы не подменить выигравшую грань.
+func (g *Game) setAbsent(label string, absent bool) {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		log.Println("Attendance cannot change while the die is rolling.")
+		return
+	}
+
+	am := g.AssetManager
+	am.SetAbsent(label, absent)
+	g.forgetUndo()
+	faces := g.Cube.Faces
+	for i := range faces {
+		switch {
+		case absent && !sm.IsGrey[i] && !sm.IsWinner[i] && am.LabelOf(faces[i].Texture) == label:
+			am.Bench(faces[i].Texture)
+			am.ReplaceFaceTexture(i, faces, sm.IsGrey)
+		case !absent && sm.IsGrey[i] && len(am.AvailableTextures) > 0:
+			am.ReplaceFaceTexture(i, faces, sm.IsGrey)
+			sm.IsWinner[i] = false
+		default:
+			continue
+		}
+		if sm.IsGrey[i] {
+			faces[i].Texture = config.GreyImage
+		}
+	}
+
+	if g.fairness != nil {
+		// Состав пула изменился, поэтому текущий цикл закрывается досрочно.
+		g.revealCycle()
+		g.commitCycle()
+	}
+}
+
+// attendanceTitle возвращает заголовок панели присутствующих с подсказкой по управлению.
+func (g *Game) attendanceTitle() string {
+	return fmt.Sprintf("Attendance: arrows, Space or click; %s to close", g.Config.Keys.Attendance)
+}
+
+// drawAttendance рисует панель присутствующих поверх кости.
+func (g *Game) drawAttendance(screen *ebiten.Image) {
+	people := g.people()
+	rows := min(len(people)-g.attendance.scroll, attendanceRows)
+	height := float32((rows + 1) * attendanceRowHeight)
+	vector.DrawFilledRect(screen, attendanceX-4, attendanceY-4, attendanceWidth+8, height+8, color.RGBA{A: 0xC0}, false)
+
+	ebitenutil.DebugPrintAt(screen, g.attendanceTitle(), attendanceX, attendanceY)
+	for row := 0; row < rows; row++ {
+		i := g.attendance.scroll + row
+		cursor, mark := " ", "x"
+		if i == g.attendance.cursor {
+			cursor = ">"
+		}
+		if g.AssetManager.IsAbsent(people[i]) {
+			mark = " "
+		}
+		line := fmt.Sprintf("%s [%s] %s", cursor, mark, people[i])
+		ebitenutil.DebugPrintAt(screen, line, attendanceX, attendanceY+(row+1)*attendanceRowHeight)
+	}
+}
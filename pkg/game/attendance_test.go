@@ -0,0 +1,107 @@
+//go:build !ci
+
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAttendanceGame создает игру на кубе с count участниками.
+func newAttendanceGame(t *testing.T, count int) *Game {
+	am := assets.NewManager(random.NewPCG(1))
+	am.AttendancePath = filepath.Join(t.TempDir(), "attendance.json")
+	for i := 0; i < count; i++ {
+		am.AddTexture(ebiten.NewImage(1, 1), fmt.Sprintf("person%d", i))
+	}
+	am.AvailableTextures = append(am.AvailableTextures, am.AllTextures...)
+	return NewGame(am, cube.NewCube(), random.NewPCG(1), config.Default())
+}
+
+func TestSetAbsent_ReplacesFace(t *testing.T) {
+	g := newAttendanceGame(t, 7)
+	label := g.AssetManager.LabelOf(g.Cube.Faces[2].Texture)
+
+	g.setAbsent(label, true)
+
+	assert.NotEqual(t, label, g.AssetManager.LabelOf(g.Cube.Faces[2].Texture), "Absent person should leave the die")
+	assert.False(t, g.StateManager.IsGrey[2], "The spare person should take the face")
+
+	// Запасных больше нет: грань следующего отсутствующего становится серой
+	other := g.AssetManager.LabelOf(g.Cube.Faces[4].Texture)
+	g.setAbsent(other, true)
+	assert.True(t, g.StateManager.IsGrey[4])
+	assert.Equal(t, config.GreyImage, g.Cube.Faces[4].Texture)
+
+	// Вернувшийся участник занимает серую грань
+	g.setAbsent(other, false)
+	assert.False(t, g.StateManager.IsGrey[4])
+	assert.Equal(t, other, g.AssetManager.LabelOf(g.Cube.Faces[4].Texture))
+}
+
+func TestSetAbsent_KeepsWinners(t *testing.T) {
+	g := newAttendanceGame(t, 7)
+	g.StateManager.IsWinner[1] = true
+	winner := g.Cube.Faces[1].Texture
+
+	g.setAbsent(g.AssetManager.LabelOf(winner), true)
+	assert.Equal(t, winner, g.Cube.Faces[1].Texture, "A face that already won this cycle should stay")
+}
+
+func TestSetAbsent_IgnoredWhileRolling(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	label := g.AssetManager.LabelOf(g.Cube.Faces[0].Texture)
+	g.StateManager.Rotating = true
+
+	g.setAbsent(label, true)
+	assert.False(t, g.AssetManager.IsAbsent(label))
+	assert.Equal(t, label, g.AssetManager.LabelOf(g.Cube.Faces[0].Texture))
+}
+
+func TestAttendancePanel_Navigation(t *testing.T) {
+	p := &attendancePanel{}
+
+	p.move(-1, 50)
+	assert.Equal(t, 0, p.cursor, "Cursor should not go above the first row")
+	p.move(45, 50)
+	assert.Equal(t, 45, p.cursor)
+	assert.Equal(t, 45-attendanceRows+1, p.scroll, "List should scroll to keep the cursor visible")
+	p.move(100, 50)
+	assert.Equal(t, 49, p.cursor)
+
+	p.move(-100, 50)
+	assert.Equal(t, 0, p.scroll)
+	assert.Equal(t, 0, p.rowAt(attendanceX+5, attendanceY+attendanceRowHeight+1, 50))
+	assert.Equal(t, 2, p.rowAt(attendanceX+5, attendanceY+3*attendanceRowHeight+1, 50))
+	assert.Equal(t, -1, p.rowAt(attendanceX+5, attendanceY+1, 50), "Header is not a row")
+	assert.Equal(t, -1, p.rowAt(attendanceX+attendanceWidth+1, attendanceY+attendanceRowHeight+1, 50))
+	assert.Equal(t, -1, p.rowAt(attendanceX+5, attendanceY+5*attendanceRowHeight+1, 3), "Below the last person")
+
+	// Список сократился после перезагрузки, пока он был прокручен
+	p.move(49, 50)
+	p.clamp(5)
+	assert.Equal(t, 0, p.scroll, "A short list is shown from the top")
+	assert.Equal(t, 4, p.cursor)
+	p.move(49, 50)
+	p.clamp(45)
+	assert.Equal(t, 45-attendanceRows, p.scroll, "The list scrolls back so that the last page is full")
+	assert.Equal(t, 44, p.cursor)
+	p.clamp(0)
+	assert.Equal(t, attendancePanel{}, *p)
+}
+
+func TestAttendanceTitle(t *testing.T) {
+	g := newAttendanceGame(t, 1)
+	assert.Contains(t, g.attendanceTitle(), "A to close")
+
+	g.Config.Keys.Attendance = config.KeyM
+	assert.Contains(t, g.attendanceTitle(), "M to close", "The hint follows the rebound key")
+}
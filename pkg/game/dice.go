@@ -0,0 +1,328 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/olegshirko/dice_roller/internal/utils"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/dice"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Размеры панели броска выражения в пикселях экрана.
+const (
+	diceRowHeight   = 16
+	diceCharWidth   = 6  // Ширина символа отладочного шрифта
+	diceMaxInput    = 64 // Длина вводимого выражения
+	diceTextureSize = 128
+)
+
+// diceFace — одна кость броска на экране: модель, подписи граней
+// и грань с выпавшим значением.
+type diceFace struct {
+	sides  int
+	labels []string
+	face   int
+}
+
+// diceRoll — режим бросков по выражению: вместо участников на экране кости
+// с числами, которые останавливаются на выпавших значениях.
+type diceRoll struct {
+	typing bool   // Открыта строка ввода выражения
+	input  string // Набираемое выражение
+	err    error  // Ошибка разбора введенного выражения
+	chars  []rune // Буфер для ebiten.AppendInputChars
+
+	expr    *dice.Expr
+	result  dice.Result
+	faces   []diceFace // Кости броска, у которых есть модель
+	scene   scene      // Кости на экране; пусто, если ни у одной кости броска нет модели
+	settled bool       // Все кости остановились, итог показан
+
+	textures map[string][]*ebiten.Image // Текстуры граней по модели и подписям
+}
+
+// active сообщает, что режим бросков по выражению включен.
+func (d *diceRoll) active() bool {
+	return d.typing || d.expr != nil
+}
+
+// rolling сообщает, что кости еще в движении.
+func (d *diceRoll) rolling() bool {
+	return d.scene.rolling()
+}
+
+// exit выключает режим бросков, сохраняя готовые текстуры граней.
+func (d *diceRoll) exit() {
+	*d = diceRoll{textures: d.textures}
+}
+
+// numberLabels возвращает подписи граней от 1 до sides.
+func numberLabels(sides int) []string {
+	labels := make([]string, sides)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+	return labels
+}
+
+// diceFaces раскладывает результат броска по костям на экране. Процентная кость
+// (d% и d100) показывается парой d10: десятки ("00"–"90") и единицы ("0"–"9"),
+// где "00" и "0" вместе означают 100.
+func diceFaces(res dice.Result) []diceFace {
+	var faces []diceFace
+	for _, g := range res.Groups {
+		for _, die := range g.Dice {
+			if die.Sides != 100 {
+				faces = append(faces, diceFace{sides: die.Sides, labels: numberLabels(die.Sides), face: die.Value - 1})
+				continue
+			}
+			tens := make([]string, 10)
+			units := make([]string, 10)
+			for i := range tens {
+				tens[i] = fmt.Sprintf("%d0", i)
+				units[i] = strconv.Itoa(i)
+			}
+			tens[0] = "00"
+			faces = append(faces,
+				diceFace{sides: 10, labels: tens, face: die.Value % 100 / 10},
+				diceFace{sides: 10, labels: units, face: die.Value % 10})
+		}
+	}
+	return faces
+}
+
+// RollDice разбирает выражение src ("4d6kh3", "2d20kl1+5", "3d6!", "d%"), бросает кости
+// и показывает результат на экране. Ошибка разбора возвращается, состояние не меняется.
+func (g *Game) RollDice(src string) error {
+	e, err := dice.Parse(src)
+	if err != nil {
+		return err
+	}
+	if g.dice.rolling() {
+		return errors.New("the dice are still rolling")
+	}
+	g.rollExpr(e)
+	return nil
+}
+
+// rollExpr бросает кости выражения e и запускает анимацию всех костей,
+// у которых есть модель, каждой — к своему выпавшему значению.
+func (g *Game) rollExpr(e *dice.Expr) {
+	d := &g.dice
+	d.expr = e
+	d.result = e.Roll(g.Random)
+	d.settled = false
+	log.Printf("Rolling %s.", e)
+
+	d.faces = d.faces[:0]
+	for _, f := range diceFaces(d.result) {
+		if _, err := cube.NewPolyhedron(f.sides); err == nil {
+			d.faces = append(d.faces, f)
+		}
+	}
+	g.layoutDice(d.faces[:min(len(d.faces), sceneMaxDice)])
+	if len(d.scene.dice) == 0 {
+		// Костей с моделью нет (например, d3 или одни числа): показываем только итог.
+		d.settled = true
+		log.Printf("Rolled %s", d.result)
+		return
+	}
+	for i, sd := range d.scene.dice {
+		textures := g.diceTextures(sd.Cube, d.faces[i].labels)
+		for j := range sd.Cube.Faces {
+			sd.Cube.Faces[j].Texture = textures[j]
+		}
+		sd.settled = false
+		sd.State.RollTo(d.faces[i].face)
+	}
+}
+
+// layoutDice расставляет на экране кости faces. Кости прошлого броска с тем же
+// числом граней остаются на экране в прежнем положении, недостающие создаются.
+func (g *Game) layoutDice(faces []diceFace) {
+	s := &g.dice.scene
+	s.scale = g.Config.Cube.Scale()
+	width, height := float64(g.Config.Window.Width), float64(g.Config.Window.Height)
+	homes, size := layoutScene(len(faces), width, height, s.scale)
+	s.size = size
+
+	old := s.dice
+	s.dice = make([]*sceneDie, len(faces))
+	for i, f := range faces {
+		if i < len(old) && len(old[i].Cube.Faces) == f.sides {
+			s.dice[i] = old[i]
+		} else {
+			model, _ := cube.NewPolyhedron(f.sides) // Модель точно есть: faces уже отобраны
+			sm := roll.NewStateManager(model, g.AssetManager, g.Random, g.Config.Animation)
+			sm.Mode = g.StateManager.Mode
+			sm.Orientation = g.StateManager.Orientation
+			if sm.Mode == roll.ModePhysics {
+				sm.Position = homes[i]
+			}
+			s.dice[i] = &sceneDie{Cube: model, State: sm}
+		}
+		sd := s.dice[i]
+		sd.home = homes[i]
+		// Стол физического режима совпадает с видимой частью экрана
+		sd.State.Table.HalfWidth = (width/2 - sceneMarginX) / (s.scale * size)
+		sd.State.Table.HalfDepth = (height/2 - sceneMarginY) / (s.scale * size)
+	}
+}
+
+// diceTextures возвращает текстуры граней модели die с подписями labels.
+// Подпись центрируется в центре тяжести грани, а не в середине текстуры.
+// На костях с девятью и более гранями 6 и 9 подчеркиваются: их легко перепутать.
+func (g *Game) diceTextures(die *cube.Polyhedron, labels []string) []*ebiten.Image {
+	key := fmt.Sprint(len(die.Faces), labels)
+	if textures, ok := g.dice.textures[key]; ok {
+		return textures
+	}
+
+	textures := make([]*ebiten.Image, len(die.Faces))
+	for i, face := range die.Faces {
+		var cx, cy float64
+		for _, uv := range face.UVs {
+			cx += float64(uv[0])
+			cy += float64(uv[1])
+		}
+		cx /= float64(len(face.UVs))
+		cy /= float64(len(face.UVs))
+		underline := len(die.Faces) >= 9 && (labels[i] == "6" || labels[i] == "9")
+		textures[i] = ebiten.NewImageFromImage(utils.FaceLabel(labels[i], diceTextureSize, cx, cy, underline))
+	}
+	if g.dice.textures == nil {
+		g.dice.textures = map[string][]*ebiten.Image{}
+	}
+	g.dice.textures[key] = textures
+	return textures
+}
+
+// updateDice обрабатывает режим бросков по выражению. R открывает строку ввода,
+// Enter бросает, S повторяет бросок, Esc возвращает к участникам.
+// Возвращает true, если режим включен и остальной ввод обрабатывать не нужно.
+func (g *Game) updateDice() bool {
+	d := &g.dice
+	if d.typing {
+		g.updateDiceInput()
+		g.advanceDice()
+		return true
+	}
+	if !d.active() {
+		if g.pressed(config.ActionDice) {
+			d.typing, d.input, d.err = true, "", nil
+		}
+		return d.typing
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		d.exit()
+		return false
+	case g.pressed(config.ActionDice):
+		d.typing, d.input, d.err = true, d.expr.String(), nil
+	case g.pressed(config.ActionSpin) && !d.rolling():
+		g.rollExpr(d.expr)
+	}
+	g.advanceDice()
+	return true
+}
+
+// advanceDice продвигает анимацию костей на один тик и сообщает итог, когда все остановились.
+func (g *Game) advanceDice() {
+	d := &g.dice
+	if d.scene.update() {
+		d.settled = true
+		log.Printf("Rolled %s", d.result)
+	}
+}
+
+// updateDiceInput обрабатывает набор выражения.
+func (g *Game) updateDiceInput() {
+	d := &g.dice
+	d.chars = ebiten.AppendInputChars(d.chars[:0])
+	for _, r := range d.chars {
+		if unicode.IsPrint(r) && len(d.input) < diceMaxInput {
+			d.input += string(r)
+		}
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && d.input != "":
+		r := []rune(d.input)
+		d.input = string(r[:len(r)-1])
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if d.rolling() {
+			return
+		}
+		if d.err = g.RollDice(d.input); d.err == nil {
+			d.typing = false
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		// Без выражения режим выключается, иначе остается предыдущий бросок
+		d.typing, d.err = false, nil
+	}
+}
+
+// drawDice рисует строку ввода или результат броска внизу экрана.
+func (g *Game) drawDice(screen *ebiten.Image) {
+	d := &g.dice
+	width := screen.Bounds().Dx()
+	var lines []string
+	switch {
+	case d.typing:
+		lines = append(lines, "Dice: "+d.input+"_")
+		if d.err != nil {
+			lines = append(lines, d.err.Error())
+		}
+		lines = append(lines, "Enter to roll, Esc to cancel (e.g. 4d6kh3, 2d20kl1+5, 3d6!, d%)")
+	case !d.settled:
+		lines = append(lines, fmt.Sprintf("Rolling %s...", d.expr))
+	default:
+		lines = append(lines, wrapText(d.result.String(), width/diceCharWidth-4)...)
+		total := fmt.Sprintf("Total: %d", d.result.Total)
+		if shown := len(d.scene.dice); shown < len(d.faces) {
+			total += fmt.Sprintf(" (%d of %d dice shown)", shown, len(d.faces))
+		}
+		lines = append(lines, total)
+		lines = append(lines, fmt.Sprintf("%s to roll again, %s for a new expression, Esc to go back",
+			g.Config.Keys.Spin, g.Config.Keys.Dice))
+	}
+
+	height := len(lines) * diceRowHeight
+	top := screen.Bounds().Dy() - height - 10
+	vector.DrawFilledRect(screen, 0, float32(top-4), float32(width), float32(height+8), color.RGBA{A: 0xC0}, false)
+	for i, line := range lines {
+		x := (width - len(line)*diceCharWidth) / 2
+		ebitenutil.DebugPrintAt(screen, line, max(x, 4), top+i*diceRowHeight)
+	}
+}
+
+// wrapText разбивает текст на строки не длиннее limit символов по пробелам.
+func wrapText(text string, limit int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > limit {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
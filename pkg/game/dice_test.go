@@ -0,0 +1,116 @@
+//go:build !ci
+
+package game
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/dice"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiceFaces(t *testing.T) {
+	e, err := dice.Parse("d6+d%")
+	require.NoError(t, err)
+	res := e.Roll(random.NewScripted([]int{3, 46}, nil))
+
+	faces := diceFaces(res)
+	require.Len(t, faces, 3, "A percentile die is shown as two d10")
+	assert.Equal(t, 6, faces[0].sides)
+	assert.Equal(t, "4", faces[0].labels[faces[0].face])
+	assert.Equal(t, "40", faces[1].labels[faces[1].face], "Tens of 47")
+	assert.Equal(t, "7", faces[2].labels[faces[2].face], "Units of 47")
+
+	res = e.Roll(random.NewScripted([]int{0, 99}, nil))
+	faces = diceFaces(res)
+	assert.Equal(t, "00", faces[1].labels[faces[1].face], "100 is shown as 00 and 0")
+	assert.Equal(t, "0", faces[2].labels[faces[2].face])
+}
+
+func TestRollDice(t *testing.T) {
+	for _, mode := range []roll.Mode{roll.ModePredetermined, roll.ModePhysics} {
+		g := newAttendanceGame(t, 6)
+		g.Random = random.NewPCG(5)
+		g.StateManager.Mode = mode
+
+		require.NoError(t, g.RollDice("3d8+2"))
+		require.True(t, g.dice.active())
+		require.Len(t, g.dice.scene.dice, 3, "Every die of the roll is on the screen")
+		for _, sd := range g.dice.scene.dice {
+			assert.Len(t, sd.Cube.Faces, 8, "The screen should show d8s")
+		}
+		assert.Error(t, g.RollDice("d6"), "A new roll waits for the current one")
+
+		for tick := 0; !g.dice.settled; tick++ {
+			require.Less(t, tick, maxTestTicks, "the dice should settle")
+			require.NoError(t, g.Update())
+			assertNoOverlap(t, &g.dice.scene)
+		}
+		for i, sd := range g.dice.scene.dice {
+			value := g.dice.result.Groups[0].Dice[i].Value
+			face := sd.State.LastWinnerIndex
+			assert.Equal(t, strconv.Itoa(value), g.dice.faces[i].labels[face], "Die %d should land on the rolled value", i)
+			assert.Equal(t, g.dice.textures["8 [1 2 3 4 5 6 7 8]"][face], sd.Cube.Faces[face].Texture)
+		}
+
+		g.dice.exit()
+		assert.False(t, g.dice.active())
+		assert.NotEmpty(t, g.dice.textures, "Face textures are kept for the next roll")
+	}
+}
+
+// assertNoOverlap проверяет, что описанные сферы костей не пересекаются. В физическом
+// режиме кости на разной высоте могут перекрываться на экране, но не в пространстве.
+func assertNoOverlap(t *testing.T, s *scene) {
+	t.Helper()
+	radius := config.CubeSize / 2 * math.Sqrt(3) * s.scale * s.size
+	for i, a := range s.dice {
+		for _, b := range s.dice[i+1:] {
+			d := s.offset(a).Sub(s.offset(b))
+			require.GreaterOrEqual(t, d.Length(), 2*radius-1e-6, "Dice should not overlap")
+		}
+	}
+}
+
+func TestLayoutScene(t *testing.T) {
+	cfg := config.Default()
+	width, height, scale := float64(cfg.Window.Width), float64(cfg.Window.Height), cfg.Cube.Scale()
+	radius := config.CubeSize / 2 * math.Sqrt(3)
+
+	_, size := layoutScene(1, width, height, scale)
+	assert.Equal(t, 1.0, size, "A single die keeps its size")
+
+	for n := 1; n <= sceneMaxDice; n++ {
+		homes, size := layoutScene(n, width, height, scale)
+		require.Len(t, homes, n)
+		assert.LessOrEqual(t, size, 1.0)
+		px := scale * size
+		for i, h := range homes {
+			assert.LessOrEqual(t, math.Abs(h.X)+radius, (width/2-sceneMarginX)/px+1e-6, "%d dice: die %d fits horizontally", n, i)
+			assert.LessOrEqual(t, math.Abs(h.Y)+radius, (height/2-sceneMarginY)/px+1e-6, "%d dice: die %d fits vertically", n, i)
+			for _, o := range homes[i+1:] {
+				assert.GreaterOrEqual(t, h.Sub(o).Length(), 2*radius, "%d dice: dice should not overlap", n)
+			}
+		}
+	}
+}
+
+func TestRollDice_WithoutModel(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+
+	require.NoError(t, g.RollDice("2d3+1"))
+	assert.Empty(t, g.dice.scene.dice, "There is no model for a d3")
+	assert.True(t, g.dice.settled, "The total is shown at once")
+	assert.GreaterOrEqual(t, g.dice.result.Total, 3)
+
+	err := g.RollDice("4d6kh")
+	var syntax *dice.SyntaxError
+	assert.ErrorAs(t, err, &syntax)
+	assert.Equal(t, "2d3+1", g.dice.expr.String(), "A malformed expression keeps the previous roll")
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"github.com/olegshirko/dice_roller/pkg/events"
+)
+
+// EnableEvents включает публикацию событий броска в журнал l.
+func (g *Game) EnableEvents(l *events.Log) {
+	g.eventLog = l
+	g.phase = g.StateManager.Phase()
+}
+
+// publish отправляет событие, если публикация включена.
+func (g *Game) publish(e events.Event) {
+	if g.eventLog != nil {
+		g.eventLog.Publish(e)
+	}
+}
+
+// publishPhase сообщает о смене фазы броска с прошлой проверки. Фаза проверяется
+// в конце каждого тика и при запуске и завершении броска, поэтому фаза, начавшаяся
+// и закончившаяся внутри одного тика (бросок без анимации), не публикуется.
+func (g *Game) publishPhase() {
+	phase := g.StateManager.Phase()
+	if g.eventLog == nil || phase == g.phase {
+		return
+	}
+	g.publish(events.Event{Type: events.PhaseChanged, From: string(g.phase), Phase: string(phase), Cycle: g.StateManager.Cycle})
+	g.phase = phase
+}
+
+// publishSpinStarted сообщает о начале броска.
+func (g *Game) publishSpinStarted() {
+	g.publish(events.Event{Type: events.SpinStarted, Cycle: g.StateManager.Cycle})
+	g.publishPhase()
+}
+
+// publishWinner сообщает о выбранном участнике и, если он был последним в цикле,
+// об исчерпании цикла.
+func (g *Game) publishWinner(label string) {
+	g.publishPhase()
+	g.publish(events.Event{Type: events.WinnerDecided, Label: label, Cycle: g.StateManager.Cycle})
+	if g.StateManager.CycleExhausted() {
+		g.publish(events.Event{Type: events.CycleExhausted, Cycle: g.StateManager.Cycle})
+	}
+}
+
+// publishReloaded сообщает о загрузке участников.
+func (g *Game) publishReloaded() {
+	g.publish(events.Event{Type: events.TexturesReloaded, Participants: len(g.people()), Cycle: g.StateManager.Cycle})
+}
@@ -0,0 +1,75 @@
+//go:build !ci
+
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventTypes возвращает виды событий журнала после номера after.
+func eventTypes(l *events.Log, after uint64) []events.Type {
+	missed, _, cancel := l.Subscribe(after)
+	defer cancel()
+	var types []events.Type
+	for _, e := range missed {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestEvents_Spin(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	l := events.NewLog(events.DefaultKeep)
+	g.EnableEvents(l)
+
+	g.startRotation()
+	g.publishPhase()
+	for !g.StateManager.UpdateState() {
+		g.publishPhase() // Так фаза проверяется в конце каждого тика
+	}
+	g.onSpinFinished()
+
+	missed, _, cancel := l.Subscribe(0)
+	defer cancel()
+	require.Len(t, missed, 5)
+	assert.Equal(t, events.SpinStarted, missed[0].Type)
+	assert.Equal(t, []string{"idle", "rotating"}, []string{missed[1].From, missed[1].Phase})
+	assert.Equal(t, []string{"rotating", "snapping"}, []string{missed[2].From, missed[2].Phase})
+	assert.Equal(t, []string{"snapping", "idle"}, []string{missed[3].From, missed[3].Phase})
+	assert.Equal(t, events.WinnerDecided, missed[4].Type)
+	assert.Equal(t, g.status().LastWinner, missed[4].Label)
+	assert.Equal(t, 1, missed[4].Cycle)
+
+	// Шестой выбор исчерпывает цикл: на гранях не остается тех, кто еще не выигрывал
+	for i := 0; i < 4; i++ {
+		spin(t, g)
+	}
+	assert.NotContains(t, eventTypes(l, missed[4].Seq), events.CycleExhausted)
+	last := missed[4].Seq + 4*4
+	spin(t, g)
+	assert.Equal(t, []events.Type{events.SpinStarted, events.PhaseChanged, events.PhaseChanged, events.WinnerDecided, events.CycleExhausted},
+		eventTypes(l, last))
+}
+
+func TestEvents_Reloaded(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	l := events.NewLog(events.DefaultKeep)
+	g.EnableEvents(l)
+	roster := filepath.Join(t.TempDir(), "team.txt")
+	require.NoError(t, os.WriteFile(roster, []byte("Anna\nBob\nEve\n"), 0o644))
+	g.Config.Assets.Roster = roster
+
+	require.NoError(t, g.reloadAssets())
+	missed, _, cancel := l.Subscribe(0)
+	defer cancel()
+	require.Len(t, missed, 1)
+	assert.Equal(t, events.TexturesReloaded, missed[0].Type)
+	assert.Equal(t, 3, missed[0].Participants)
+	assert.Equal(t, 2, missed[0].Cycle, "Reloading starts a new cycle")
+}
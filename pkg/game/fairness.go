@@ -0,0 +1,103 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/olegshirko/dice_roller/pkg/fairness"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+)
+
+// fairnessRecorder ведет доказательство честности текущего цикла розыгрыша.
+type fairnessRecorder struct {
+	dir   string
+	cycle int
+	proof *fairness.Proof
+	pose  fairness.Draw // Положение кости в момент последнего запуска броска
+}
+
+// EnableFairness включает режим доказуемой честности. Перед каждым циклом в
+// каталог dir записывается обязательство (хеш зерна и исходного состояния),
+// а после цикла тот же файл дополняется бросками и раскрытым зерном.
+func (g *Game) EnableFairness(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	g.fairness = &fairnessRecorder{dir: dir}
+	g.commitCycle()
+	return nil
+}
+
+// commitCycle начинает новый цикл со свежим зерном и публикует обязательство.
+func (g *Game) commitCycle() {
+	f := g.fairness
+	seed := random.NewSeed()
+	rnd := random.NewPCG(seed)
+	g.StateManager.Random = rnd
+	g.AssetManager.Random = rnd
+
+	faces := make([]fairness.FaceState, len(g.Cube.Faces))
+	for i, face := range g.Cube.Faces {
+		faces[i] = fairness.FaceState{
+			Label:  g.AssetManager.LabelOf(face.Texture),
+			Grey:   g.StateManager.IsGrey[i],
+			Winner: g.StateManager.IsWinner[i],
+		}
+	}
+	pool := make([]string, len(g.AssetManager.AvailableTextures))
+	for i, tex := range g.AssetManager.AvailableTextures {
+		pool[i] = g.AssetManager.LabelOf(tex)
+	}
+
+	f.cycle++
+	f.proof = fairness.NewProof(seed, len(g.Cube.Faces), g.StateManager.Mode == roll.ModePhysics, faces, pool)
+	g.saveProof()
+	log.Printf("Fairness commitment for cycle %d: %s", f.cycle, f.proof.Commitment)
+}
+
+// revealCycle раскрывает зерно завершенного цикла.
+func (g *Game) revealCycle() {
+	g.fairness.proof.Reveal()
+	g.saveProof()
+	log.Printf("Fairness seed for cycle %d revealed: %d", g.fairness.cycle, *g.fairness.proof.Seed)
+}
+
+// saveProof записывает доказательство текущего цикла в файл.
+func (g *Game) saveProof() {
+	path := filepath.Join(g.fairness.dir, fmt.Sprintf("cycle-%03d.json", g.fairness.cycle))
+	if err := g.fairness.proof.Save(path); err != nil {
+		log.Printf("Could not save fairness proof %s: %v", path, err)
+	}
+}
+
+// observeStart запоминает положение кости перед запуском броска.
+func (f *fairnessRecorder) observeStart(sm *roll.StateManager) {
+	if sm.Rotating || sm.Snapping {
+		return
+	}
+	o := sm.Orientation
+	f.pose = fairness.Draw{
+		Orientation: [4]float64{o.W, o.X, o.Y, o.Z},
+		Position:    [3]float64{sm.Position.X, sm.Position.Y, sm.Position.Z},
+	}
+}
+
+// recordDraw добавляет завершившийся бросок в доказательство и, если в цикле
+// не осталось граней для розыгрыша, раскрывает зерно и начинает новый цикл.
+func (g *Game) recordDraw() {
+	sm := g.StateManager
+	draw := g.fairness.pose
+	draw.Face = sm.LastWinnerIndex
+	draw.Label = g.AssetManager.LabelOf(g.Cube.Faces[draw.Face].Texture)
+	g.fairness.proof.Draws = append(g.fairness.proof.Draws, draw)
+
+	if !sm.CycleExhausted() {
+		g.saveProof()
+		return
+	}
+	g.revealCycle()
+	g.commitCycle()
+}
@@ -0,0 +1,86 @@
+//go:build !ci
+
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/fairness"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFairGame создает игру с count именованными текстурами в режиме доказуемой честности.
+func newFairGame(t *testing.T, count int, mode roll.Mode) (*Game, string) {
+	am := assets.NewManager(random.NewPCG(1))
+	for i := 0; i < count; i++ {
+		am.AddTexture(ebiten.NewImage(1, 1), fmt.Sprintf("person%d", i))
+	}
+	am.AvailableTextures = append(am.AvailableTextures, am.AllTextures...)
+
+	g := NewGame(am, cube.NewCube(), random.NewPCG(1), config.Default())
+	g.StateManager.Mode = mode
+	dir := t.TempDir()
+	require.NoError(t, g.EnableFairness(dir))
+	return g, dir
+}
+
+// playCycle разыгрывает броски, пока цикл не завершится раскрытием зерна.
+func playCycle(t *testing.T, g *Game) {
+	cycle := g.fairness.cycle
+	for draws := 0; g.fairness.cycle == cycle; draws++ {
+		require.Less(t, draws, len(g.Cube.Faces), "cycle should end after every face has won")
+		g.startRotation()
+		require.True(t, g.StateManager.Finish(), "roll should finish")
+		g.onSpinFinished()
+	}
+}
+
+func TestFairness_CommitRevealVerify(t *testing.T) {
+	for _, mode := range []roll.Mode{roll.ModePredetermined, roll.ModePhysics} {
+		g, dir := newFairGame(t, 8, mode)
+
+		committed, err := fairness.Load(filepath.Join(dir, "cycle-001.json"))
+		require.NoError(t, err)
+		assert.Nil(t, committed.Seed, "Seed should not be published before the cycle ends")
+		assert.Len(t, committed.Pool, 2, "Two textures should remain in the pool")
+
+		playCycle(t, g)
+		playCycle(t, g)
+
+		for _, name := range []string{"cycle-001.json", "cycle-002.json"} {
+			proof, err := fairness.Load(filepath.Join(dir, name))
+			require.NoError(t, err)
+			require.NotNil(t, proof.Seed, "Seed should be revealed after the cycle")
+			assert.NotEmpty(t, proof.Draws)
+			assert.NoError(t, roll.VerifyProof(proof), "%s should verify", name)
+		}
+	}
+}
+
+func TestVerifyProof_DetectsTampering(t *testing.T) {
+	g, dir := newFairGame(t, 6, roll.ModePredetermined)
+	playCycle(t, g)
+
+	proof, err := fairness.Load(filepath.Join(dir, "cycle-001.json"))
+	require.NoError(t, err)
+	require.NoError(t, roll.VerifyProof(proof))
+
+	// Подмена победителя в одном из бросков
+	proof.Draws[0], proof.Draws[1] = proof.Draws[1], proof.Draws[0]
+	assert.Error(t, roll.VerifyProof(proof), "Swapped draws should not verify")
+
+	// Подмена зерна
+	proof.Draws[0], proof.Draws[1] = proof.Draws[1], proof.Draws[0]
+	seed := *proof.Seed + 1
+	proof.Seed = &seed
+	assert.Error(t, roll.VerifyProof(proof), "A different seed should not match the commitment")
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/roll"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Параметры броска жестом. Скорости указателя — в пикселях экрана за тик.
+const (
+	flingClickRadius = 6    // Дальше этого от точки нажатия указатель уже тянет кость, а не щелкает
+	flingMinSpeed    = 4.0  // Медленнее кость после перетаскивания просто отпускается
+	flingJumpSpeed   = 30.0 // Скорость, при которой кость подпрыгивает на обычную высоту
+	flingMinSpin     = 0.1  // Наименьшая скорость вращения после броска жестом (рад/тик)
+	flingMaxSpin     = 0.6  // Наибольшая скорость вращения после броска жестом (рад/тик)
+	flingSmoothing   = 0.5  // Доля новой скорости указателя в сглаженной
+)
+
+// pointer — состояние мыши или касания экрана за один тик.
+type pointer struct {
+	X, Y         int
+	JustPressed  bool
+	Pressed      bool
+	JustReleased bool
+	Touch        bool           // Касание экрана; в отличие от мыши, у него нет наведения
+	ID           ebiten.TouchID // Номер касания
+}
+
+// flingInput отслеживает, как кость схватили указателем и тянут.
+type flingInput struct {
+	grabbed        bool
+	touch          ebiten.TouchID // Касание, которым схвачена кость
+	isTouch        bool
+	startX, startY int
+	lastX, lastY   int
+	moved          bool    // Указатель отошел от точки нажатия, это не щелчок
+	vx, vy         float64 // Сглаженная скорость указателя
+	hovering       bool    // Указатель мыши был над костью в прошлый тик
+	hoverIdle      bool    // Вращение при простое включено наведением мыши
+	touches        []ebiten.TouchID
+}
+
+// readPointer возвращает состояние указателя: касания, которым схвачена кость,
+// нового касания или мыши.
+func (g *Game) readPointer() pointer {
+	f := &g.grab
+	if f.grabbed && f.isTouch {
+		if inpututil.IsTouchJustReleased(f.touch) {
+			x, y := inpututil.TouchPositionInPreviousTick(f.touch)
+			return pointer{X: x, Y: y, JustReleased: true, Touch: true, ID: f.touch}
+		}
+		x, y := ebiten.TouchPosition(f.touch)
+		return pointer{X: x, Y: y, Pressed: true, Touch: true, ID: f.touch}
+	}
+
+	f.touches = inpututil.AppendJustPressedTouchIDs(f.touches[:0])
+	if len(f.touches) > 0 && !f.grabbed {
+		id := f.touches[0]
+		x, y := ebiten.TouchPosition(id)
+		return pointer{X: x, Y: y, JustPressed: true, Pressed: true, Touch: true, ID: id}
+	}
+
+	x, y := ebiten.CursorPosition()
+	return pointer{
+		X:            x,
+		Y:            y,
+		JustPressed:  inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft),
+		Pressed:      ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft),
+		JustReleased: inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft),
+	}
+}
+
+// updateFling обрабатывает мышь и касания: кость можно схватить и бросить,
+// щелчок по кости бросает ее как клавиша, а наведение мыши раскручивает ее
+// при простое.
+func (g *Game) updateFling() {
+	g.handlePointer(g.readPointer())
+}
+
+// handlePointer применяет состояние указателя p к кости.
+func (g *Game) handlePointer(p pointer) {
+	f := &g.grab
+	sm := g.StateManager
+	busy := sm.Rotating || sm.Snapping || sm.Shaking
+
+	if !f.grabbed {
+		over := g.overDie(p.X, p.Y)
+		if p.JustPressed && over && !busy && !g.overAttendance(p.X, p.Y) {
+			f.grabbed, f.isTouch, f.touch = true, p.Touch, p.ID
+			f.startX, f.startY, f.lastX, f.lastY = p.X, p.Y, p.X, p.Y
+			f.moved, f.vx, f.vy = false, 0, 0
+			f.hovering, f.hoverIdle = true, false
+			sm.IdleRotating = false
+			return
+		}
+		g.updateHover(!p.Touch && over, busy)
+		return
+	}
+
+	dx, dy := float64(p.X-f.lastX), float64(p.Y-f.lastY)
+	f.lastX, f.lastY = p.X, p.Y
+	if math.Hypot(float64(p.X-f.startX), float64(p.Y-f.startY)) > flingClickRadius {
+		f.moved = true
+	}
+	if p.Pressed && !p.JustReleased {
+		// Пока кость держат, она поворачивается вслед за указателем, как трекбол
+		radius := g.dieRadius()
+		sm.Rotate(dy/radius, -dx/radius)
+		f.vx = f.vx*(1-flingSmoothing) + dx*flingSmoothing
+		f.vy = f.vy*(1-flingSmoothing) + dy*flingSmoothing
+		return
+	}
+
+	f.grabbed = false
+	switch speed := math.Hypot(f.vx, f.vy); {
+	case !f.moved:
+		g.startRotation() // Щелчок по кости
+	case speed >= flingMinSpeed:
+		g.fling(f.vx, f.vy)
+	}
+}
+
+// updateHover включает вращение при простое, когда мышь наводится на кость,
+// и выключает, когда она уходит. Кость, под которой мышь осталась после броска,
+// не раскручивается, иначе выигравшая грань сразу отвернется.
+func (g *Game) updateHover(over, busy bool) {
+	f := &g.grab
+	sm := g.StateManager
+	entered := over && !f.hovering
+	f.hovering = over
+	switch {
+	case busy:
+		f.hoverIdle = false
+	case entered && !sm.IdleRotating:
+		sm.IdleRotating = true
+		sm.RotationSpeedX = sm.Animation.IdleSpeedX
+		sm.RotationSpeedY = sm.Animation.IdleSpeedY
+		f.hoverIdle = true
+	case !over && f.hoverIdle:
+		sm.IdleRotating = false
+		f.hoverIdle = false
+	}
+}
+
+// fling бросает кость жестом со скоростью указателя (vx, vy). Победитель
+// выбирается так же, как при нажатии клавиши, поэтому жест задает только
+// вращение и высоту прыжка. В физическом режиме бросок обычный: исход
+// определяет сам бросок, и он должен повторяться при проверке честности.
+func (g *Game) fling(vx, vy float64) {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		return
+	}
+	g.startRotation()
+	if !sm.Rotating || sm.Mode == roll.ModePhysics {
+		return
+	}
+
+	// Движение указателя вправо поворачивает ближнюю к зрителю сторону кости вправо
+	radius := g.dieRadius()
+	speedX, speedY := vy/radius, -vx/radius
+	spin := math.Hypot(speedX, speedY)
+	k := min(max(spin, flingMinSpin), flingMaxSpin) / spin
+	sm.RotationSpeedX, sm.RotationSpeedY = speedX*k, speedY*k
+
+	jump := min(max(math.Hypot(vx, vy)/flingJumpSpeed, 0.3), 1.5)
+	sm.ScaleJump(jump)
+}
+
+// dieRadius возвращает радиус описанной окружности кости на экране в пикселях.
+func (g *Game) dieRadius() float64 {
+	return g.Config.Cube.Size / 2 * math.Sqrt(3)
+}
+
+// overDie проверяет, что точка экрана (x, y) попадает на кость.
+func (g *Game) overDie(x, y int) bool {
+	cx := float64(g.Config.Window.Width)/2 + g.StateManager.Position.X
+	cy := float64(g.Config.Window.Height)/2 + g.StateManager.Position.Y
+	return math.Hypot(float64(x)-cx, float64(y)-cy) <= g.dieRadius()
+}
+
+// overAttendance проверяет, что точка (x, y) попадает на строку панели присутствующих.
+func (g *Game) overAttendance(x, y int) bool {
+	return g.attendance.Visible && g.attendance.rowAt(x, y, len(g.people())) >= 0
+}
@@ -0,0 +1,102 @@
+//go:build !ci
+
+package game
+
+import (
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drag хватает кость в центре экрана, ведет указатель по шагам steps и отпускает.
+func drag(g *Game, steps ...[2]int) {
+	x, y := g.Config.Window.Width/2, g.Config.Window.Height/2
+	g.handlePointer(pointer{X: x, Y: y, JustPressed: true, Pressed: true})
+	for _, s := range steps {
+		x, y = x+s[0], y+s[1]
+		g.handlePointer(pointer{X: x, Y: y, Pressed: true})
+	}
+	g.handlePointer(pointer{X: x, Y: y, JustReleased: true})
+}
+
+func TestPointer_Click(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+
+	g.handlePointer(pointer{X: 5, Y: 5, JustPressed: true, Pressed: true})
+	g.handlePointer(pointer{X: 5, Y: 5, JustReleased: true})
+	assert.False(t, g.StateManager.Rotating, "A click outside the die should not roll it")
+
+	drag(g, [2]int{2, 1}) // Дрожание руки при щелчке — еще не перетаскивание
+	assert.True(t, g.StateManager.Rotating, "A click on the die should roll it")
+}
+
+func TestPointer_Fling(t *testing.T) {
+	keyboard := newAttendanceGame(t, 6)
+	keyboard.startRotation()
+
+	g := newAttendanceGame(t, 6)
+	drag(g, [2]int{30, 0}, [2]int{30, 0}, [2]int{30, 0})
+	sm := g.StateManager
+	require.True(t, sm.Rotating, "A fast drag should throw the die")
+	assert.Equal(t, keyboard.StateManager.WinningFaceIndex, sm.WinningFaceIndex, "A gesture should not change the winner")
+	assert.Less(t, sm.RotationSpeedY, 0.0, "Dragging to the right should spin the die to the right")
+	assert.Zero(t, sm.RotationSpeedX)
+	speedY := sm.RotationSpeedY
+	sm.UpdateState()
+	assert.Less(t, sm.Position.Y, 0.0, "The die should jump")
+
+	// Более быстрый жест раскручивает кость сильнее и подбрасывает выше
+	fast := newAttendanceGame(t, 6)
+	drag(fast, [2]int{0, 60}, [2]int{0, 60}, [2]int{0, 60})
+	assert.Greater(t, fast.StateManager.RotationSpeedX, -speedY)
+	fast.StateManager.UpdateState()
+	assert.Less(t, fast.StateManager.Position.Y, sm.Position.Y)
+
+	winner := sm.WinningFaceIndex
+	require.True(t, sm.Finish())
+	assert.Equal(t, winner, sm.LastWinnerIndex, "The thrown die should land on the chosen face")
+}
+
+func TestPointer_SlowDragTurnsDie(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	before := g.StateManager.Orientation
+
+	drag(g, [2]int{2, 0}, [2]int{2, 0}, [2]int{2, 0}, [2]int{2, 0}, [2]int{2, 0})
+	assert.False(t, g.StateManager.Rotating, "A slow drag should only turn the die")
+	assert.False(t, g.StateManager.IdleRotating, "A grabbed die stops idling")
+	assert.NotEqual(t, before, g.StateManager.Orientation)
+}
+
+func TestPointer_Hover(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	sm := g.StateManager
+	drag(g) // Щелчок по кости
+	require.True(t, sm.Finish())
+	require.False(t, sm.IdleRotating)
+
+	centerX, centerY := g.Config.Window.Width/2, g.Config.Window.Height/2
+	g.handlePointer(pointer{X: centerX, Y: centerY})
+	assert.False(t, sm.IdleRotating, "The die under the cursor that rolled it keeps showing the winner")
+
+	g.handlePointer(pointer{X: 5, Y: 5})
+	g.handlePointer(pointer{X: centerX, Y: centerY})
+	assert.True(t, sm.IdleRotating, "Hovering over the die should make it idle-rotate")
+
+	g.handlePointer(pointer{X: 5, Y: 5})
+	assert.False(t, sm.IdleRotating, "Leaving the die should stop it")
+
+	g.handlePointer(pointer{X: centerX, Y: centerY, Touch: true})
+	assert.False(t, sm.IdleRotating, "Touches do not hover")
+}
+
+func TestPointer_FlingPhysics(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	g.StateManager.Mode = roll.ModePhysics
+
+	drag(g, [2]int{30, 0}, [2]int{30, 0}, [2]int{30, 0})
+	require.True(t, g.StateManager.Rotating, "In physics mode a gesture throws the die as usual")
+	assert.True(t, g.StateManager.Finish())
+	assert.GreaterOrEqual(t, g.StateManager.LastWinnerIndex, 0)
+}
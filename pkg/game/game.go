@@ -1,73 +1,259 @@
 package game
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/olegshirko/dice_roller/pkg/assets"
 	"github.com/olegshirko/dice_roller/pkg/config"
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/events"
 	"github.com/olegshirko/dice_roller/pkg/graphics"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/raster"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/olegshirko/dice_roller/pkg/ui"
+	"github.com/olegshirko/dice_roller/pkg/webhook"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
-// Renderer defines the interface for drawing a cube.
+// Renderer defines the interface for drawing dice.
 type Renderer interface {
-	DrawCube(screen *ebiten.Image, c *cube.Cube, angleX, angleY, angleZ, offsetY float64)
+	DrawCubes(screen *ebiten.Image, models []raster.Model)
 }
 
+var (
+	_ Renderer = (*graphics.Renderer)(nil)
+	_ Renderer = (*graphics.SoftwareRenderer)(nil)
+)
+
 type Game struct {
-	Cube         *cube.Cube
+	Config       config.Config // Настройки окна, анимации и клавиш
+	Cube         *cube.Polyhedron
 	AssetManager *assets.Manager
-	StateManager *StateManager
+	StateManager *roll.StateManager
 	Renderer     Renderer
+	fairness     *fairnessRecorder // Доказательство честности, если режим включен
+	attendance   attendancePanel   // Панель отметки присутствующих
+
+	history        *history.Store  // Журнал результатов, если запись включена
+	recent         []history.Entry // Последние результаты для показа на экране
+	historyVisible bool
+
+	standup standupOrder // Очередь выступлений для стендапа
+
+	Timebox Timebox          // Настройки таймера выступления
+	timer   speakerTimer     // Таймер текущего выступающего
+	now     func() time.Time // Часы таймера; тесты подменяют их
+
+	Random random.Source // Источник случайности для бросков по выражению
+	dice   diceRoll      // Режим бросков по выражению
+
+	grab flingInput // Бросок кости мышью или касанием
+
+	undo undoHistory // Отмена и повтор последних выборов
+
+	Record    Recording     // Настройки записи бросков
+	recording rollRecording // Запись текущего броска
+
+	calls    chan func() // Команды Remote, ожидающие выполнения в Update
+	eventLog *events.Log // Журнал событий броска, если публикация включена
+	phase    roll.Phase  // Фаза броска, о которой сообщено последней
+
+	webhook       *webhook.Notifier // Уведомления о выбранных участниках, если включены
+	pendingNotice *webhook.Payload  // Уведомление, которое ждет снимка следующего кадра
+
+	gamepads          []ebiten.GamepadID // Подключенные геймпады
+	helpVisible       bool               // Показана справка по управлению
+	screenshotPending bool               // Снимок экрана запрошен и будет сделан после отрисовки кадра
+	ScreenshotDir     string             // Каталог для снимков экрана и записей; пустой — текущий каталог
 }
 
-// NewGame создает новую игру.
-func NewGame(assetManager *assets.Manager) *Game {
-	c := cube.NewCube()
-	sm := NewStateManager(c, assetManager)
+// NewGame создает новую игру с костью заданной формы и настройками cfg.
+func NewGame(assetManager *assets.Manager, c *cube.Polyhedron, rnd random.Source, cfg config.Config) *Game {
+	sm := roll.NewStateManager(c, assetManager, rnd, cfg.Animation)
+	if cfg.Cube.Physics {
+		sm.Mode = roll.ModePhysics
+	}
 	r := graphics.NewRenderer()
+	r.Scale = cfg.Cube.Scale()
+	r.Camera = raster.Camera{
+		FOV:      cfg.Camera.FOV,
+		Distance: cfg.Camera.Distance,
+		LookAt:   cube.Point3D{X: cfg.Camera.LookAt[0], Y: cfg.Camera.LookAt[1], Z: cfg.Camera.LookAt[2]},
+	}
+	if l := cfg.Lighting; l.Enabled {
+		r.Light = &raster.Light{
+			Direction: cube.Point3D{X: l.Direction[0], Y: l.Direction[1], Z: l.Direction[2]},
+			Ambient:   l.Ambient,
+			Diffuse:   l.Diffuse,
+			Specular:  l.Specular,
+			Shininess: l.Shininess,
+		}
+	}
+	r.Help = fmt.Sprintf("Press '%s' to spin, '%s' for help", cfg.Key(config.ActionSpin), cfg.Key(config.ActionHelp))
 
 	g := &Game{
+		Config:       cfg,
 		Cube:         c,
 		AssetManager: assetManager,
 		StateManager: sm,
 		Renderer:     r,
+		Timebox:      DefaultTimebox(),
+		now:          time.Now,
+		Record:       DefaultRecording(),
+		Random:       rnd,
+		calls:        make(chan func(), remoteQueue),
 	}
 
 	// Устанавливаем начальные текстуры, если они были загружены
-	assetManager.SetInitialTextures(&g.Cube.Faces, &g.StateManager.IsGrey)
+	assetManager.SetInitialTextures(g.Cube.Faces, g.StateManager.IsGrey)
 
 	return g
 }
 
 // Update выполняется каждый такт (tick).
 func (g *Game) Update() error {
+	g.runRemote()
+	if err := g.updateGlobal(); err != nil {
+		return err
+	}
+	// Режим бросков по выражению перехватывает весь остальной ввод, в том числе набор текста
+	if g.updateDice() {
+		return nil
+	}
+
 	// Обработка пользовательского ввода
-	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+	if g.pressed(config.ActionLoad) {
+		// Диалог блокирует горутину до выбора файлов, поэтому показывается в фоне,
+		// а файлы загружаются в Update вместе с командами Remote: пул тасуется
+		// общим источником случайности и грани читаются при отрисовке.
 		go func() {
-			g.AssetManager.LoadTextures()
-			g.AssetManager.SetInitialTextures(&g.Cube.Faces, &g.StateManager.IsGrey)
-			g.StateManager.LastWinnerIndex = -1
+			if files := ui.PickTextures(); files != nil {
+				g.calls <- func() { g.loadFiles(files) }
+			}
 		}()
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-		g.StateManager.StartRotation()
+	if g.pressed(config.ActionResetCycle) {
+		g.ResetCycle()
+	}
+	if g.pressed(config.ActionUndo) {
+		g.Undo()
+	}
+	if g.pressed(config.ActionRedo) {
+		g.Redo()
+	}
+	g.updateAttendance()
+	g.updateHistory()
+
+	if g.pressed(config.ActionSpin) {
+		g.startRotation()
 	}
+	g.updateFling()
+	g.updateStandup()
+	g.updateTimer()
 
 	// Обновляем состояние игры (вращение, и т.д.)
-	g.StateManager.UpdateState()
+	if g.StateManager.UpdateState() {
+		g.onSpinFinished()
+	}
+	g.publishPhase()
+	g.tickRecording()
 
 	return nil
 }
 
+// startRotation запускает бросок, запоминая положение кости для доказательства честности
+// и состояние розыгрыша для отмены, начинает запись броска, если она включена,
+// и сообщает о броске подписчикам событий.
+func (g *Game) startRotation() {
+	sm := g.StateManager
+	idle := !sm.Rotating && !sm.Snapping
+	var before drawSnapshot
+	if idle {
+		// Новый бросок означает, что предыдущий участник закончил выступление.
+		g.stopTimer()
+		before = g.snapshot()
+	}
+	if g.fairness != nil {
+		g.fairness.observeStart(sm)
+	}
+	sm.StartRotation()
+	if sm.Rotating || sm.Snapping {
+		g.startRecording()
+	}
+	if idle && (sm.Rotating || sm.Snapping) {
+		g.publishSpinStarted()
+		if !g.standup.active() {
+			g.pushUndo(before)
+			g.undo.pending = true
+		}
+	}
+}
+
+// onSpinFinished обрабатывает завершение броска: записывает результат в журнал
+// и в доказательство честности, сообщает о нем подписчикам событий и отправляет уведомление.
+func (g *Game) onSpinFinished() {
+	label := g.StateManager.LastWinner()
+	entry := g.recordHistory(label)
+	g.recordUndoEntry(entry)
+	g.finishRecording()
+	g.publishWinner(label)
+	g.notifyWinner(entry)
+	if g.standup.drawing {
+		g.recordSpeaker(entry)
+	} else {
+		g.startTimer(entry)
+	}
+	if g.fairness != nil {
+		g.recordDraw()
+	}
+}
+
 // Draw выполняется каждый кадр (frame).
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.Renderer.DrawCube(screen, g.Cube, g.StateManager.AngleX, g.StateManager.AngleY, g.StateManager.AngleZ, g.StateManager.OffsetY)
+	g.drawScene(screen)
+	g.captureRecording(screen)
+	g.captureNotice(screen)
+	if g.helpVisible {
+		g.drawHelp(screen)
+	}
+	if g.screenshotPending {
+		g.screenshotPending = false
+		g.saveScreenshot(screen)
+	}
+}
+
+// drawScene рисует кость и панели текущего режима.
+func (g *Game) drawScene(screen *ebiten.Image) {
+	if g.dice.active() && len(g.dice.scene.dice) > 0 {
+		g.Renderer.DrawCubes(screen, g.dice.scene.models())
+	} else {
+		g.Renderer.DrawCubes(screen, []raster.Model{{Cube: g.Cube, Orientation: g.StateManager.Orientation, Position: g.StateManager.Position}})
+	}
+	if g.dice.active() {
+		g.drawDice(screen)
+		return
+	}
+
+	if g.attendance.Visible {
+		g.drawAttendance(screen)
+	}
+	if g.historyVisible {
+		g.drawHistory(screen)
+	}
+	if g.standup.active() {
+		g.drawStandup(screen)
+	}
+	if g.timer.running {
+		g.drawTimer(screen)
+	}
 }
 
 // Layout принимает логические размеры экрана и возвращает физические размеры.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return config.ScreenWidth, config.ScreenHeight
+	return g.Config.Window.Width, g.Config.Window.Height
 }
@@ -8,19 +8,24 @@ import (
 	"github.com/olegshirko/dice_roller/pkg/assets"
 	"github.com/olegshirko/dice_roller/pkg/config"
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/raster"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// maxTestTicks ограничивает ожидание анимации в тестах.
+const maxTestTicks = 100000
+
 // MockRenderer is a mock implementation of the Renderer.
 type MockRenderer struct {
 	mock.Mock
 }
 
-func (m *MockRenderer) DrawCube(screen *ebiten.Image, c *cube.Cube, angleX, angleY, angleZ, offsetY float64) {
-	m.Called(screen, c, angleX, angleY, angleZ, offsetY)
+func (m *MockRenderer) DrawCubes(screen *ebiten.Image, models []raster.Model) {
+	m.Called(screen, models)
 }
 
 // MockAssetManager is a mock implementation of the AssetManager.
@@ -32,17 +37,17 @@ func (m *MockAssetManager) LoadTextures() {
 	m.Called()
 }
 
-func (m *MockAssetManager) SetInitialTextures(faces *[6]cube.Face, isGrey *[6]bool) {
+func (m *MockAssetManager) SetInitialTextures(faces []cube.Face, isGrey []bool) {
 	m.Called(faces, isGrey)
 }
 
-func (m *MockAssetManager) ReplaceFaceTexture(faceIndex int, faces *[6]cube.Face, isGrey *[6]bool) {
+func (m *MockAssetManager) ReplaceFaceTexture(faceIndex int, faces []cube.Face, isGrey []bool) {
 	m.Called(faceIndex, faces, isGrey)
 }
 
 func TestGame_Update(t *testing.T) {
 	assetManager := &assets.Manager{}
-	game := NewGame(assetManager)
+	game := NewGame(assetManager, cube.NewCube(), random.NewPCG(1), config.Default())
 
 	// Test initial state
 	assert.NotNil(t, game)
@@ -53,22 +58,20 @@ func TestGame_Update(t *testing.T) {
 	// Simulate key press
 	// This is tricky to test without a running game loop.
 	// We will focus on the state changes that Update triggers.
-	initialAngleX := game.StateManager.AngleX
-	initialAngleY := game.StateManager.AngleY
+	initialOrientation := game.StateManager.Orientation
 
 	// To properly test Update, we would need to simulate key presses.
 	// For now, we just call Update and check that it doesn't panic and that the state is updated.
 	err := game.Update()
 	assert.NoError(t, err)
 
-	// Since IdleRotating is true by default, the angles should change.
-	assert.NotEqual(t, initialAngleX, game.StateManager.AngleX, "AngleX should change due to idle rotation")
-	assert.NotEqual(t, initialAngleY, game.StateManager.AngleY, "AngleY should change due to idle rotation")
+	// Since IdleRotating is true by default, the orientation should change.
+	assert.NotEqual(t, initialOrientation, game.StateManager.Orientation, "Orientation should change due to idle rotation")
 }
 
 func TestGame_Draw(t *testing.T) {
 	assetManager := &assets.Manager{}
-	game := NewGame(assetManager)
+	game := NewGame(assetManager, cube.NewCube(), random.NewPCG(1), config.Default())
 
 	mockRenderer := new(MockRenderer)
 	game.Renderer = mockRenderer // Inject mock renderer
@@ -77,7 +80,7 @@ func TestGame_Draw(t *testing.T) {
 	screen := ebiten.NewImage(100, 100)
 
 	// Set up the mock expectation
-	mockRenderer.On("DrawCube", screen, game.Cube, game.StateManager.AngleX, game.StateManager.AngleY, game.StateManager.AngleZ, game.StateManager.OffsetY)
+	mockRenderer.On("DrawCubes", screen, []raster.Model{{Cube: game.Cube, Orientation: game.StateManager.Orientation, Position: game.StateManager.Position}})
 
 	// Call the method
 	game.Draw(screen)
@@ -87,8 +90,13 @@ func TestGame_Draw(t *testing.T) {
 }
 
 func TestGame_Layout(t *testing.T) {
-	game := &Game{}
+	game := &Game{Config: config.Default()}
 	width, height := game.Layout(800, 600)
 	assert.Equal(t, config.ScreenWidth, width)
 	assert.Equal(t, config.ScreenHeight, height)
+
+	game.Config.Window.Width, game.Config.Window.Height = 1280, 800
+	width, height = game.Layout(800, 600)
+	assert.Equal(t, 1280, width, "Layout should follow the configured window")
+	assert.Equal(t, 800, height)
 }
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Размеры панели последних результатов в пикселях экрана.
+const (
+	historyShown     = 10 // Сколько последних результатов показывать
+	historyWidth     = 260
+	historyRowHeight = 16
+	historyMargin    = 10 // Отступ от правого края экрана
+	historyY         = 30
+)
+
+// EnableHistory включает запись результатов в журнал store и загружает
+// последние результаты для показа на экране. Нумерация циклов продолжается
+// с последней записи журнала, чтобы циклы разных запусков не сливались.
+func (g *Game) EnableHistory(store *history.Store) {
+	g.history = store
+	recent, err := store.Last(historyShown)
+	if err != nil {
+		log.Printf("Could not read history from %s: %v", store.Path, err)
+	}
+	g.recent = recent
+	if len(recent) > 0 {
+		g.StateManager.Cycle = recent[len(recent)-1].Cycle + 1
+	}
+}
+
+// recordHistory дописывает в журнал участника label, выбранного последним броском,
+// и возвращает сделанную запись.
+func (g *Game) recordHistory(label string) history.Entry {
+	sm := g.StateManager
+	entry := history.Entry{
+		Time:  time.Now(),
+		Label: label,
+		Cycle: sm.Cycle,
+	}
+	// В режиме доказуемой честности зерно остается секретным до конца цикла.
+	if seeded, ok := sm.Random.(random.Seeded); ok && g.fairness == nil {
+		seed := seeded.Seed()
+		entry.Seed = &seed
+	}
+
+	g.addHistoryEntry(entry)
+	return entry
+}
+
+// addHistoryEntry дописывает запись в журнал и в последние результаты.
+func (g *Game) addHistoryEntry(entry history.Entry) {
+	g.StateManager.Policy.Observe(entry)
+	g.recent = history.Tail(append(g.recent, entry), historyShown)
+	if g.history == nil {
+		return
+	}
+	if err := g.history.Append(entry); err != nil {
+		log.Printf("Could not save history to %s: %v", g.history.Path, err)
+	}
+}
+
+// removeHistoryEntry удаляет отмененный выбор из журнала и последних результатов,
+// а политика выбора его забывает. Повтор снова добавляет выбор через addHistoryEntry.
+func (g *Game) removeHistoryEntry(entry history.Entry) {
+	g.StateManager.Policy.Forget(entry)
+	g.recent = slices.DeleteFunc(g.recent, func(e history.Entry) bool {
+		return e.Time.Equal(entry.Time) && e.Label == entry.Label
+	})
+	if g.history == nil {
+		return
+	}
+	if err := g.history.Remove(entry); err != nil {
+		log.Printf("Could not remove undone pick from history in %s: %v", g.history.Path, err)
+	}
+}
+
+// updateHistoryEntry обновляет уже сделанную запись журнала, например дописывая длительность выступления.
+func (g *Game) updateHistoryEntry(entry history.Entry) {
+	for i := range g.recent {
+		if g.recent[i].Time.Equal(entry.Time) && g.recent[i].Label == entry.Label {
+			g.recent[i] = entry
+		}
+	}
+	if g.history == nil {
+		return
+	}
+	if err := g.history.Update(entry); err != nil {
+		log.Printf("Could not update history in %s: %v", g.history.Path, err)
+	}
+}
+
+// updateHistory открывает и закрывает панель последних результатов клавишей H.
+func (g *Game) updateHistory() {
+	if g.pressed(config.ActionHistory) {
+		g.historyVisible = !g.historyVisible
+	}
+}
+
+// drawHistory рисует панель последних результатов, самые свежие — сверху.
+func (g *Game) drawHistory(screen *ebiten.Image) {
+	historyX := screen.Bounds().Dx() - historyWidth - historyMargin
+	height := float32((len(g.recent) + 1) * historyRowHeight)
+	vector.DrawFilledRect(screen, float32(historyX-4), historyY-4, historyWidth+8, height+8, color.RGBA{A: 0xC0}, false)
+
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Last results (%s to close)", g.Config.Keys.History), historyX, historyY)
+	for row := range g.recent {
+		e := g.recent[len(g.recent)-1-row]
+		line := fmt.Sprintf("%s  #%d  %s", e.Time.Format("Jan 02 15:04"), e.Cycle, e.Label)
+		if e.SpeakingSeconds > 0 {
+			line += fmt.Sprintf("  %d:%02d", int(e.SpeakingSeconds)/60, int(e.SpeakingSeconds)%60)
+		}
+		ebitenutil.DebugPrintAt(screen, line, historyX, historyY+(row+1)*historyRowHeight)
+	}
+}
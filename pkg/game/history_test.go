@@ -0,0 +1,62 @@
+//go:build !ci
+
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spin запускает бросок и доводит его до конца так же, как Game.Update.
+func spin(t *testing.T, g *Game) {
+	g.startRotation()
+	require.True(t, g.StateManager.Finish(), "spin should finish")
+	g.onSpinFinished()
+}
+
+func TestRecordHistory(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	g.EnableHistory(store)
+
+	for i := 0; i < 7; i++ {
+		spin(t, g)
+	}
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 7)
+
+	labels := map[string]bool{}
+	for _, e := range entries[:6] {
+		assert.Equal(t, 1, e.Cycle)
+		require.NotNil(t, e.Seed, "Seed of a seeded source should be recorded")
+		assert.Equal(t, uint64(1), *e.Seed)
+		labels[e.Label] = true
+	}
+	assert.Len(t, labels, 6, "Every person should be picked once per cycle")
+	assert.Equal(t, 2, entries[6].Cycle, "The seventh spin starts a new cycle")
+
+	// Новый запуск видит последние результаты из журнала
+	next := newAttendanceGame(t, 8)
+	next.EnableHistory(store)
+	assert.Len(t, next.recent, 7)
+	assert.Equal(t, entries[6], next.recent[6])
+
+	// и продолжает нумерацию циклов
+	assert.Equal(t, 3, next.StateManager.Cycle)
+	spin(t, next)
+	entries, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, entries[7].Cycle)
+}
+
+func TestRecordHistory_WithoutStore(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	spin(t, g)
+	assert.Len(t, g.recent, 1, "Results are shown on screen even when history is not saved")
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"image"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/record"
+	"github.com/olegshirko/dice_roller/pkg/webhook"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Recording — настройки записи бросков.
+type Recording struct {
+	All     bool           // Записывать каждый бросок, а не только после нажатия клавиши
+	Options record.Options // Формат, уменьшение и частота кадров
+}
+
+// DefaultRecording возвращает настройки записи по умолчанию: GIF вдвое меньше
+// экрана с частотой 20 кадров в секунду.
+func DefaultRecording() Recording {
+	return Recording{Options: record.Options{Format: record.GIF, Scale: 0.5, FPS: 20}}
+}
+
+// rollRecording — запись броска от начала вращения до остановки кости.
+type rollRecording struct {
+	armed     bool             // Следующий бросок будет записан
+	recorder  *record.Recorder // Идет запись
+	frameDue  bool             // В этом тике еще не снят кадр
+	finishing bool             // Кость остановилась; запись закроется после следующего кадра
+	notice    *webhook.Payload // Уведомление, которое ждет анимацию броска
+}
+
+// toggleRecording включает или отменяет запись следующего броска.
+func (g *Game) toggleRecording() {
+	g.recording.armed = !g.recording.armed
+	if g.recording.armed {
+		log.Println("The next roll will be recorded.")
+	} else {
+		log.Println("Recording cancelled.")
+	}
+}
+
+// startRecording начинает запись броска, если она включена. Вызывается, когда
+// кость начала вращаться.
+func (g *Game) startRecording() {
+	rec := &g.recording
+	if rec.recorder != nil || !(rec.armed || g.Record.All || g.webhookWantsGIF()) {
+		return
+	}
+	if g.standup.drawing && g.standup.fast {
+		return // Бросок без анимации: записывать нечего
+	}
+	path := filepath.Join(g.ScreenshotDir, time.Now().Format("dice_roller-20060102-150405.000")+g.Record.Options.Format.Ext())
+	recorder, err := record.Start(path, g.Record.Options, ebiten.TPS())
+	if err != nil {
+		log.Printf("Could not start recording: %v", err)
+		return
+	}
+	rec.armed = false
+	rec.recorder = recorder
+}
+
+// tickRecording отмечает, что в этом тике нужен кадр записи.
+func (g *Game) tickRecording() {
+	if g.recording.recorder != nil {
+		g.recording.frameDue = true
+	}
+}
+
+// finishRecording отмечает, что кость остановилась: кадр с выпавшей гранью
+// будет последним.
+func (g *Game) finishRecording() {
+	if g.recording.recorder != nil {
+		g.recording.finishing = true
+	}
+}
+
+// captureRecording снимает кадр записи с готового кадра экрана: не чаще раза
+// за тик и без справки поверх сцены. После последнего кадра запись кодируется в фоне.
+func (g *Game) captureRecording(screen *ebiten.Image) {
+	rec := &g.recording
+	if rec.recorder == nil || !rec.frameDue {
+		return
+	}
+	rec.frameDue = false
+	if rec.recorder.Due() || rec.finishing {
+		b := screen.Bounds()
+		img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		screen.ReadPixels(img.Pix)
+		rec.recorder.Add(img)
+	}
+	if !rec.finishing {
+		return
+	}
+
+	recorder, notice := rec.recorder, rec.notice
+	var send func(webhook.Payload)
+	if notice != nil {
+		send = g.webhook.Pending()
+	}
+	*rec = rollRecording{armed: rec.armed}
+	go func() {
+		err := recorder.Close()
+		if err != nil {
+			log.Printf("Could not save recording %s: %v", recorder.Path(), err)
+		} else {
+			log.Printf("Recording saved to %s (%d frames)", recorder.Path(), recorder.Frames())
+		}
+		if notice != nil {
+			notifyRecorded(send, *notice, recorder.Path(), err == nil)
+		}
+	}()
+}
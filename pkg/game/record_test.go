@@ -0,0 +1,41 @@
+//go:build !ci
+
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecording_Lifecycle(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	g.ScreenshotDir = t.TempDir()
+
+	spin(t, g)
+	assert.Nil(t, g.recording.recorder, "Rolls are not recorded unless asked")
+
+	g.toggleRecording()
+	g.startRotation()
+	recorder := g.recording.recorder
+	require.NotNil(t, recorder, "The armed roll is recorded")
+	assert.False(t, g.recording.armed, "Only one roll is recorded per key press")
+	assert.Equal(t, ".gif", filepath.Ext(recorder.Path()))
+
+	g.startRotation() // Повторный толчок не начинает новую запись
+	assert.Same(t, recorder, g.recording.recorder)
+
+	require.True(t, g.StateManager.Finish())
+	g.onSpinFinished()
+	g.tickRecording()
+	assert.True(t, g.recording.finishing, "The frame with the result closes the recording")
+	assert.True(t, g.recording.frameDue)
+
+	// Бросок без анимации не записывается даже при -record
+	g.recording = rollRecording{}
+	g.Record.All = true
+	g.DrawStandupOrder()
+	assert.Nil(t, g.recording.recorder)
+}
@@ -0,0 +1,231 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+)
+
+// remoteQueue — сколько вызовов Remote может ждать очередного тика.
+const remoteQueue = 16
+
+// Ошибки команд Remote.
+var (
+	ErrRolling            = errors.New("the die is rolling")
+	ErrNobodyLeft         = errors.New("nobody is left to pick")
+	ErrDiceMode           = errors.New("dice expression mode is active")
+	ErrUnknownParticipant = errors.New("unknown participant")
+)
+
+// Status — состояние розыгрыша для внешних программ.
+type Status struct {
+	Phase      roll.Phase `json:"phase"`
+	LastWinner string     `json:"last_winner,omitempty"` // Участник на выпавшей грани, пока кость не брошена снова
+	Cycle      int        `json:"cycle"`
+}
+
+// Participant — участник и его отметка присутствия.
+type Participant struct {
+	Label  string `json:"label"`
+	Absent bool   `json:"absent"`
+}
+
+// Remote управляет игрой из других горутин, например из HTTP-сервера. Состояние
+// игры меняется только в Update, поэтому каждая команда передается в Update и
+// выполняется в начале следующего тика; вызов ждет ее завершения.
+type Remote struct {
+	g *Game
+}
+
+// Remote возвращает управление игрой для других горутин.
+func (g *Game) Remote() *Remote {
+	return &Remote{g: g}
+}
+
+// do выполняет f в горутине Update. Если ctx отменен раньше, чем f выполнилась,
+// do возвращает ошибку ctx, а f все равно выполнится, если уже попала в очередь.
+func (r *Remote) do(ctx context.Context, f func() error) error {
+	done := make(chan error, 1)
+	select {
+	case r.g.calls <- func() { done <- f() }:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runRemote выполняет команды Remote, накопившиеся с прошлого тика.
+func (g *Game) runRemote() {
+	for {
+		select {
+		case f := <-g.calls:
+			f()
+		default:
+			return
+		}
+	}
+}
+
+// Spin бросает кость, как клавиша броска.
+func (r *Remote) Spin(ctx context.Context) error {
+	return r.do(ctx, r.g.remoteSpin)
+}
+
+// Reload заново загружает участников из каталога или списка команды из настроек
+// и начинает новый цикл.
+func (r *Remote) Reload(ctx context.Context) error {
+	return r.do(ctx, r.g.reloadAssets)
+}
+
+// Status возвращает фазу броска, последнего выбранного и номер цикла.
+func (r *Remote) Status(ctx context.Context) (Status, error) {
+	var s Status
+	err := r.do(ctx, func() error {
+		s = r.g.status()
+		return nil
+	})
+	return s, err
+}
+
+// Participants возвращает всех участников с отметками присутствия.
+func (r *Remote) Participants(ctx context.Context) ([]Participant, error) {
+	var people []Participant
+	err := r.do(ctx, func() error {
+		people = r.g.participants()
+		return nil
+	})
+	return people, err
+}
+
+// SetAbsent отмечает участника label отсутствующим или вернувшимся.
+func (r *Remote) SetAbsent(ctx context.Context, label string, absent bool) (Participant, error) {
+	p := Participant{Label: label, Absent: absent}
+	err := r.do(ctx, func() error {
+		return r.g.remoteSetAbsent(label, absent)
+	})
+	return p, err
+}
+
+// Toggle меняет отметку участника label на противоположную.
+func (r *Remote) Toggle(ctx context.Context, label string) (Participant, error) {
+	p := Participant{Label: label}
+	err := r.do(ctx, func() error {
+		p.Absent = !r.g.AssetManager.IsAbsent(label)
+		return r.g.remoteSetAbsent(label, p.Absent)
+	})
+	return p, err
+}
+
+// History возвращает не больше limit последних результатов: из журнала, если он
+// ведется, иначе из показанных на экране.
+func (r *Remote) History(ctx context.Context, limit int) ([]history.Entry, error) {
+	var entries []history.Entry
+	err := r.do(ctx, func() error {
+		var err error
+		entries, err = r.g.lastResults(limit)
+		return err
+	})
+	return entries, err
+}
+
+// remoteSpin запускает бросок или объясняет, почему он невозможен.
+func (g *Game) remoteSpin() error {
+	sm := g.StateManager
+	switch {
+	case g.dice.active():
+		return ErrDiceMode
+	case sm.Rotating || sm.Snapping:
+		return ErrRolling
+	}
+	g.startRotation()
+	if !sm.Rotating && !sm.Snapping {
+		return ErrNobodyLeft
+	}
+	return nil
+}
+
+// reloadAssets заново загружает участников из источника, заданного в настройках,
+// и раздает их на грани.
+func (g *Game) reloadAssets() error {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		return ErrRolling
+	}
+	a := g.Config.Assets
+	if !g.AssetManager.Reload(a.Dir, a.Roster) {
+		if a.Roster != "" {
+			return fmt.Errorf("nobody could be loaded from roster %s", a.Roster)
+		}
+		return fmt.Errorf("no images could be loaded from %s", a.Dir)
+	}
+	g.forgetUndo()
+	g.stopTimer()
+	g.standup = standupOrder{}
+	g.redeal()
+	g.publishReloaded()
+	log.Printf("Faces reloaded; cycle %d started.", sm.Cycle)
+	return nil
+}
+
+// loadFiles заменяет участников текстурами из файлов, выбранных клавишей
+// загрузки, и раздает их на грани.
+func (g *Game) loadFiles(files []string) {
+	g.forgetUndo()
+	g.AssetManager.LoadFiles(files)
+	g.AssetManager.SetInitialTextures(g.Cube.Faces, g.StateManager.IsGrey)
+	g.StateManager.LastWinnerIndex = -1
+	g.publishReloaded()
+	if g.fairness != nil {
+		// Новые текстуры меняют пул, поэтому текущий цикл закрывается досрочно.
+		g.revealCycle()
+		g.commitCycle()
+	}
+}
+
+// status собирает Status.
+func (g *Game) status() Status {
+	sm := g.StateManager
+	return Status{Phase: sm.Phase(), LastWinner: sm.LastWinner(), Cycle: sm.Cycle}
+}
+
+// participants возвращает участников в порядке загрузки.
+func (g *Game) participants() []Participant {
+	people := []Participant{}
+	for _, label := range g.people() {
+		people = append(people, Participant{Label: label, Absent: g.AssetManager.IsAbsent(label)})
+	}
+	return people
+}
+
+// remoteSetAbsent меняет отметку участника, как панель присутствующих.
+func (g *Game) remoteSetAbsent(label string, absent bool) error {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		return ErrRolling
+	}
+	for _, p := range g.people() {
+		if p == label {
+			g.setAbsent(label, absent)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownParticipant, label)
+}
+
+// lastResults возвращает не больше limit последних результатов.
+func (g *Game) lastResults(limit int) ([]history.Entry, error) {
+	if g.history == nil {
+		return slices.Clone(history.Tail(g.recent, limit)), nil
+	}
+	return g.history.Last(limit)
+}
@@ -0,0 +1,110 @@
+//go:build !ci
+
+package game
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runUpdates выполняет команды Remote, как это делает Update, пока не закончится тест.
+func runUpdates(t *testing.T, g *Game) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			g.runRemote()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+}
+
+func TestRemote(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	remote := g.Remote()
+	ctx := context.Background()
+	runUpdates(t, g)
+
+	require.NoError(t, remote.Spin(ctx))
+	status, err := remote.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Status{Phase: roll.PhaseRotating, Cycle: 1}, status)
+	assert.ErrorIs(t, remote.Spin(ctx), ErrRolling)
+	_, err = remote.Toggle(ctx, "person1")
+	assert.ErrorIs(t, err, ErrRolling, "Attendance waits until the die stops")
+
+	require.NoError(t, remote.do(ctx, func() error {
+		if !g.StateManager.Finish() {
+			return errors.New("spin should finish")
+		}
+		g.onSpinFinished()
+		return nil
+	}))
+	status, err = remote.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, roll.PhaseIdle, status.Phase)
+	assert.NotEmpty(t, status.LastWinner)
+	entries, err := remote.History(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, status.LastWinner, entries[0].Label)
+
+	p, err := remote.Toggle(ctx, "person1")
+	require.NoError(t, err)
+	assert.Equal(t, Participant{Label: "person1", Absent: true}, p)
+	p, err = remote.SetAbsent(ctx, "person1", false)
+	require.NoError(t, err)
+	assert.False(t, p.Absent)
+	people, err := remote.Participants(ctx)
+	require.NoError(t, err)
+	assert.Len(t, people, 8)
+	_, err = remote.SetAbsent(ctx, "nobody", true)
+	assert.ErrorIs(t, err, ErrUnknownParticipant)
+}
+
+func TestRemote_NotRunning(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Без Update команда не выполняется, и вызов возвращается по таймауту
+	assert.ErrorIs(t, g.Remote().Spin(ctx), context.DeadlineExceeded)
+	assert.Equal(t, roll.PhaseIdle, g.StateManager.Phase())
+}
+
+func TestLoadFiles(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	spin(t, g)
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"Anna.png", "Bob.png"} {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, png.Encode(f, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+		require.NoError(t, f.Close())
+		files = append(files, path)
+	}
+
+	// Клавиша загрузки передает выбранные файлы в Update так же, как команды Remote
+	g.calls <- func() { g.loadFiles(files) }
+	g.runRemote()
+	assert.ElementsMatch(t, []string{"Anna", "Bob"}, g.people())
+	assert.Equal(t, -1, g.StateManager.LastWinnerIndex, "The new faces have no winner yet")
+	assert.Equal(t, "Anna", g.AssetManager.LabelOf(g.AssetManager.AllTextures[0]))
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/raster"
+	"github.com/olegshirko/dice_roller/pkg/roll"
+)
+
+// Раскладка нескольких костей на экране.
+const (
+	sceneGap     = 20.0 // Зазор между описанными сферами соседних костей в единицах модели
+	sceneMarginX = 20.0 // Отступ от краев экрана по горизонтали в пикселях
+	sceneMarginY = 90.0 // Отступ сверху и снизу: там подсказка и панель с результатом
+	sceneMaxDice = 36   // Больше костей на экране не показывается, итог считается по всем
+)
+
+// sceneDie — одна из костей сцены со своей ориентацией и анимацией.
+type sceneDie struct {
+	Cube    *cube.Polyhedron
+	State   *roll.StateManager
+	home    cube.Point3D // Место кости в раскладке в единицах модели
+	settled bool         // Кость остановилась после последнего броска
+}
+
+// scene — несколько костей, брошенных одновременно. Без физики кости стоят
+// сеткой и не пересекаются; с физикой они падают на общий стол и сталкиваются.
+type scene struct {
+	dice  []*sceneDie
+	size  float64 // Размер костей относительно обычного, чтобы все поместились на экране
+	scale float64 // Пикселей экрана на единицу модели при обычном размере
+}
+
+// layoutScene раскладывает n костей сеткой, которая помещается в экран width×height.
+// Возвращает места костей в единицах модели относительно центра экрана и размер
+// костей относительно обычного (не больше 1). Число столбцов выбирается так,
+// чтобы кости получились как можно крупнее.
+func layoutScene(n int, width, height, scale float64) (homes []cube.Point3D, size float64) {
+	if n == 0 {
+		return nil, 1
+	}
+	step := config.CubeSize*math.Sqrt(3) + sceneGap // Все модели вписаны в сферу куба
+	areaW, areaH := width-2*sceneMarginX, height-2*sceneMarginY
+
+	cols := 1
+	size = 0
+	for c := 1; c <= n; c++ {
+		rows := (n + c - 1) / c
+		s := math.Min(areaW/(float64(c)*step*scale), areaH/(float64(rows)*step*scale))
+		if s > size {
+			cols, size = c, s
+		}
+	}
+	size = math.Min(size, 1)
+
+	rows := (n + cols - 1) / cols
+	homes = make([]cube.Point3D, n)
+	for i := range homes {
+		row, col := i/cols, i%cols
+		inRow := min(cols, n-row*cols) // Неполный последний ряд тоже центрируется
+		homes[i] = cube.Point3D{
+			X: (float64(col) - float64(inRow-1)/2) * step,
+			Y: (float64(row) - float64(rows-1)/2) * step,
+		}
+	}
+	return homes, size
+}
+
+// rolling сообщает, что хотя бы одна кость еще в движении.
+func (s *scene) rolling() bool {
+	for _, d := range s.dice {
+		if d.State.Rotating || d.State.Snapping {
+			return true
+		}
+	}
+	return false
+}
+
+// update продвигает анимацию всех костей на один тик. В физическом режиме кости
+// сталкиваются друг с другом, остановившаяся кость служит препятствием.
+// Возвращает true, когда остановилась последняя кость броска.
+func (s *scene) update() (allSettled bool) {
+	justSettled := false
+	for _, d := range s.dice {
+		if d.State.UpdateState() {
+			d.settled = true
+			justSettled = true
+		}
+	}
+	for i, a := range s.dice {
+		for _, b := range s.dice[i+1:] {
+			a.State.Collide(b.State)
+		}
+	}
+	if !justSettled {
+		return false
+	}
+	for _, d := range s.dice {
+		if !d.settled {
+			return false
+		}
+	}
+	return true
+}
+
+// offset возвращает смещение кости от центра экрана в пикселях.
+func (s *scene) offset(d *sceneDie) cube.Point3D {
+	if d.State.Mode == roll.ModePhysics {
+		// Положение на столе уже включает место в раскладке
+		return d.State.Position.Scale(s.scale * s.size)
+	}
+	// Прыжок задан в пикселях для кости обычного размера
+	return d.home.Scale(s.scale * s.size).Add(d.State.Position.Scale(s.size))
+}
+
+// models возвращает кости сцены для отрисовки.
+func (s *scene) models() []raster.Model {
+	models := make([]raster.Model, len(s.dice))
+	for i, d := range s.dice {
+		models[i] = raster.Model{Cube: d.Cube, Orientation: d.State.Orientation, Position: s.offset(d), Size: s.size}
+	}
+	return models
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/history"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Размеры списка очередности в пикселях экрана.
+const (
+	standupX         = 10
+	standupWidth     = 280
+	standupRowHeight = 16
+	standupRows      = 20 // Сколько строк списка видно одновременно
+)
+
+// standupOrder — режим стендапа: кость разыгрывает всех участников по очереди,
+// а на экране показывается получившийся порядок выступлений.
+type standupOrder struct {
+	drawing bool            // Розыгрыш очереди еще идет
+	fast    bool            // Броски доводятся до конца без анимации
+	order   []string        // Участники в порядке выпадения
+	entries []history.Entry // Записи журнала для каждого участника очереди
+	current int             // Индекс текущего выступающего
+}
+
+// active сообщает, что режим стендапа включен.
+func (s *standupOrder) active() bool {
+	return s.drawing || s.order != nil
+}
+
+// StartStandup заново раздает всех присутствующих на грани и разыгрывает
+// полную очередь выступлений. В режиме fast броски не анимируются.
+func (g *Game) StartStandup(fast bool) {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		log.Println("Stand-up order cannot start while the die is rolling.")
+		return
+	}
+
+	g.forgetUndo() // Очередь разыгрывается целиком, отдельные ее броски не отменяются
+	g.redeal()
+	g.standup = standupOrder{drawing: true, fast: fast, order: []string{}}
+	log.Printf("Drawing the stand-up order (fast-forward: %v).", fast)
+}
+
+// ResetCycle досрочно завершает цикл: все присутствующие возвращаются в пул,
+// грани раздаются заново, а очередь стендапа и таймер сбрасываются.
+// Сброс можно отменить, как и выбор.
+func (g *Game) ResetCycle() {
+	sm := g.StateManager
+	if sm.Rotating || sm.Snapping {
+		log.Println("The cycle cannot be reset while the die is rolling.")
+		return
+	}
+
+	g.stopTimer()
+	g.standup = standupOrder{}
+	g.pushUndo(g.snapshot())
+	g.redeal()
+	log.Printf("Cycle %d started: everyone is back in the pool.", sm.Cycle)
+}
+
+// redeal перемешивает всех присутствующих, заново раздает их на грани
+// и начинает новый цикл.
+func (g *Game) redeal() {
+	g.StateManager.Redeal()
+	if g.fairness != nil {
+		// Раздача изменила грани и пул, поэтому текущий цикл закрывается досрочно.
+		g.revealCycle()
+		g.commitCycle()
+	}
+}
+
+// updateStandup обрабатывает клавиши режима стендапа и запускает следующие броски.
+// O начинает розыгрыш очереди, F — то же без анимации, N переходит к следующему
+// выступающему, а после последнего закрывает список.
+func (g *Game) updateStandup() {
+	switch {
+	case g.pressed(config.ActionStandup):
+		g.StartStandup(false)
+	case g.pressed(config.ActionStandupFast):
+		g.StartStandup(true)
+	case g.pressed(config.ActionNext):
+		g.nextSpeaker()
+	}
+	g.advanceStandup()
+}
+
+// DrawStandupOrder разыгрывает полную очередь выступлений без анимации и окна
+// и возвращает ее. Порядок тот же, что у roll.StateManager.DrawOrder, которым
+// пользуется команда order, но выборы еще попадают в журнал, события и уведомления.
+func (g *Game) DrawStandupOrder() []string {
+	g.StartStandup(true)
+	g.advanceStandup()
+	return g.standup.order
+}
+
+// advanceStandup запускает следующие броски розыгрыша очереди. В режиме fast
+// вся очередь разыгрывается за один вызов.
+func (g *Game) advanceStandup() {
+	sm := g.StateManager
+	for g.standup.drawing && !sm.Rotating && !sm.Snapping && !sm.Shaking {
+		g.startRotation()
+		if !sm.Rotating && !sm.Snapping {
+			// Разыгрывать больше некого.
+			g.standup.drawing = false
+			sm.Shaking = false
+			break
+		}
+		if !g.standup.fast {
+			break
+		}
+		if sm.Finish() {
+			g.onSpinFinished()
+		}
+	}
+}
+
+// recordSpeaker добавляет выпавшего участника в очередь и завершает розыгрыш,
+// когда пул исчерпан и все грани в игре уже выиграли.
+func (g *Game) recordSpeaker(entry history.Entry) {
+	if !g.standup.drawing {
+		return
+	}
+	g.standup.order = append(g.standup.order, entry.Label)
+	g.standup.entries = append(g.standup.entries, entry)
+
+	if !g.StateManager.OrderDone() {
+		return
+	}
+	g.standup.drawing = false
+	log.Printf("Stand-up order is ready: %d speakers.", len(g.standup.order))
+	g.startTimer(g.standup.entries[0])
+}
+
+// nextSpeaker передает слово следующему участнику.
+func (g *Game) nextSpeaker() {
+	if !g.standup.active() {
+		return
+	}
+	if g.standup.current < len(g.standup.order) {
+		g.stopTimer()
+		g.standup.current++
+		if !g.standup.drawing && g.standup.current < len(g.standup.entries) {
+			g.startTimer(g.standup.entries[g.standup.current])
+		}
+		return
+	}
+	if !g.standup.drawing {
+		// После последнего выступающего список закрывается.
+		g.standup = standupOrder{}
+	}
+}
+
+// drawStandup рисует очередь выступлений, подсвечивая текущего выступающего.
+func (g *Game) drawStandup(screen *ebiten.Image) {
+	order := g.standup.order
+	first := max(0, min(g.standup.current-standupRows/2, len(order)-standupRows))
+	rows := min(len(order)-first, standupRows)
+	height := (rows + 1) * standupRowHeight
+	top := screen.Bounds().Dy() - height - 10
+	vector.DrawFilledRect(screen, standupX-4, float32(top-4), standupWidth+8, float32(height+8), color.RGBA{A: 0xC0}, false)
+
+	title := fmt.Sprintf("Stand-up order: %s for next speaker", g.Config.Keys.Next)
+	switch {
+	case g.standup.drawing:
+		title = fmt.Sprintf("Drawing the order... %d so far", len(order))
+	case g.standup.current >= len(order):
+		title = fmt.Sprintf("Stand-up finished: %s to close", g.Config.Keys.Next)
+	}
+	ebitenutil.DebugPrintAt(screen, title, standupX, top)
+
+	for row := 0; row < rows; row++ {
+		i := first + row
+		y := top + (row+1)*standupRowHeight
+		if i == g.standup.current {
+			vector.DrawFilledRect(screen, standupX-2, float32(y), standupWidth+4, standupRowHeight, color.RGBA{R: 0x1E, G: 0x88, B: 0xE5, A: 0xFF}, false)
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%2d. %s", i+1, order[i]), standupX, y)
+	}
+}
@@ -0,0 +1,69 @@
+//go:build !ci
+
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func allPeople(count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("person%d", i)
+	}
+	return names
+}
+
+func TestStandup_FastForward(t *testing.T) {
+	g := newAttendanceGame(t, 10)
+	g.AssetManager.SetAbsent("person3", true)
+
+	g.StartStandup(true)
+	require.NoError(t, g.Update())
+
+	assert.False(t, g.standup.drawing, "Fast-forward should draw everyone in one update")
+	want := append(allPeople(3), allPeople(10)[4:]...)
+	assert.ElementsMatch(t, want, g.standup.order, "Everyone present should speak exactly once")
+	assert.Len(t, g.recent, 9, "Every pick should be recorded")
+}
+
+func TestStandup_Animated(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	g.StartStandup(false)
+
+	for tick := 0; g.standup.drawing; tick++ {
+		require.Less(t, tick, maxTestTicks, "stand-up order should be drawn")
+		require.NoError(t, g.Update())
+	}
+	assert.ElementsMatch(t, allPeople(8), g.standup.order)
+}
+
+func TestStandup_NextSpeaker(t *testing.T) {
+	g := newAttendanceGame(t, 3)
+	g.StartStandup(true)
+	require.NoError(t, g.Update())
+	require.Len(t, g.standup.order, 3)
+
+	assert.Equal(t, 0, g.standup.current)
+	g.nextSpeaker()
+	g.nextSpeaker()
+	assert.Equal(t, 2, g.standup.current)
+	g.nextSpeaker()
+	assert.Equal(t, 3, g.standup.current, "After the last speaker the stand-up is finished")
+	assert.True(t, g.standup.active())
+	g.nextSpeaker()
+	assert.False(t, g.standup.active(), "Next after the end closes the list")
+}
+
+func TestDrawStandupOrder(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	order := g.DrawStandupOrder()
+	assert.ElementsMatch(t, allPeople(8), order, "Everyone should be drawn without running the game loop")
+
+	again := newAttendanceGame(t, 8).DrawStandupOrder()
+	assert.Equal(t, order, again, "The same seed gives the same order")
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/history"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Timebox задает ограничение времени на выступление выбранного участника.
+type Timebox struct {
+	Duration time.Duration // Время на выступление; 0 отключает таймер
+	Warning  float64       // Доля оставшегося времени, после которой кольцо желтеет
+	AutoNext bool          // По истечении времени сразу перейти к следующему участнику
+}
+
+// DefaultTimebox возвращает настройки таймера по умолчанию: таймер выключен.
+func DefaultTimebox() Timebox {
+	return Timebox{Warning: 0.25}
+}
+
+// speakerTimer отсчитывает время выступления по часам, а не по тикам игры:
+// тики замедляются, когда кадры не успевают отрисоваться или окно свернуто.
+type speakerTimer struct {
+	running bool
+	entry   history.Entry // Запись журнала о выступающем
+	started time.Time     // Начало выступления
+}
+
+// elapsed возвращает, сколько времени прошло с начала выступления.
+func (g *Game) elapsed() time.Duration {
+	return g.now().Sub(g.timer.started)
+}
+
+// startTimer начинает отсчет времени выступления участника из записи entry.
+func (g *Game) startTimer(entry history.Entry) {
+	g.stopTimer()
+	if g.Timebox.Duration <= 0 {
+		return
+	}
+	g.timer = speakerTimer{running: true, entry: entry, started: g.now()}
+}
+
+// stopTimer останавливает таймер и дописывает фактическую длительность выступления в журнал.
+func (g *Game) stopTimer() {
+	if !g.timer.running {
+		return
+	}
+	g.timer.running = false
+	entry := g.timer.entry
+	elapsed := g.elapsed()
+	entry.SpeakingSeconds = math.Round(elapsed.Seconds()*10) / 10
+	log.Printf("%s spoke for %s.", entry.Label, elapsed.Round(time.Second))
+	g.updateHistoryEntry(entry)
+}
+
+// updateTimer проверяет таймер в очередном тике. Клавиша T завершает выступление досрочно.
+// Если время вышло и включен AutoNext, слово переходит к следующему участнику.
+func (g *Game) updateTimer() {
+	if !g.timer.running {
+		return
+	}
+	if g.pressed(config.ActionStopTimer) {
+		g.stopTimer()
+		return
+	}
+
+	if g.elapsed() < g.Timebox.Duration || !g.Timebox.AutoNext {
+		return
+	}
+	if g.standup.active() {
+		g.nextSpeaker()
+	} else {
+		g.startRotation()
+	}
+}
+
+// timerColor возвращает цвет кольца: зеленый, желтый при малом остатке
+// времени и мигающий красный, когда время вышло.
+func (g *Game) timerColor(remaining float64) color.RGBA {
+	switch {
+	case remaining > g.Timebox.Warning:
+		return color.RGBA{R: 0x43, G: 0xA0, B: 0x47, A: 0xFF}
+	case remaining > 0:
+		return color.RGBA{R: 0xFB, G: 0xC0, B: 0x2D, A: 0xFF}
+	case g.elapsed()/(time.Second/2)%2 == 0:
+		return color.RGBA{R: 0xE5, G: 0x39, B: 0x35, A: 0xFF}
+	default:
+		return color.RGBA{R: 0x7F, G: 0x1C, B: 0x1A, A: 0xFF}
+	}
+}
+
+// drawTimer рисует вокруг кости кольцо, которое убывает по часовой стрелке
+// вместе с оставшимся временем, и подпись с остатком.
+func (g *Game) drawTimer(screen *ebiten.Image) {
+	remaining := 1 - g.elapsed().Seconds()/g.Timebox.Duration.Seconds()
+	clr := g.timerColor(remaining)
+
+	radius := 0.0
+	for _, v := range g.Cube.Vertices {
+		radius = math.Max(radius, v.Length())
+	}
+	radius = radius*g.Config.Cube.Scale() + 20 // Масштаб проекции рендерера и зазор до кости
+	cx := float64(screen.Bounds().Dx())/2 + g.StateManager.Position.X
+	cy := float64(screen.Bounds().Dy())/2 + g.StateManager.Position.Y
+
+	// Серая подложка показывает полный круг, поверх нее — оставшаяся дуга
+	vector.StrokeCircle(screen, float32(cx), float32(cy), float32(radius), 8, color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0x80}, true)
+	if remaining <= 0 {
+		vector.StrokeCircle(screen, float32(cx), float32(cy), float32(radius), 8, clr, true)
+	} else {
+		const segments = 120
+		sweep := remaining * 2 * math.Pi
+		n := max(1, int(math.Ceil(segments*remaining)))
+		for i := 0; i < n; i++ {
+			a0 := -math.Pi/2 + sweep*float64(i)/float64(n)
+			a1 := -math.Pi/2 + sweep*float64(i+1)/float64(n)
+			vector.StrokeLine(screen,
+				float32(cx+radius*math.Cos(a0)), float32(cy+radius*math.Sin(a0)),
+				float32(cx+radius*math.Cos(a1)), float32(cy+radius*math.Sin(a1)),
+				8, clr, true)
+		}
+	}
+
+	left := g.Timebox.Duration - g.elapsed()
+	sign := ""
+	if left < 0 {
+		sign, left = "+", -left
+	}
+	left = left.Round(time.Second)
+	label := fmt.Sprintf("%s  %s%d:%02d  (%s to stop)", g.timer.entry.Label, sign, int(left.Minutes()), int(left.Seconds())%60, g.Config.Keys.StopTimer)
+	ebitenutil.DebugPrintAt(screen, label, int(cx)-len(label)*3, int(cy+radius)+12)
+}
@@ -0,0 +1,103 @@
+//go:build !ci
+
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tickSeconds продвигает игру и ее часы на заданное число секунд.
+func tickSeconds(t *testing.T, g *Game, seconds int) {
+	now := g.now()
+	g.now = func() time.Time { return now }
+	for i := 0; i < seconds*ebiten.DefaultTPS; i++ {
+		now = now.Add(time.Second / ebiten.DefaultTPS)
+		require.NoError(t, g.Update())
+	}
+}
+
+func TestTimer_RecordsSpeakingTime(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	g.EnableHistory(store)
+	g.Timebox.Duration = time.Minute
+
+	spin(t, g)
+	require.True(t, g.timer.running, "Timer should start when a face wins")
+	tickSeconds(t, g, 3)
+
+	spin(t, g) // Следующий бросок завершает выступление предыдущего
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.InDelta(t, 3.0, entries[0].SpeakingSeconds, 0.1)
+	assert.Zero(t, entries[1].SpeakingSeconds, "The current speaker is still talking")
+	assert.Equal(t, entries[0].SpeakingSeconds, g.recent[0].SpeakingSeconds, "On-screen history should show the duration too")
+}
+
+func TestTimer_WallClock(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	g.Timebox = Timebox{Duration: 2 * time.Second, Warning: 0.25, AutoNext: true}
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	spin(t, g)
+	// Кадры тормозят: за три секунды прошло всего несколько тиков
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		require.NoError(t, g.Update())
+	}
+	assert.False(t, g.timer.running, "Time runs out by the clock, not by the number of ticks")
+	assert.InDelta(t, 2.0, g.recent[0].SpeakingSeconds, 0.1)
+}
+
+func TestTimer_Disabled(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	spin(t, g)
+	assert.False(t, g.timer.running)
+}
+
+func TestTimer_AutoNext(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	g.Timebox = Timebox{Duration: 2 * time.Second, Warning: 0.25, AutoNext: true}
+
+	spin(t, g)
+	tickSeconds(t, g, 2)
+	assert.True(t, g.StateManager.Rotating, "Next spin should start when time runs out")
+	assert.False(t, g.timer.running)
+}
+
+func TestTimer_Colors(t *testing.T) {
+	g := newAttendanceGame(t, 6)
+	green, yellow := g.timerColor(0.8), g.timerColor(0.1)
+	assert.NotEqual(t, green, yellow)
+
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	g.timer.started = now
+	red := g.timerColor(-0.1)
+	g.timer.started = now.Add(-time.Second / 2)
+	assert.NotEqual(t, red, g.timerColor(-0.1), "Overtime should blink")
+}
+
+func TestTimer_Standup(t *testing.T) {
+	g := newAttendanceGame(t, 3)
+	g.Timebox = Timebox{Duration: time.Second, Warning: 0.25, AutoNext: true}
+
+	g.StartStandup(true)
+	require.NoError(t, g.Update())
+	require.True(t, g.timer.running, "Timer should start for the first speaker")
+	assert.Equal(t, g.standup.order[0], g.timer.entry.Label)
+
+	tickSeconds(t, g, 1)
+	assert.Equal(t, 1, g.standup.current, "Time out passes the word in the stand-up order")
+	assert.Equal(t, g.standup.order[1], g.timer.entry.Label)
+	assert.InDelta(t, 1.0, g.recent[len(g.recent)-3].SpeakingSeconds, 0.1)
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"image"
+	"log"
+	"slices"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+)
+
+// undoLimit — сколько последних выборов и сбросов цикла можно отменить.
+const undoLimit = 20
+
+// drawSnapshot — состояние розыгрыша между бросками: кто на гранях, кто уже
+// выиграл и кто остался в пуле.
+type drawSnapshot struct {
+	textures   []image.Image
+	isGrey     []bool
+	isWinner   []bool
+	pool       []image.Image
+	lastWinner int
+	cycle      int
+	entry      *history.Entry // Запись журнала о выборе, сделанном после снимка, если он был
+}
+
+// undoHistory хранит снимки для отмены и повтора.
+type undoHistory struct {
+	undo    []drawSnapshot
+	redo    []drawSnapshot
+	pending bool // Идет бросок, снимок перед которым лежит последним в undo
+}
+
+// snapshot запоминает текущее состояние розыгрыша.
+func (g *Game) snapshot() drawSnapshot {
+	sm := g.StateManager
+	s := drawSnapshot{
+		textures:   make([]image.Image, len(g.Cube.Faces)),
+		isGrey:     slices.Clone(sm.IsGrey),
+		isWinner:   slices.Clone(sm.IsWinner),
+		pool:       slices.Clone(g.AssetManager.AvailableTextures),
+		lastWinner: sm.LastWinnerIndex,
+		cycle:      sm.Cycle,
+	}
+	for i, face := range g.Cube.Faces {
+		s.textures[i] = face.Texture
+	}
+	return s
+}
+
+// restore возвращает розыгрыш в состояние s.
+func (g *Game) restore(s drawSnapshot) {
+	sm := g.StateManager
+	for i := range g.Cube.Faces {
+		g.Cube.Faces[i].Texture = s.textures[i]
+	}
+	copy(sm.IsGrey, s.isGrey)
+	copy(sm.IsWinner, s.isWinner)
+	g.AssetManager.AvailableTextures = slices.Clone(s.pool)
+	sm.LastWinnerIndex = s.lastWinner
+	sm.Cycle = s.cycle
+}
+
+// pushUndo запоминает состояние s перед выбором или сбросом цикла. Новое действие
+// делает отмененное ранее неповторимым.
+func (g *Game) pushUndo(s drawSnapshot) {
+	u := &g.undo
+	u.undo = append(u.undo, s)
+	if len(u.undo) > undoLimit {
+		u.undo = slices.Delete(u.undo, 0, len(u.undo)-undoLimit)
+	}
+	u.redo = nil
+	u.pending = false
+}
+
+// recordUndoEntry связывает запись журнала с только что завершившимся броском,
+// чтобы отмена удалила ее из журнала.
+func (g *Game) recordUndoEntry(entry history.Entry) {
+	u := &g.undo
+	if u.pending {
+		u.undo[len(u.undo)-1].entry = &entry
+		u.pending = false
+	}
+}
+
+// forgetUndo очищает отмену и повтор. Вызывается, когда состав участников
+// меняется иначе, чем броском: старые снимки вернули бы на кость отсутствующих.
+func (g *Game) forgetUndo() {
+	g.undo = undoHistory{}
+}
+
+// Undo отменяет последний выбор или сброс цикла: грани, отметки выигравших,
+// серые грани и пул возвращаются как были, а запись о выборе удаляется из журнала.
+func (g *Game) Undo() {
+	if !g.canUndo() {
+		return
+	}
+	u := &g.undo
+	if len(u.undo) == 0 {
+		log.Println("Nothing to undo.")
+		return
+	}
+	prev := u.undo[len(u.undo)-1]
+	u.undo = u.undo[:len(u.undo)-1]
+	current := g.snapshot()
+	current.entry = prev.entry
+	u.redo = append(u.redo, current)
+
+	g.stopTimer()
+	g.restore(prev)
+	if prev.entry != nil {
+		g.removeHistoryEntry(*prev.entry)
+		log.Printf("Undone: %s is back in the draw.", prev.entry.Label)
+	} else {
+		log.Printf("Undone: cycle %d restored.", prev.cycle)
+	}
+	g.afterUndo()
+}
+
+// Redo повторяет то, что было отменено последним.
+func (g *Game) Redo() {
+	if !g.canUndo() {
+		return
+	}
+	u := &g.undo
+	if len(u.redo) == 0 {
+		log.Println("Nothing to redo.")
+		return
+	}
+	next := u.redo[len(u.redo)-1]
+	u.redo = u.redo[:len(u.redo)-1]
+	current := g.snapshot()
+	current.entry = next.entry
+	u.undo = append(u.undo, current)
+
+	g.stopTimer()
+	g.restore(next)
+	if next.entry != nil {
+		g.addHistoryEntry(*next.entry)
+		log.Printf("Redone: %s is picked again.", next.entry.Label)
+	} else {
+		log.Printf("Redone: cycle %d restored.", next.cycle)
+	}
+	g.afterUndo()
+}
+
+// canUndo проверяет, что отмена сейчас возможна. Во время броска она подменила бы
+// выигравшую грань, а очередь стендапа разыгрывается и отменяется только целиком.
+func (g *Game) canUndo() bool {
+	sm := g.StateManager
+	switch {
+	case sm.Rotating || sm.Snapping:
+		log.Println("Nothing can be undone while the die is rolling.")
+		return false
+	case g.standup.active():
+		log.Println("Undo is not available during the stand-up order.")
+		return false
+	}
+	return true
+}
+
+// afterUndo приводит остальное состояние игры в соответствие с восстановленным розыгрышем.
+func (g *Game) afterUndo() {
+	sm := g.StateManager
+	sm.WinningFaceIndex = -1
+	sm.NeedsToRetireFace = sm.LastWinnerIndex >= 0
+	if sm.LastWinnerIndex >= 0 {
+		// Кость сразу показывает восстановленного победителя; доворот анимацией
+		// закончился бы как бросок и снова попал бы в журнал.
+		sm.TargetOrientation = g.Cube.TargetOrientation(sm.LastWinnerIndex)
+		sm.Orientation = sm.TargetOrientation
+	}
+	if g.fairness != nil {
+		// Состояние цикла изменилось не броском, поэтому он закрывается досрочно.
+		g.revealCycle()
+		g.commitCycle()
+	}
+}
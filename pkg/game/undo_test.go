@@ -0,0 +1,126 @@
+//go:build !ci
+
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drawLabels — состояние розыгрыша, где текстуры заменены именами, чтобы его можно было сравнивать.
+type drawLabels struct {
+	Faces, Pool       []string
+	IsGrey, IsWinner  []bool
+	LastWinner, Cycle int
+}
+
+func labelsOf(g *Game) drawLabels {
+	s := g.snapshot()
+	d := drawLabels{IsGrey: s.isGrey, IsWinner: s.isWinner, LastWinner: s.lastWinner, Cycle: s.cycle}
+	for _, tex := range s.textures {
+		d.Faces = append(d.Faces, g.AssetManager.LabelOf(tex))
+	}
+	for _, tex := range s.pool {
+		d.Pool = append(d.Pool, g.AssetManager.LabelOf(tex))
+	}
+	return d
+}
+
+func TestUndo_Pick(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	g.EnableHistory(store)
+
+	// Седьмой бросок начинает новый цикл и меняет выигравшие грани на участников из пула
+	for i := 0; i < 6; i++ {
+		spin(t, g)
+	}
+	before := labelsOf(g)
+	spin(t, g)
+	after := labelsOf(g)
+	require.NotEqual(t, before.Faces, after.Faces)
+
+	g.Undo()
+	assert.Equal(t, before, labelsOf(g), "Undo should restore faces, winners, grey faces and the pool")
+	entries, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, entries, 6, "The undone pick should leave the history")
+	assert.Len(t, g.recent, 6)
+
+	g.Redo()
+	assert.Equal(t, after, labelsOf(g))
+	entries, err = store.Load()
+	require.NoError(t, err)
+	assert.Len(t, entries, 7, "Redo should put the pick back into the history")
+
+	// Новый бросок после отмены делает повтор невозможным
+	g.Undo()
+	spin(t, g)
+	picked := labelsOf(g)
+	g.Redo()
+	assert.Equal(t, picked, labelsOf(g), "Nothing to redo after a new pick")
+}
+
+func TestUndo_ResetCycle(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	spin(t, g)
+	spin(t, g)
+	before := labelsOf(g)
+
+	g.ResetCycle()
+	reset := labelsOf(g)
+	g.Undo()
+	assert.Equal(t, before, labelsOf(g), "A cycle reset can be undone")
+	g.Redo()
+	assert.Equal(t, reset, labelsOf(g))
+
+	g.Undo()
+	g.Undo()
+	g.Undo()
+	assert.NotContains(t, labelsOf(g).IsWinner, true, "All picks are undone")
+	g.Undo() // Отменять больше нечего
+	assert.NotContains(t, labelsOf(g).IsWinner, true)
+}
+
+func TestUndo_Policy(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	policy := selection.NewWeighted(nil)
+	g.StateManager.Policy = policy
+
+	spin(t, g)
+	label := g.StateManager.LastWinner()
+	assert.InDelta(t, policy.MinDays, policy.Weight(label), 1e-6)
+
+	g.Undo()
+	assert.InDelta(t, policy.MaxDays, policy.Weight(label), 1e-6, "The undone pick is forgotten by the policy")
+	g.Redo()
+	assert.InDelta(t, policy.MinDays, policy.Weight(label), 1e-6)
+	g.Undo()
+	assert.InDelta(t, policy.MaxDays, policy.Weight(label), 1e-6, "Redo observes the pick only once")
+}
+
+func TestUndo_Refused(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	spin(t, g)
+	picked := labelsOf(g)
+
+	g.StateManager.Rotating = true
+	g.Undo()
+	assert.Equal(t, picked, labelsOf(g), "Undo should wait until the die stops")
+	g.StateManager.Rotating = false
+
+	g.setAbsent(g.AssetManager.LabelOf(g.AssetManager.AllTextures[0]), true)
+	absent := labelsOf(g)
+	g.Undo()
+	assert.Equal(t, absent, labelsOf(g), "Attendance changes cannot be undone past")
+
+	g.DrawStandupOrder()
+	standup := labelsOf(g)
+	g.Undo()
+	assert.Equal(t, standup, labelsOf(g), "The stand-up order is drawn as a whole")
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/record"
+	"github.com/olegshirko/dice_roller/pkg/webhook"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// EnableWebhook включает уведомления о выбранных участниках через n.
+func (g *Game) EnableWebhook(n *webhook.Notifier) {
+	g.webhook = n
+}
+
+// webhookWantsGIF сообщает, что уведомлению нужна анимация броска, поэтому
+// каждый бросок записывается.
+func (g *Game) webhookWantsGIF() bool {
+	return g.webhook != nil && g.webhook.Snapshot() == webhook.SnapshotGIF && g.Record.Options.Format == record.GIF
+}
+
+// notifyWinner отправляет уведомление о результате entry. Снимок кадра
+// прикладывается после отрисовки следующего кадра, анимация — после того, как
+// запись броска закодирована. Броски без анимации отправляются без изображения.
+func (g *Game) notifyWinner(entry history.Entry) {
+	if g.webhook == nil {
+		return
+	}
+	p := webhook.Payload{Label: entry.Label, Time: entry.Time, Cycle: entry.Cycle}
+	fast := g.standup.drawing && g.standup.fast
+	switch {
+	case fast:
+		g.webhook.Notify(p)
+	case g.webhook.Snapshot() == webhook.SnapshotPNG:
+		g.pendingNotice = &p
+	case g.webhook.Snapshot() == webhook.SnapshotGIF && g.recording.recorder != nil && g.Record.Options.Format == record.GIF:
+		g.recording.notice = &p
+	default:
+		g.webhook.Notify(p)
+	}
+}
+
+// captureNotice снимает кадр для уведомления, ожидающего снимка, и отправляет
+// уведомление. PNG кодируется в фоне, чтобы не задерживать следующий кадр.
+func (g *Game) captureNotice(screen *ebiten.Image) {
+	if g.pendingNotice == nil {
+		return
+	}
+	p := *g.pendingNotice
+	g.pendingNotice = nil
+	b := screen.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	screen.ReadPixels(img.Pix)
+
+	send := g.webhook.Pending()
+	go func() {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			log.Printf("Could not encode the webhook snapshot: %v", err)
+		} else {
+			p.Snapshot, p.SnapshotType = buf.Bytes(), "image/png"
+		}
+		send(p)
+	}()
+}
+
+// notifyRecorded отправляет через send уведомление с анимацией из сохраненной
+// записи path. Если запись не удалась, уведомление уходит без изображения.
+func notifyRecorded(send func(webhook.Payload), p webhook.Payload, path string, saved bool) {
+	if saved {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Could not attach recording %s to the webhook: %v", path, err)
+		} else {
+			p.Snapshot, p.SnapshotType = data, "image/gif"
+		}
+	}
+	send(p)
+}
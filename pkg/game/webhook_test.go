@@ -0,0 +1,59 @@
+//go:build !ci
+
+package game
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableDryRunWebhook включает уведомления без отправки и возвращает их лог.
+func enableDryRunWebhook(t *testing.T, g *Game, snapshot webhook.Snapshot) (*webhook.Notifier, *bytes.Buffer) {
+	t.Helper()
+	n, err := webhook.New(webhook.Options{URL: "http://example.com/hook", Snapshot: snapshot, DryRun: true})
+	require.NoError(t, err)
+	g.EnableWebhook(n)
+	var out bytes.Buffer
+	w := log.Writer()
+	t.Cleanup(func() { log.SetOutput(w) })
+	log.SetOutput(&out)
+	return n, &out
+}
+
+func TestWebhook_Winner(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	n, out := enableDryRunWebhook(t, g, webhook.NoSnapshot)
+
+	spin(t, g)
+	n.Wait()
+	assert.Contains(t, out.String(), "POST http://example.com/hook")
+	assert.Contains(t, out.String(), `"label": "`+g.status().LastWinner+`"`)
+	assert.Nil(t, g.pendingNotice)
+}
+
+func TestWebhook_Snapshots(t *testing.T) {
+	g := newAttendanceGame(t, 8)
+	g.ScreenshotDir = t.TempDir()
+
+	enableDryRunWebhook(t, g, webhook.SnapshotPNG)
+	spin(t, g)
+	require.NotNil(t, g.pendingNotice, "The PNG snapshot is taken from the next frame")
+	assert.Equal(t, g.status().LastWinner, g.pendingNotice.Label)
+	assert.Nil(t, g.recording.recorder, "A PNG snapshot does not need a recording")
+
+	g.pendingNotice = nil
+	enableDryRunWebhook(t, g, webhook.SnapshotGIF)
+	g.startRotation()
+	require.NotNil(t, g.recording.recorder, "A GIF snapshot records every roll")
+	require.True(t, g.StateManager.Finish())
+	g.onSpinFinished()
+	require.NotNil(t, g.recording.notice, "The notification waits for the recording")
+	assert.Equal(t, g.status().LastWinner, g.recording.notice.Label)
+	assert.Nil(t, g.pendingNotice)
+	g.recording.recorder.Close()
+}
@@ -1,119 +1,157 @@
 package graphics
 
 import (
-	"github.com/olegshirko/dice_roller/pkg/config"
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/raster"
+	"image"
 	"image/color"
-	"math"
-	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 type Renderer struct {
-	// Пока рендерер не имеет состояния, но может получить его в будущем (например, кэши).
+	Scale  float64       // Во сколько раз кость на экране больше модели
+	Help   string        // Подсказка с клавишами в левом верхнем углу
+	Camera raster.Camera // Проекция сцены на экран
+	Light  *raster.Light // Освещение граней; nil — грани рисуются без затенения
+
+	white    *ebiten.Image                    // Белая текстура для блика
+	textures map[image.Image]*uploadedTexture // Текстуры граней, загруженные на видеокарту
+	frame    int                              // Номер кадра для вытеснения текстур
+}
+
+// textureTTL — сколько кадров текстура хранится на видеокарте, не попадая на экран.
+const textureTTL = 600
+
+// uploadedTexture — изображение грани на видеокарте.
+type uploadedTexture struct {
+	image *ebiten.Image
+	used  int // Кадр, в котором текстура рисовалась последней
 }
 
 func NewRenderer() *Renderer {
-	return &Renderer{}
+	return &Renderer{
+		Scale: 1.5,
+		Help:  "Press 'S' to spin, 'F1' for help",
+	}
 }
 
-// DrawCube отрисовывает куб на экране.
-func (r *Renderer) DrawCube(screen *ebiten.Image, c *cube.Cube, angleX, angleY, angleZ, offsetY float64) {
-	screen.Fill(color.Transparent)
-	ebitenutil.DebugPrint(screen, "Press 'L' to load textures, 'S' to spin")
+// DrawCube отрисовывает кость на экране, смещая ее на position от центра экрана.
+func (r *Renderer) DrawCube(screen *ebiten.Image, c *cube.Polyhedron, orientation cube.Quaternion, position cube.Point3D) {
+	r.DrawCubes(screen, []raster.Model{{Cube: c, Orientation: orientation, Position: position}})
+}
 
-	cosX, sinX := math.Cos(angleX), math.Sin(angleX)
-	cosY, sinY := math.Cos(angleY), math.Sin(angleY)
-	cosZ, sinZ := math.Cos(angleZ), math.Sin(angleZ)
+// DrawCubes отрисовывает несколько костей. Кости рисуются от дальних к ближним,
+// поэтому при перекрытии ближняя оказывается сверху.
+func (r *Renderer) DrawCubes(screen *ebiten.Image, models []raster.Model) {
+	screen.Fill(color.Transparent)
+	ebitenutil.DebugPrint(screen, r.Help)
 
-	type RotatedPoint struct {
-		cube.Point3D
-		ProjX, ProjY float64
+	for _, i := range raster.BackToFront(models) {
+		r.drawModel(screen, models[i])
 	}
-	rotatedPoints := make([]RotatedPoint, len(c.Vertices))
-
-	for i, v := range c.Vertices {
-		// Вращение вокруг оси Y
-		rotatedY := cube.Point3D{
-			X: v.X*cosY - v.Z*sinY,
-			Y: v.Y,
-			Z: v.X*sinY + v.Z*cosY,
-		}
-		// Вращение вокруг оси X
-		rotatedX := cube.Point3D{
-			X: rotatedY.X,
-			Y: rotatedY.Y*cosX - rotatedY.Z*sinX,
-			Z: rotatedY.Y*sinX + rotatedY.Z*cosX,
-		}
-		// Финальный доворот для выравнивания
-		finalRotated := cube.Point3D{
-			X: rotatedX.X*cosZ - rotatedX.Y*sinZ,
-			Y: rotatedX.X*sinZ + rotatedX.Y*cosZ,
-			Z: rotatedX.Z,
-		}
+	r.evictTextures()
+}
 
-		scale := 1.5
-		rotatedPoints[i] = RotatedPoint{
-			Point3D: finalRotated,
-			ProjX:   finalRotated.X*scale + config.ScreenWidth/2,
-			ProjY:   finalRotated.Y*scale + config.ScreenHeight/2 + offsetY,
+// upload возвращает текстуру img на видеокарте. Изображение загружается при
+// первой отрисовке и дальше берется из кэша.
+func (r *Renderer) upload(img image.Image) *ebiten.Image {
+	if tex, ok := img.(*ebiten.Image); ok {
+		return tex
+	}
+	t, ok := r.textures[img]
+	if !ok {
+		if r.textures == nil {
+			r.textures = map[image.Image]*uploadedTexture{}
 		}
+		t = &uploadedTexture{image: ebiten.NewImageFromImage(img)}
+		r.textures[img] = t
 	}
+	t.used = r.frame
+	return t.image
+}
 
-	type faceToSort struct {
-		face     cube.Face
-		averageZ float64
-	}
-	sortedFaces := make([]faceToSort, 0, len(c.Faces))
-
-	for _, face := range c.Faces {
-		avgZ := (rotatedPoints[face.Indices[0]].Z +
-			rotatedPoints[face.Indices[1]].Z +
-			rotatedPoints[face.Indices[2]].Z +
-			rotatedPoints[face.Indices[3]].Z) / 4.0
-
-		// Back-face culling
-		v0 := rotatedPoints[face.Indices[0]].Point3D
-		v1 := rotatedPoints[face.Indices[1]].Point3D
-		v2 := rotatedPoints[face.Indices[2]].Point3D
-		u := cube.Point3D{X: v1.X - v0.X, Y: v1.Y - v0.Y, Z: v1.Z - v0.Z}
-		v := cube.Point3D{X: v2.X - v0.X, Y: v2.Y - v0.Y, Z: v2.Z - v0.Z}
-		normal := cube.Point3D{
-			X: u.Y*v.Z - u.Z*v.Y,
-			Y: u.Z*v.X - u.X*v.Z,
-			Z: u.X*v.Y - u.Y*v.X,
-		}
-		if normal.Z > 0 {
-			sortedFaces = append(sortedFaces, faceToSort{face: face, averageZ: avgZ})
+// evictTextures освобождает текстуры, которые давно не рисовались: например,
+// участников, замененных загрузкой других изображений.
+func (r *Renderer) evictTextures() {
+	r.frame++
+	for img, t := range r.textures {
+		if r.frame-t.used > textureTTL {
+			t.image.Deallocate()
+			delete(r.textures, img)
 		}
 	}
+}
 
-	// Сортировка граней для правильного отображения (Painter's algorithm)
-	sort.Slice(sortedFaces, func(i, j int) bool {
-		return sortedFaces[i].averageZ < sortedFaces[j].averageZ
-	})
+// drawModel отрисовывает одну кость, не очищая экран.
+func (r *Renderer) drawModel(screen *ebiten.Image, m raster.Model) {
+	proj := r.Camera.Projection(float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy()))
+	points, sortedFaces := raster.VisibleFaces(m, r.Scale, proj)
 
+	n := 1
+	if proj.Perspective {
+		n = subdivisions
+	}
+	specular := r.Light != nil && r.Light.Shiny()
+	if specular && r.white == nil {
+		r.white = ebiten.NewImage(3, 3)
+		r.white.Fill(color.White)
+	}
 	for _, fts := range sortedFaces {
-		face := fts.face
-		p0 := rotatedPoints[face.Indices[0]]
-		p1 := rotatedPoints[face.Indices[1]]
-		p2 := rotatedPoints[face.Indices[2]]
-		p3 := rotatedPoints[face.Indices[3]]
-
-		texWidth, texHeight := face.Texture.Size()
+		face := fts.Face
+		texture := r.upload(face.Texture)
+		texWidth, texHeight := texture.Bounds().Dx(), texture.Bounds().Dy()
+		shade := [3]float32{1, 1, 1}
+		if r.Light != nil {
+			shade = r.Light.Shade(fts.Normal)
+		}
+		corner := func(i int) vertex {
+			return vertex{
+				pos: points[face.Indices[i]],
+				u:   face.UVs[i][0] * float32(texWidth),
+				v:   face.UVs[i][1] * float32(texHeight),
+			}
+		}
 
-		v0 := ebiten.Vertex{DstX: float32(p0.ProjX), DstY: float32(p0.ProjY), SrcX: face.UVs[0][0] * float32(texWidth), SrcY: face.UVs[0][1] * float32(texHeight), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1}
-		v1 := ebiten.Vertex{DstX: float32(p1.ProjX), DstY: float32(p1.ProjY), SrcX: face.UVs[1][0] * float32(texWidth), SrcY: face.UVs[1][1] * float32(texHeight), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1}
-		v2 := ebiten.Vertex{DstX: float32(p2.ProjX), DstY: float32(p2.ProjY), SrcX: face.UVs[2][0] * float32(texWidth), SrcY: face.UVs[2][1] * float32(texHeight), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1}
-		v3 := ebiten.Vertex{DstX: float32(p3.ProjX), DstY: float32(p3.ProjY), SrcX: face.UVs[3][0] * float32(texWidth), SrcY: face.UVs[3][1] * float32(texHeight), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1}
+		// Грань - выпуклый многоугольник, поэтому разбиваем ее на треугольники веером.
+		var vertices, highlights []ebiten.Vertex
+		var indices []uint16
+		shiny := false
+		for i := 1; i < len(face.Indices)-1; i++ {
+			part, partIndices := subdivide(corner(0), corner(i), corner(i+1), n)
+			base := uint16(len(vertices))
+			for _, v := range part {
+				x, y := proj.Project(v.pos)
+				vertices = append(vertices, ebiten.Vertex{
+					DstX: float32(x), DstY: float32(y),
+					SrcX: v.u, SrcY: v.v,
+					ColorR: shade[0], ColorG: shade[1], ColorB: shade[2], ColorA: 1,
+				})
+				if specular {
+					// Блик зависит от направления на камеру, поэтому считается в каждой вершине
+					h := r.Light.Highlight(fts.Normal, proj.ToEye(v.pos))
+					shiny = shiny || max(h[0], h[1], h[2]) > 1.0/255
+					highlights = append(highlights, ebiten.Vertex{
+						DstX: float32(x), DstY: float32(y),
+						SrcX: 1, SrcY: 1,
+						ColorR: h[0], ColorG: h[1], ColorB: h[2], ColorA: 1,
+					})
+				}
+			}
+			for _, idx := range partIndices {
+				indices = append(indices, base+idx)
+			}
+		}
 
 		op := &ebiten.DrawTrianglesOptions{
 			FillRule: ebiten.FillAll,
 		}
-
-		screen.DrawTriangles([]ebiten.Vertex{v0, v1, v2}, []uint16{0, 1, 2}, face.Texture, op)
-		screen.DrawTriangles([]ebiten.Vertex{v0, v2, v3}, []uint16{0, 1, 2}, face.Texture, op)
+		screen.DrawTriangles(vertices, indices, texture, op)
+		if shiny {
+			// Блик добавляется к цвету грани поверх текстуры
+			op.Blend = ebiten.BlendLighter
+			screen.DrawTriangles(highlights, indices, r.white, op)
+		}
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/raster"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,6 +38,44 @@ func TestDrawCube(t *testing.T) {
 
 	// 5. Вызываем DrawCube и проверяем, что паники не произошло
 	assert.NotPanics(t, func() {
-		renderer.DrawCube(screen, c, 0, 0, 0, 0)
+		renderer.DrawCube(screen, c, cube.IdentityQuaternion(), cube.Point3D{})
 	}, "DrawCube should not panic")
-}
\ No newline at end of file
+}
+
+func TestDrawCubes(t *testing.T) {
+	// Как и TestDrawCube, проверяем только, что сцена из нескольких костей
+	// разного размера и глубины рисуется без паники.
+	renderer := NewRenderer()
+	screen := ebiten.NewImage(200, 100)
+
+	models := make([]raster.Model, 3)
+	for i := range models {
+		c := cube.NewCube()
+		for j := range c.Faces {
+			c.Faces[j].Texture = ebiten.NewImage(1, 1)
+		}
+		models[i] = raster.Model{Cube: c, Orientation: cube.IdentityQuaternion(), Position: cube.Point3D{X: float64(60*i - 60), Z: float64(i)}, Size: 0.2}
+	}
+
+	assert.NotPanics(t, func() {
+		renderer.DrawCubes(screen, models)
+	}, "DrawCubes should not panic")
+
+	// С перспективой, в том числе когда кость оказывается за камерой
+	renderer.Camera = raster.Camera{FOV: 60}
+	models[0].Position.Z = -1000
+	assert.NotPanics(t, func() {
+		renderer.DrawCubes(screen, models)
+	}, "DrawCubes should not panic with a perspective camera")
+
+	renderer.Light = &raster.Light{
+		Direction: cube.Point3D{Z: -2},
+		Ambient:   [3]float64{0.2, 0.2, 0.2},
+		Diffuse:   [3]float64{0.6, 0.6, 0.9},
+		Specular:  [3]float64{0.5, 0.5, 0.5},
+		Shininess: 16,
+	}
+	assert.NotPanics(t, func() {
+		renderer.DrawCubes(screen, models)
+	}, "DrawCubes should not panic with lighting")
+}
@@ -0,0 +1,45 @@
+package graphics
+
+import (
+	"image"
+
+	"github.com/olegshirko/dice_roller/pkg/raster"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// SoftwareRenderer выводит на экран кадры программного рендерера raster.Renderer:
+// кость рисуется на процессоре точно так же, как в тестах с эталонными изображениями.
+type SoftwareRenderer struct {
+	*raster.Renderer
+	Help string // Подсказка с клавишами в левом верхнем углу
+
+	frame *image.RGBA // Кадр DrawCubes
+}
+
+// NewSoftwareRenderer создает программный рендерер для окна.
+func NewSoftwareRenderer() *SoftwareRenderer {
+	return &SoftwareRenderer{
+		Renderer: raster.NewRenderer(),
+		Help:     "Press 'S' to spin, 'F1' for help",
+	}
+}
+
+// Software возвращает программный рендерер с теми же настройками, что у r.
+func (r *Renderer) Software() *SoftwareRenderer {
+	s := NewSoftwareRenderer()
+	s.Scale, s.Help, s.Camera, s.Light = r.Scale, r.Help, r.Camera, r.Light
+	return s
+}
+
+// DrawCubes рисует кости на процессоре и копирует кадр на экран.
+func (r *SoftwareRenderer) DrawCubes(screen *ebiten.Image, models []raster.Model) {
+	b := screen.Bounds()
+	if r.frame == nil || r.frame.Rect.Dx() != b.Dx() || r.frame.Rect.Dy() != b.Dy() {
+		r.frame = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	}
+	r.Render(r.frame, models)
+	screen.WritePixels(r.frame.Pix)
+	ebitenutil.DebugPrint(screen, r.Help)
+}
@@ -0,0 +1,44 @@
+package graphics
+
+import "github.com/olegshirko/dice_roller/pkg/cube"
+
+// subdivisions — на сколько частей делится сторона треугольника грани при перспективе.
+const subdivisions = 8
+
+// vertex — вершина грани в сцене вместе с координатами текстуры.
+type vertex struct {
+	pos  cube.Point3D
+	u, v float32
+}
+
+// subdivide делит треугольник abc на n×n одинаковых треугольников. Отрисовка
+// треугольника растягивает текстуру линейно по экрану, а в перспективе она
+// должна сжиматься с глубиной; на мелких треугольниках эта разница незаметна.
+func subdivide(a, b, c vertex, n int) (vertices []vertex, indices []uint16) {
+	at := func(i, j int) vertex {
+		s, t := float64(i)/float64(n), float64(j)/float64(n)
+		return vertex{
+			pos: a.pos.Add(b.pos.Sub(a.pos).Scale(s)).Add(c.pos.Sub(a.pos).Scale(t)),
+			u:   a.u + (b.u-a.u)*float32(s) + (c.u-a.u)*float32(t),
+			v:   a.v + (b.v-a.v)*float32(s) + (c.v-a.v)*float32(t),
+		}
+	}
+	// Вершины идут строками по j; в строке j их n+1-j
+	row := make([]int, n+2)
+	for j := 0; j <= n; j++ {
+		row[j+1] = row[j] + n + 1 - j
+		for i := 0; i <= n-j; i++ {
+			vertices = append(vertices, at(i, j))
+		}
+	}
+	index := func(i, j int) uint16 { return uint16(row[j] + i) }
+	for j := 0; j < n; j++ {
+		for i := 0; i < n-j; i++ {
+			indices = append(indices, index(i, j), index(i+1, j), index(i, j+1))
+			if i+j+1 < n {
+				indices = append(indices, index(i+1, j), index(i+1, j+1), index(i, j+1))
+			}
+		}
+	}
+	return vertices, indices
+}
@@ -0,0 +1,35 @@
+//go:build !ci
+
+package graphics
+
+import (
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubdivide(t *testing.T) {
+	a := vertex{pos: cube.Point3D{}, u: 0, v: 0}
+	b := vertex{pos: cube.Point3D{X: 30}, u: 3, v: 0}
+	c := vertex{pos: cube.Point3D{Y: 30, Z: 30}, u: 0, v: 3}
+
+	vertices, indices := subdivide(a, b, c, 1)
+	assert.Equal(t, []vertex{a, b, c}, vertices)
+	assert.Equal(t, []uint16{0, 1, 2}, indices)
+
+	vertices, indices = subdivide(a, b, c, 3)
+	require.Len(t, vertices, 10)
+	require.Len(t, indices, 3*9, "n×n triangles")
+	for _, i := range indices {
+		assert.Less(t, int(i), len(vertices))
+	}
+	assert.Contains(t, vertices, b)
+	assert.Contains(t, vertices, c)
+	for _, v := range vertices {
+		// Координаты текстуры следуют за положением в сцене, а не на экране
+		assert.InDelta(t, v.pos.X/10, float64(v.u), 1e-6)
+		assert.InDelta(t, v.pos.Y/10, float64(v.v), 1e-6)
+	}
+}
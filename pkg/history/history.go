@@ -0,0 +1,197 @@
+// Package history хранит журнал результатов розыгрыша: кто и когда был выбран.
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Entry — одна запись журнала.
+type Entry struct {
+	Time  time.Time `json:"time"`
+	Label string    `json:"label"`          // Имя выбранного участника
+	Cycle int       `json:"cycle"`          // Номер цикла розыгрыша, начиная с 1
+	Seed  *uint64   `json:"seed,omitempty"` // Зерно генератора, если оно известно
+	// Сколько участник фактически говорил, если время выступления засекалось.
+	SpeakingSeconds float64 `json:"speaking_seconds,omitempty"`
+}
+
+// Store — журнал в файле формата JSON Lines: по одной записи на строку.
+// Записи только дописываются в конец, поэтому файл не теряется при сбое.
+type Store struct {
+	Path string
+}
+
+// DefaultPath возвращает путь к журналу в пользовательском каталоге настроек.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("Could not find user config directory: %v. History will not be saved.", err)
+		return ""
+	}
+	return filepath.Join(dir, "dice_roller", "history.jsonl")
+}
+
+// NewStore создает журнал в файле path.
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// Append дописывает запись в конец журнала.
+func (s *Store) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Update заменяет последнюю запись с тем же временем и именем, например чтобы
+// дописать длительность выступления. Файл перезаписывается целиком через
+// временный файл, поэтому при сбое остается либо старая, либо новая версия.
+func (s *Store) Update(e Entry) error {
+	entries, i, err := s.find(e)
+	if err != nil {
+		return err
+	}
+	entries[i] = e
+	return s.rewrite(entries)
+}
+
+// Remove удаляет последнюю запись с тем же временем и именем, например когда
+// выбор отменен. Файл перезаписывается так же, как в Update.
+func (s *Store) Remove(e Entry) error {
+	entries, i, err := s.find(e)
+	if err != nil {
+		return err
+	}
+	return s.rewrite(append(entries[:i], entries[i+1:]...))
+}
+
+// find читает журнал и возвращает индекс последней записи с тем же временем и именем, что у e.
+func (s *Store) find(e Entry) ([]Entry, int, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Time.Equal(e.Time) && entries[i].Label == e.Label {
+			return entries, i, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("no history entry for %s at %s", e.Label, e.Time.Format(time.RFC3339))
+}
+
+// rewrite записывает журнал целиком через временный файл.
+func (s *Store) rewrite(entries []Entry) error {
+	var buf []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// Load читает все записи журнала. Отсутствующий файл означает пустой журнал.
+func (s *Store) Load() ([]Entry, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.Path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Last возвращает не более n последних записей журнала.
+func (s *Store) Last(n int) ([]Entry, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Tail(entries, n), nil
+}
+
+// Tail возвращает не более n последних записей.
+func Tail(entries []Entry, n int) []Entry {
+	if n >= 0 && len(entries) > n {
+		return entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// ExportCSV записывает записи в формате CSV с заголовком.
+func ExportCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "label", "cycle", "seed", "speaking_seconds"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		seed := ""
+		if e.Seed != nil {
+			seed = strconv.FormatUint(*e.Seed, 10)
+		}
+		speaking := ""
+		if e.SpeakingSeconds > 0 {
+			speaking = strconv.FormatFloat(e.SpeakingSeconds, 'f', 1, 64)
+		}
+		record := []string{e.Time.Format(time.RFC3339), e.Label, strconv.Itoa(e.Cycle), seed, speaking}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportJSON записывает записи одним JSON-массивом.
+func ExportJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
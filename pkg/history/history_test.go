@@ -0,0 +1,114 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntries() []Entry {
+	seed := uint64(42)
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return []Entry{
+		{Time: start, Label: "Anna", Cycle: 1, Seed: &seed},
+		{Time: start.Add(time.Minute), Label: "Bob, Jr.", Cycle: 1},
+		{Time: start.Add(2 * time.Minute), Label: "Carol", Cycle: 2, Seed: &seed, SpeakingSeconds: 95.25},
+	}
+}
+
+func TestStore_AppendLoad(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	entries, err := s.Load()
+	require.NoError(t, err, "Missing file should be an empty history")
+	assert.Empty(t, entries)
+
+	for _, e := range testEntries() {
+		require.NoError(t, s.Append(e))
+	}
+
+	entries, err = s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testEntries(), entries)
+
+	last, err := s.Last(2)
+	require.NoError(t, err)
+	assert.Equal(t, testEntries()[1:], last)
+}
+
+func TestStore_Update(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	for _, e := range testEntries() {
+		require.NoError(t, s.Append(e))
+	}
+
+	bob := testEntries()[1]
+	bob.SpeakingSeconds = 62
+	require.NoError(t, s.Update(bob))
+
+	entries, err := s.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, 62.0, entries[1].SpeakingSeconds)
+	assert.Equal(t, testEntries()[2], entries[2], "Other entries should stay untouched")
+
+	missing := bob
+	missing.Label = "Dave"
+	assert.Error(t, s.Update(missing))
+}
+
+func TestStore_Remove(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	for _, e := range testEntries() {
+		require.NoError(t, s.Append(e))
+	}
+
+	require.NoError(t, s.Remove(testEntries()[1]))
+	entries, err := s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{testEntries()[0], testEntries()[2]}, entries)
+
+	assert.Error(t, s.Remove(testEntries()[1]), "The entry is already gone")
+}
+
+func TestStore_LoadCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"label\":\"Anna\"}\nnot json\n"), 0o644))
+
+	_, err := NewStore(path).Load()
+	assert.ErrorContains(t, err, ":2:", "Error should point at the broken line")
+}
+
+func TestTail(t *testing.T) {
+	entries := testEntries()
+	assert.Len(t, Tail(entries, 10), 3)
+	assert.Len(t, Tail(entries, 1), 1)
+	assert.Equal(t, "Carol", Tail(entries, 1)[0].Label)
+	assert.Empty(t, Tail(entries, 0))
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportCSV(&buf, testEntries()))
+
+	want := "time,label,cycle,seed,speaking_seconds\n" +
+		"2024-03-01T09:30:00Z,Anna,1,42,\n" +
+		"2024-03-01T09:31:00Z,\"Bob, Jr.\",1,,\n" +
+		"2024-03-01T09:32:00Z,Carol,2,42,95.2\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportJSON(&buf, nil))
+	assert.JSONEq(t, "[]", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ExportJSON(&buf, testEntries()[:1]))
+	assert.JSONEq(t, `[{"time": "2024-03-01T09:30:00Z", "label": "Anna", "cycle": 1, "seed": 42}]`, buf.String())
+}
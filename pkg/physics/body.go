@@ -0,0 +1,304 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+)
+
+// Up — направление "вверх" в системе координат стола. Пол стола лежит в плоскости z = 0.
+var Up = cube.Point3D{Z: 1}
+
+// Table описывает параметры виртуального стола. Единицы длины совпадают с
+// единицами модели кости, время измеряется в тиках игры.
+type Table struct {
+	Gravity        float64 // Ускорение свободного падения
+	Restitution    float64 // Коэффициент восстановления при ударе (0 — неупругий, 1 — упругий)
+	Friction       float64 // Коэффициент трения о пол и стенки
+	LinearDamping  float64 // Доля линейной скорости, теряемая за тик
+	AngularDamping float64 // Доля угловой скорости, теряемая за тик
+	RollingDamping float64 // Дополнительная доля угловой скорости, теряемая за тик при касании пола
+	HalfWidth      float64 // Расстояние от центра стола до боковых стенок по X
+	HalfDepth      float64 // Расстояние от центра стола до стенок по Y
+	Substeps       int     // Количество шагов интегрирования за тик
+	MaxTicks       int     // Предельная длительность броска, после которой кость считается остановившейся
+	SettleTicks    int     // Сколько тиков подряд кость должна лежать неподвижно
+	SettleSpeed    float64 // Порог линейной скорости, ниже которого кость считается неподвижной
+	SettleSpin     float64 // Порог угловой скорости, ниже которого кость считается неподвижной
+}
+
+// DefaultTable возвращает параметры стола, подобранные под размер кости и экрана.
+func DefaultTable() Table {
+	return Table{
+		Gravity:        1.0,
+		Restitution:    0.35,
+		Friction:       0.5,
+		LinearDamping:  0.005,
+		AngularDamping: 0.01,
+		RollingDamping: 0.05,
+		HalfWidth:      300,
+		HalfDepth:      220,
+		Substeps:       8,
+		MaxTicks:       600,
+		SettleTicks:    20,
+		SettleSpeed:    0.2,
+		SettleSpin:     0.005,
+	}
+}
+
+// Body — твердое тело кости на столе.
+type Body struct {
+	Shape           *cube.Polyhedron
+	Position        cube.Point3D    // Центр масс в координатах стола
+	Velocity        cube.Point3D    // Линейная скорость (единиц за тик)
+	Orientation     cube.Quaternion // Ориентация относительно модели
+	AngularVelocity cube.Point3D    // Угловая скорость в координатах стола (рад/тик)
+
+	invMass    float64
+	invInertia float64
+	radius     float64 // Радиус описанной сферы, по которому сталкиваются кости
+	ticks      int
+	restTicks  int
+	settled    bool
+}
+
+// NewBody создает тело для кости заданной формы.
+// Правильные многогранники имеют изотропный тензор инерции, поэтому он
+// задается одним скаляром, как для сплошного шара того же радиуса.
+func NewBody(shape *cube.Polyhedron) *Body {
+	radius := 0.0
+	for _, v := range shape.Vertices {
+		radius = math.Max(radius, v.Length())
+	}
+	const mass = 1.0
+	inertia := 0.4 * mass * radius * radius
+
+	return &Body{
+		Shape:       shape,
+		Orientation: cube.IdentityQuaternion(),
+		invMass:     1 / mass,
+		invInertia:  1 / inertia,
+		radius:      radius,
+	}
+}
+
+// Throw запускает новый бросок с заданными начальными скоростями.
+func (b *Body) Throw(position, velocity, angularVelocity cube.Point3D) {
+	b.Position = position
+	b.Velocity = velocity
+	b.AngularVelocity = angularVelocity
+	b.ticks = 0
+	b.restTicks = 0
+	b.settled = false
+}
+
+// Settled сообщает, что кость остановилась.
+func (b *Body) Settled() bool {
+	return b.settled
+}
+
+// Step продвигает симуляцию на один тик.
+func (b *Body) Step(t Table) {
+	if b.settled {
+		return
+	}
+
+	substeps := max(t.Substeps, 1)
+	dt := 1.0 / float64(substeps)
+	for i := 0; i < substeps; i++ {
+		b.integrate(t, dt)
+		b.collide(t)
+	}
+
+	b.Velocity = b.Velocity.Scale(1 - t.LinearDamping)
+	b.AngularVelocity = b.AngularVelocity.Scale(1 - t.AngularDamping)
+	if b.touchesFloor() {
+		// Сопротивление качению не дает "круглым" костям (d12, d20) катиться бесконечно.
+		b.AngularVelocity = b.AngularVelocity.Scale(1 - t.RollingDamping)
+	}
+
+	b.ticks++
+	if b.isResting(t) {
+		b.restTicks++
+	} else {
+		b.restTicks = 0
+	}
+	if b.restTicks >= t.SettleTicks || (t.MaxTicks > 0 && b.ticks >= t.MaxTicks) {
+		b.settled = true
+		b.Velocity = cube.Point3D{}
+		b.AngularVelocity = cube.Point3D{}
+	}
+}
+
+// TopFace возвращает индекс грани, оказавшейся сверху. Если у кости нет
+// горизонтальной верхней грани (как у d4), результатом считается грань,
+// на которой кость стоит.
+func (b *Body) TopFace() int {
+	top, bottom := b.extremeFaces()
+	if b.Orientation.Rotate(b.Shape.Faces[top].Normal).Dot(Up) < flatThreshold {
+		return bottom
+	}
+	return top
+}
+
+// Cocked сообщает, что кость не лежит ни на одной грани (например, оперлась о стенку).
+func (b *Body) Cocked() bool {
+	_, bottom := b.extremeFaces()
+	return b.Orientation.Rotate(b.Shape.Faces[bottom].Normal).Dot(Up) > -flatThreshold
+}
+
+// flatThreshold — косинус наклона, при котором грань считается горизонтальной.
+const flatThreshold = 0.95
+
+// extremeFaces возвращает грани, чьи нормали больше всего направлены вверх и вниз.
+func (b *Body) extremeFaces() (top, bottom int) {
+	topDot, bottomDot := math.Inf(-1), math.Inf(1)
+	for i, face := range b.Shape.Faces {
+		d := b.Orientation.Rotate(face.Normal).Dot(Up)
+		if d > topDot {
+			top, topDot = i, d
+		}
+		if d < bottomDot {
+			bottom, bottomDot = i, d
+		}
+	}
+	return top, bottom
+}
+
+// Collide разводит кости b и other, если они столкнулись, и обменивает импульс удара.
+// Кости приближены описанными сферами, поэтому не проникают друг в друга ни в каком
+// положении. Остановившаяся кость не сдвигается, как стенка стола.
+func (b *Body) Collide(other *Body, t Table) {
+	if b.settled && other.settled {
+		return
+	}
+	delta := other.Position.Sub(b.Position)
+	dist := delta.Length()
+	overlap := b.radius + other.radius - dist
+	if overlap <= 0 {
+		return
+	}
+	n := cube.Point3D{X: 1}
+	if dist > 1e-9 {
+		n = delta.Scale(1 / dist)
+	}
+
+	invA, invB := b.movableMass(), other.movableMass()
+	sum := invA + invB
+	b.Position = b.Position.Sub(n.Scale(overlap * invA / sum))
+	other.Position = other.Position.Add(n.Scale(overlap * invB / sum))
+
+	vn := other.Velocity.Sub(b.Velocity).Dot(n)
+	if vn >= 0 {
+		return // Кости уже расходятся
+	}
+	j := -(1 + t.Restitution) * vn / sum
+	b.Velocity = b.Velocity.Sub(n.Scale(j * invA))
+	other.Velocity = other.Velocity.Add(n.Scale(j * invB))
+}
+
+// movableMass возвращает обратную массу тела; у остановившейся кости она нулевая.
+func (b *Body) movableMass() float64 {
+	if b.settled {
+		return 0
+	}
+	return b.invMass
+}
+
+// integrate применяет гравитацию и переносит тело на шаг dt.
+func (b *Body) integrate(t Table, dt float64) {
+	b.Velocity = b.Velocity.Sub(Up.Scale(t.Gravity * dt))
+	b.Position = b.Position.Add(b.Velocity.Scale(dt))
+
+	angle := b.AngularVelocity.Length() * dt
+	if angle > 0 {
+		spin := cube.QuaternionFromAxisAngle(b.AngularVelocity, angle)
+		b.Orientation = spin.Mul(b.Orientation).Normalize()
+	}
+}
+
+// collide обрабатывает столкновения вершин с полом и стенками стола.
+// Касание ребром или гранью складывается из касаний нескольких вершин.
+func (b *Body) collide(t Table) {
+	planes := []struct {
+		normal cube.Point3D
+		offset float64
+	}{
+		{Up, 0},
+		{cube.Point3D{X: 1}, -t.HalfWidth},
+		{cube.Point3D{X: -1}, -t.HalfWidth},
+		{cube.Point3D{Y: 1}, -t.HalfDepth},
+		{cube.Point3D{Y: -1}, -t.HalfDepth},
+	}
+
+	for _, plane := range planes {
+		deepest := 0.0
+		for _, v := range b.Shape.Vertices {
+			r := b.Orientation.Rotate(v)
+			depth := plane.offset - b.Position.Add(r).Dot(plane.normal)
+			if depth <= 0 {
+				continue
+			}
+			deepest = math.Max(deepest, depth)
+			b.applyContact(t, r, plane.normal)
+		}
+		// Выталкиваем тело из плоскости, чтобы погрешность не накапливалась.
+		b.Position = b.Position.Add(plane.normal.Scale(deepest))
+	}
+}
+
+// applyContact применяет импульс удара и трения в точке r (относительно центра масс).
+func (b *Body) applyContact(t Table, r, n cube.Point3D) {
+	velocity := b.Velocity.Add(b.AngularVelocity.Cross(r))
+	vn := velocity.Dot(n)
+	if vn >= 0 {
+		return
+	}
+
+	// Медленные касания гасим без отскока, иначе лежащая кость дрожит на столе.
+	restitution := t.Restitution
+	if -vn < 2*t.Gravity {
+		restitution = 0
+	}
+
+	rn := r.Cross(n)
+	j := -(1 + restitution) * vn / (b.invMass + b.invInertia*rn.Dot(rn))
+	b.applyImpulse(r, n.Scale(j))
+
+	// Трение по закону Кулона: касательный импульс не больше μ·j.
+	velocity = b.Velocity.Add(b.AngularVelocity.Cross(r))
+	tangent := velocity.Sub(n.Scale(velocity.Dot(n)))
+	speed := tangent.Length()
+	if speed < 1e-9 {
+		return
+	}
+	tangent = tangent.Scale(1 / speed)
+	rt := r.Cross(tangent)
+	jt := speed / (b.invMass + b.invInertia*rt.Dot(rt))
+	jt = math.Min(jt, t.Friction*j)
+	b.applyImpulse(r, tangent.Scale(-jt))
+}
+
+// applyImpulse изменяет линейную и угловую скорость от импульса, приложенного в точке r.
+func (b *Body) applyImpulse(r, impulse cube.Point3D) {
+	b.Velocity = b.Velocity.Add(impulse.Scale(b.invMass))
+	b.AngularVelocity = b.AngularVelocity.Add(r.Cross(impulse).Scale(b.invInertia))
+}
+
+// isResting проверяет, что кость почти неподвижна и касается пола.
+func (b *Body) isResting(t Table) bool {
+	if b.Velocity.Length() > t.SettleSpeed || b.AngularVelocity.Length() > t.SettleSpin {
+		return false
+	}
+	return b.touchesFloor()
+}
+
+// touchesFloor проверяет, что хотя бы одна вершина касается пола.
+func (b *Body) touchesFloor() bool {
+	for _, v := range b.Shape.Vertices {
+		if b.Position.Add(b.Orientation.Rotate(v)).Z < 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBody(t *testing.T) {
+	shape := cube.NewCube()
+	b := NewBody(shape)
+
+	assert.Equal(t, shape, b.Shape)
+	assert.Equal(t, cube.IdentityQuaternion(), b.Orientation)
+	assert.False(t, b.Settled(), "A new body should not be settled")
+}
+
+func TestBodySettlesOnTable(t *testing.T) {
+	table := DefaultTable()
+	throws := []struct {
+		velocity, spin cube.Point3D
+	}{
+		{cube.Point3D{X: 4, Y: -3}, cube.Point3D{X: 0.2, Y: -0.1, Z: 0.05}},
+		{cube.Point3D{X: -6, Y: 5}, cube.Point3D{X: -0.3, Y: 0.25, Z: -0.1}},
+		{cube.Point3D{}, cube.Point3D{X: 0.05, Y: 0.3}},
+	}
+
+	for _, sides := range cube.SupportedSides {
+		shape, err := cube.NewPolyhedron(sides)
+		assert.NoError(t, err)
+
+		for i, throw := range throws {
+			b := NewBody(shape)
+			b.Throw(cube.Point3D{Z: 250}, throw.velocity, throw.spin)
+
+			ticks := 0
+			for !b.Settled() {
+				b.Step(table)
+				ticks++
+
+				for _, v := range shape.Vertices {
+					p := b.Position.Add(b.Orientation.Rotate(v))
+					assert.Greater(t, p.Z, -5.0, "%s throw %d: vertex should not sink into the floor", shape.Name, i)
+					assert.Less(t, math.Abs(p.X), table.HalfWidth+5, "%s throw %d: vertex should stay inside the table", shape.Name, i)
+					assert.Less(t, math.Abs(p.Y), table.HalfDepth+5, "%s throw %d: vertex should stay inside the table", shape.Name, i)
+				}
+			}
+
+			assert.Less(t, ticks, table.MaxTicks, "%s throw %d: body should come to rest before the time limit", shape.Name, i)
+			top := b.TopFace()
+			assert.GreaterOrEqual(t, top, 0)
+			assert.Less(t, top, len(shape.Faces))
+		}
+	}
+}
+
+func TestBodyStepAfterSettled(t *testing.T) {
+	b := NewBody(cube.NewCube())
+	b.Throw(cube.Point3D{Z: 75}, cube.Point3D{}, cube.Point3D{})
+	table := DefaultTable()
+	for !b.Settled() {
+		b.Step(table)
+	}
+	position := b.Position
+
+	b.Step(table)
+
+	assert.Equal(t, position, b.Position, "A settled body should not move")
+}
+
+func TestTopFace(t *testing.T) {
+	shape := cube.NewCube()
+	b := NewBody(shape)
+
+	// Без поворота вверх (+Z) смотрит передняя грань куба.
+	assert.Equal(t, 1, b.TopFace())
+	assert.False(t, b.Cocked())
+
+	// Поворот на 90° вокруг X разворачивает грань с нормалью +Y вверх.
+	b.Orientation = cube.QuaternionFromAxisAngle(cube.Point3D{X: 1}, math.Pi/2)
+	assert.Equal(t, 4, b.TopFace())
+
+	// Поворот на 45° ставит куб на ребро.
+	b.Orientation = cube.QuaternionFromAxisAngle(cube.Point3D{X: 1}, math.Pi/4)
+	assert.True(t, b.Cocked(), "A cube balanced on an edge should be cocked")
+}
+
+func TestTopFace_Tetrahedron(t *testing.T) {
+	shape := cube.NewTetrahedron()
+	b := NewBody(shape)
+
+	// Кладем d4 на грань 2: ее нормаль должна смотреть вниз.
+	n := shape.Faces[2].Normal
+	axis := n.Cross(Up.Scale(-1))
+	angle := math.Acos(n.Dot(Up.Scale(-1)))
+	b.Orientation = cube.QuaternionFromAxisAngle(axis, angle)
+
+	assert.False(t, b.Cocked())
+	assert.Equal(t, 2, b.TopFace(), "d4 result should be the face it rests on")
+}
+
+func TestCollide(t *testing.T) {
+	table := DefaultTable()
+	a, b := NewBody(cube.NewCube()), NewBody(cube.NewCube())
+	a.Throw(cube.Point3D{X: -150, Z: 100}, cube.Point3D{X: 8}, cube.Point3D{})
+	b.Throw(cube.Point3D{X: 150, Z: 100}, cube.Point3D{X: -8}, cube.Point3D{})
+
+	for !a.Settled() || !b.Settled() {
+		a.Step(table)
+		b.Step(table)
+		a.Collide(b, table)
+		gap := b.Position.Sub(a.Position).Length()
+		assert.GreaterOrEqual(t, gap, a.radius+b.radius-1e-6, "Dice should not overlap")
+	}
+	assert.Less(t, a.Position.X, b.Position.X, "Dice should bounce off each other rather than pass through")
+
+	// Катящаяся кость отскакивает от остановившейся, не сдвигая ее
+	position := b.Position
+	a.Throw(cube.Point3D{X: b.Position.X - 2*a.radius + 10, Z: b.Position.Z}, cube.Point3D{X: 5}, cube.Point3D{})
+	a.Collide(b, table)
+	assert.Equal(t, position, b.Position)
+	assert.Less(t, a.Velocity.X, 0.0)
+}
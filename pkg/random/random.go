@@ -0,0 +1,145 @@
+package random
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand/v2"
+)
+
+// Source — источник случайности для всех решений, принимаемых при броске:
+// перемешивания пула текстур, выбора победителя и параметров вращения.
+type Source interface {
+	// Intn возвращает случайное число в диапазоне [0, n).
+	Intn(n int) int
+	// Float64 возвращает случайное число в диапазоне [0, 1).
+	Float64() float64
+	// Shuffle перемешивает n элементов, вызывая swap для перестановки.
+	Shuffle(n int, swap func(i, j int))
+}
+
+// Seeded реализуют воспроизводимые источники, зерно которых известно.
+type Seeded interface {
+	Seed() uint64
+}
+
+// pcgStream — фиксированный номер потока генератора PCG. Вместе с зерном он
+// полностью определяет последовательность чисел.
+const pcgStream = 0x9e3779b97f4a7c15
+
+// PCG — воспроизводимый источник на основе генератора PCG.
+// Одно и то же зерно дает одну и ту же последовательность победителей и вращений.
+type PCG struct {
+	seed uint64
+	r    *rand.Rand
+}
+
+// NewPCG создает воспроизводимый источник с заданным зерном.
+func NewPCG(seed uint64) *PCG {
+	return &PCG{
+		seed: seed,
+		r:    rand.New(rand.NewPCG(seed, pcgStream)),
+	}
+}
+
+// Seed возвращает зерно, с которым был создан источник.
+func (p *PCG) Seed() uint64 {
+	return p.seed
+}
+
+// Intn реализует Source.
+func (p *PCG) Intn(n int) int {
+	return p.r.IntN(n)
+}
+
+// Float64 реализует Source.
+func (p *PCG) Float64() float64 {
+	return p.r.Float64()
+}
+
+// Shuffle реализует Source.
+func (p *PCG) Shuffle(n int, swap func(i, j int)) {
+	p.r.Shuffle(n, swap)
+}
+
+// cryptoReader поставляет 64-битные числа из crypto/rand.
+type cryptoReader struct{}
+
+// Uint64 реализует rand.Source.
+func (cryptoReader) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("crypto/rand is unavailable: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Crypto — невоспроизводимый источник на основе crypto/rand.
+type Crypto struct {
+	r *rand.Rand
+}
+
+// NewCrypto создает невоспроизводимый источник на основе crypto/rand.
+func NewCrypto() *Crypto {
+	return &Crypto{r: rand.New(cryptoReader{})}
+}
+
+// Intn реализует Source.
+func (c *Crypto) Intn(n int) int {
+	return c.r.IntN(n)
+}
+
+// Float64 реализует Source.
+func (c *Crypto) Float64() float64 {
+	return c.r.Float64()
+}
+
+// Shuffle реализует Source.
+func (c *Crypto) Shuffle(n int, swap func(i, j int)) {
+	c.r.Shuffle(n, swap)
+}
+
+// NewSeed возвращает случайное зерно для PCG, полученное из crypto/rand.
+func NewSeed() uint64 {
+	return cryptoReader{}.Uint64()
+}
+
+// Scripted — источник с заранее заданной последовательностью значений для тестов.
+// Когда значения заканчиваются, последовательность начинается сначала.
+type Scripted struct {
+	Ints   []int
+	Floats []float64
+	ints   int
+	floats int
+}
+
+// NewScripted создает источник, выдающий заданные значения по порядку.
+func NewScripted(ints []int, floats []float64) *Scripted {
+	return &Scripted{Ints: ints, Floats: floats}
+}
+
+// Intn возвращает очередное целое из сценария, приведенное к диапазону [0, n).
+func (s *Scripted) Intn(n int) int {
+	if len(s.Ints) == 0 {
+		return 0
+	}
+	v := s.Ints[s.ints%len(s.Ints)]
+	s.ints++
+	return ((v % n) + n) % n
+}
+
+// Float64 возвращает очередное дробное из сценария.
+func (s *Scripted) Float64() float64 {
+	if len(s.Floats) == 0 {
+		return 0
+	}
+	v := s.Floats[s.floats%len(s.Floats)]
+	s.floats++
+	return v
+}
+
+// Shuffle перемешивает элементы алгоритмом Фишера — Йетса, беря индексы из сценария.
+func (s *Scripted) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, s.Intn(i+1))
+	}
+}
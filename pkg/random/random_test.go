@@ -0,0 +1,81 @@
+package random
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPCG_Reproducible(t *testing.T) {
+	a := NewPCG(42)
+	b := NewPCG(42)
+
+	assert.Equal(t, uint64(42), a.Seed())
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, a.Intn(1000), b.Intn(1000), "same seed should give the same integers")
+		assert.Equal(t, a.Float64(), b.Float64(), "same seed should give the same floats")
+	}
+
+	permA := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	permB := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	a.Shuffle(len(permA), func(i, j int) { permA[i], permA[j] = permA[j], permA[i] })
+	b.Shuffle(len(permB), func(i, j int) { permB[i], permB[j] = permB[j], permB[i] })
+	assert.Equal(t, permA, permB, "same seed should give the same shuffle")
+}
+
+func TestPCG_DifferentSeeds(t *testing.T) {
+	a := NewPCG(1)
+	b := NewPCG(2)
+
+	same := true
+	for i := 0; i < 10; i++ {
+		if a.Float64() != b.Float64() {
+			same = false
+		}
+	}
+	assert.False(t, same, "different seeds should give different sequences")
+}
+
+func TestSourcesStayInRange(t *testing.T) {
+	sources := map[string]Source{
+		"pcg":      NewPCG(7),
+		"crypto":   NewCrypto(),
+		"scripted": NewScripted([]int{5, -3, 17}, []float64{0.25, 0.75}),
+	}
+
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				n := src.Intn(6)
+				assert.GreaterOrEqual(t, n, 0)
+				assert.Less(t, n, 6)
+
+				f := src.Float64()
+				assert.GreaterOrEqual(t, f, 0.0)
+				assert.Less(t, f, 1.0)
+			}
+
+			perm := []int{0, 1, 2, 3, 4, 5}
+			src.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+			sort.Ints(perm)
+			assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, perm, "shuffle should produce a permutation")
+		})
+	}
+}
+
+func TestScripted(t *testing.T) {
+	s := NewScripted([]int{2, 0, 1}, []float64{0.1, 0.9})
+
+	assert.Equal(t, 2, s.Intn(3))
+	assert.Equal(t, 0, s.Intn(3))
+	assert.Equal(t, 1, s.Intn(3))
+	assert.Equal(t, 2, s.Intn(3), "sequence should wrap around")
+	assert.Equal(t, 0.1, s.Float64())
+	assert.Equal(t, 0.9, s.Float64())
+	assert.Equal(t, 0.1, s.Float64())
+
+	empty := NewScripted(nil, nil)
+	assert.Equal(t, 0, empty.Intn(5))
+	assert.Equal(t, 0.0, empty.Float64())
+}
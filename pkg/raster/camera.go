@@ -0,0 +1,105 @@
+// Package raster проецирует кости на экран и рисует их на процессоре в image.RGBA.
+// Пакет не зависит от Ebitengine: камерой и светом пользуется и рендерер на
+// видеокарте из пакета graphics, а программный рендерер работает без окна,
+// поэтому его кадры сравниваются с эталонами в тестах на сервере без дисплея.
+package raster
+
+import (
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+)
+
+const (
+	nearPlane  = 1.0  // Грани ближе этого к камере (в пикселях) не рисуются
+	degenerate = 1e-9 // Длина вектора, при которой его направление не определено
+)
+
+// Camera задает проекцию сцены на экран. Координаты сцены — пиксели от центра
+// экрана: X вправо, Y вниз, Z от зрителя. Камера стоит перед центром экрана
+// на расстоянии Distance и смотрит на точку LookAt.
+type Camera struct {
+	FOV      float64      // Угол обзора по вертикали в градусах; 0 — ортографическая проекция
+	Distance float64      // Расстояние от камеры до плоскости экрана в пикселях; 0 — при котором плоскость экрана не масштабируется
+	LookAt   cube.Point3D // Точка, на которую смотрит камера; в ортографической проекции не используется
+}
+
+// Projection — камера, настроенная на экран определенного размера.
+type Projection struct {
+	Perspective          bool // Перспективная проекция; иначе ортографическая
+	eye                  cube.Point3D
+	right, down, forward cube.Point3D // Оси камеры в координатах сцены
+	focal                float64      // Фокусное расстояние в пикселях
+	centerX, centerY     float64
+}
+
+// focal возвращает фокусное расстояние в пикселях для экрана высотой height.
+func (c Camera) focal(height float64) float64 {
+	return height / 2 / math.Tan(c.FOV*math.Pi/360)
+}
+
+// Projection настраивает камеру на экран width×height.
+func (c Camera) Projection(width, height float64) Projection {
+	p := Projection{centerX: width / 2, centerY: height / 2}
+	if c.FOV <= 0 {
+		return p
+	}
+
+	p.Perspective = true
+	p.focal = c.focal(height)
+	distance := c.Distance
+	if distance <= 0 {
+		distance = p.focal
+	}
+	p.eye = cube.Point3D{Z: -distance}
+	forward := c.LookAt.Sub(p.eye)
+	if forward.Length() < degenerate {
+		// Точка взгляда совпадает с камерой: камера смотрит прямо на экран
+		forward = cube.Point3D{Z: 1}
+	}
+	p.forward = forward.Normalize()
+	// "Низ" камеры остается в вертикальной плоскости, проходящей через направление взгляда
+	right := cube.Point3D{Y: 1}.Cross(p.forward)
+	if right.Length() < degenerate {
+		// Камера смотрит прямо вверх или вниз, и такой плоскости нет: низ кадра — ближний к зрителю край
+		right = cube.Point3D{Z: -1}.Cross(p.forward)
+	}
+	p.right = right.Normalize()
+	p.down = p.forward.Cross(p.right)
+	return p
+}
+
+// depth возвращает расстояние до точки сцены вдоль направления взгляда.
+func (p Projection) depth(v cube.Point3D) float64 {
+	if !p.Perspective {
+		return v.Z
+	}
+	return v.Sub(p.eye).Dot(p.forward)
+}
+
+// Project возвращает координаты точки сцены v на экране.
+func (p Projection) Project(v cube.Point3D) (x, y float64) {
+	if !p.Perspective {
+		return p.centerX + v.X, p.centerY + v.Y
+	}
+	d := v.Sub(p.eye)
+	z := d.Dot(p.forward)
+	return p.centerX + p.focal*d.Dot(p.right)/z, p.centerY + p.focal*d.Dot(p.down)/z
+}
+
+// ToEye возвращает единичный вектор из точки сцены v на камеру.
+func (p Projection) ToEye(v cube.Point3D) cube.Point3D {
+	if !p.Perspective {
+		return cube.Point3D{Z: -1}
+	}
+	return p.eye.Sub(v).Normalize()
+}
+
+// facing сообщает, что грань с нормалью normal, проходящая через точку v, обращена к камере.
+// Нормаль направлена так, как ее дает обход вершин грани: от зрителя.
+func (p Projection) facing(v, normal cube.Point3D) bool {
+	if !p.Perspective {
+		return normal.Z > 0
+	}
+	return normal.Dot(v.Sub(p.eye)) > 0
+}
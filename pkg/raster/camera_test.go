@@ -0,0 +1,57 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCamera_Orthographic(t *testing.T) {
+	p := Camera{}.Projection(800, 600)
+	x, y := p.Project(cube.Point3D{X: 10, Y: 20, Z: 500})
+	assert.Equal(t, 410.0, x)
+	assert.Equal(t, 320.0, y, "Depth does not change the size without perspective")
+	assert.False(t, p.facing(cube.Point3D{X: 100}, cube.Point3D{X: 1}), "Side faces are not seen")
+}
+
+func TestCamera_Perspective(t *testing.T) {
+	p := Camera{FOV: 30}.Projection(800, 600)
+	x, y := p.Project(cube.Point3D{X: 100, Y: 50})
+	assert.InDelta(t, 500.0, x, 1e-9, "The screen plane keeps its scale")
+	assert.InDelta(t, 350.0, y, 1e-9)
+
+	far, _ := p.Project(cube.Point3D{X: 100, Z: 200})
+	near, _ := p.Project(cube.Point3D{X: 100, Z: -200})
+	assert.Less(t, far, 500.0, "Farther points are closer to the center")
+	assert.Greater(t, near, 500.0)
+	assert.Greater(t, p.depth(cube.Point3D{Z: 200}), p.depth(cube.Point3D{}))
+
+	// Кость справа от центра видна немного сбоку: ее левая грань обращена к камере
+	assert.True(t, p.facing(cube.Point3D{X: 100}, cube.Point3D{X: 1}))
+	assert.False(t, p.facing(cube.Point3D{X: -100}, cube.Point3D{X: 1}))
+
+	// Дальше поставленная камера показывает кость меньше
+	x, _ = Camera{FOV: 30, Distance: 5000}.Projection(800, 600).Project(cube.Point3D{X: 100})
+	assert.Less(t, x, 500.0)
+
+	// Камера, повернутая к точке LookAt, показывает ее в центре экрана
+	x, y = Camera{FOV: 30, LookAt: cube.Point3D{X: 100, Y: -40}}.Projection(800, 600).Project(cube.Point3D{X: 100, Y: -40})
+	assert.InDelta(t, 400.0, x, 1e-9)
+	assert.InDelta(t, 300.0, y, 1e-9)
+}
+
+func TestCamera_DegenerateLookAt(t *testing.T) {
+	// Точка взгляда прямо над камерой, под ней или в ней самой не дает NaN
+	eye := cube.Point3D{Z: -1000}
+	for _, lookAt := range []cube.Point3D{{Z: -1000, Y: -300}, {Z: -1000, Y: 300}, eye} {
+		p := Camera{FOV: 30, Distance: 1000, LookAt: lookAt}.Projection(800, 600)
+		for _, axis := range []cube.Point3D{p.right, p.down, p.forward} {
+			assert.InDelta(t, 1, axis.Length(), 1e-9, "look_at %v", lookAt)
+		}
+		assert.InDelta(t, 0, p.right.Dot(p.down), 1e-9)
+		x, y := p.Project(cube.Point3D{X: 10, Y: 20, Z: 30})
+		assert.False(t, math.IsNaN(x) || math.IsNaN(y), "look_at %v", lookAt)
+	}
+}
@@ -0,0 +1,42 @@
+package raster
+
+import (
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+)
+
+// Light — освещение костей: рассеянный свет, направленный свет и блик по модели
+// Блинна-Фонга. Цвета задаются долями от 0 до 1 по каналам R, G, B.
+type Light struct {
+	Direction cube.Point3D // Направление от кости на источник света в координатах сцены
+	Ambient   [3]float64   // Рассеянный свет, одинаковый для всех граней
+	Diffuse   [3]float64   // Направленный свет на грани, обращенной прямо к источнику
+	Specular  [3]float64   // Цвет блика; нули отключают блик
+	Shininess float64      // Чем больше, тем меньше и резче блик
+}
+
+// Shade возвращает множитель цвета текстуры для грани с внешней нормалью normal.
+func (l *Light) Shade(normal cube.Point3D) (rgb [3]float32) {
+	diffuse := math.Max(0, normal.Dot(l.Direction.Normalize()))
+	for i := range rgb {
+		rgb[i] = float32(math.Min(1, l.Ambient[i]+l.Diffuse[i]*diffuse))
+	}
+	return rgb
+}
+
+// Shiny сообщает, что у света есть блик.
+func (l *Light) Shiny() bool {
+	return l.Specular != [3]float64{}
+}
+
+// Highlight возвращает цвет блика в точке грани с внешней нормалью normal,
+// если направление из этой точки на камеру — toEye.
+func (l *Light) Highlight(normal, toEye cube.Point3D) (rgb [3]float32) {
+	half := l.Direction.Normalize().Add(toEye).Normalize()
+	k := math.Pow(math.Max(0, normal.Dot(half)), l.Shininess)
+	for i := range rgb {
+		rgb[i] = float32(math.Min(1, l.Specular[i]*k))
+	}
+	return rgb
+}
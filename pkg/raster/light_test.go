@@ -0,0 +1,51 @@
+package raster
+
+import (
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLight() *Light {
+	return &Light{
+		Direction: cube.Point3D{Z: -2}, // Свет из-за спины зрителя
+		Ambient:   [3]float64{0.2, 0.2, 0.2},
+		Diffuse:   [3]float64{0.6, 0.6, 0.9},
+		Specular:  [3]float64{0.5, 0.5, 0.5},
+		Shininess: 16,
+	}
+}
+
+// assertRGB сравнивает цвета с точностью до ошибок округления.
+func assertRGB(t *testing.T, want, got [3]float32, msgAndArgs ...any) {
+	t.Helper()
+	for i := range want {
+		assert.InDelta(t, want[i], got[i], 1e-6, msgAndArgs...)
+	}
+}
+
+func TestLight_Shade(t *testing.T) {
+	l := testLight()
+	assertRGB(t, [3]float32{0.8, 0.8, 1}, l.Shade(cube.Point3D{Z: -1}), "A face turned to the light is lit fully; channels are capped at 1")
+	assertRGB(t, [3]float32{0.2, 0.2, 0.2}, l.Shade(cube.Point3D{X: 1}), "A face edge-on to the light gets only ambient light")
+	assertRGB(t, [3]float32{0.2, 0.2, 0.2}, l.Shade(cube.Point3D{Z: 1}))
+
+	tilted := l.Shade(cube.Point3D{X: 1, Z: -1}.Normalize())
+	assert.Greater(t, tilted[0], float32(0.2))
+	assert.Less(t, tilted[0], float32(0.8), "Tilted faces are darker")
+}
+
+func TestLight_Highlight(t *testing.T) {
+	l := testLight()
+	assert.True(t, l.Shiny())
+	front := cube.Point3D{Z: -1}
+	assertRGB(t, [3]float32{0.5, 0.5, 0.5}, l.Highlight(front, front), "The light is reflected straight into the camera")
+
+	side := l.Highlight(front, cube.Point3D{X: 1, Z: -1}.Normalize())
+	assert.Less(t, side[0], float32(0.5), "The highlight fades away from the mirror direction")
+	assert.Zero(t, l.Highlight(cube.Point3D{Z: 1}, front)[0])
+
+	l.Specular = [3]float64{}
+	assert.False(t, l.Shiny())
+}
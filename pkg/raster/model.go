@@ -0,0 +1,80 @@
+package raster
+
+import (
+	"math"
+	"sort"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+)
+
+// Model — кость в сцене из нескольких костей.
+type Model struct {
+	Cube        *cube.Polyhedron
+	Orientation cube.Quaternion
+	Position    cube.Point3D // Смещение от центра экрана в пикселях; Z — глубина (дальше от зрителя — больше)
+	Size        float64      // Размер относительно Scale; 0 — обычный размер
+}
+
+// BackToFront возвращает индексы костей от дальних к ближним.
+func BackToFront(models []Model) []int {
+	order := make([]int, len(models))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return models[order[i]].Position.Z > models[order[j]].Position.Z
+	})
+	return order
+}
+
+// VisibleFace — видимая грань кости в порядке отрисовки.
+type VisibleFace struct {
+	Face   cube.Face
+	Normal cube.Point3D // Внешняя единичная нормаль
+	depth  float64
+}
+
+// VisibleFaces переводит вершины кости m в сцену (в пиксели от центра экрана) и
+// возвращает их вместе с обращенными к камере гранями, от дальних к ближним.
+func VisibleFaces(m Model, scale float64, proj Projection) ([]cube.Point3D, []VisibleFace) {
+	c, orientation, position := m.Cube, m.Orientation, m.Position
+	if m.Size > 0 {
+		scale *= m.Size
+	}
+
+	points := make([]cube.Point3D, len(c.Vertices))
+	for i, v := range c.Vertices {
+		points[i] = orientation.Rotate(v).Scale(scale).Add(position)
+	}
+
+	sortedFaces := make([]VisibleFace, 0, len(c.Faces))
+	for _, face := range c.Faces {
+		depth := 0.0
+		nearest := math.Inf(1)
+		for _, idx := range face.Indices {
+			d := proj.depth(points[idx])
+			depth += d
+			nearest = min(nearest, d)
+		}
+		depth /= float64(len(face.Indices))
+		if proj.Perspective && nearest < nearPlane {
+			continue // Грань за камерой или вплотную к ней
+		}
+
+		// Back-face culling
+		v0 := points[face.Indices[0]]
+		v1 := points[face.Indices[1]]
+		v2 := points[face.Indices[2]]
+		normal := v1.Sub(v0).Cross(v2.Sub(v0))
+		if proj.facing(v0, normal) {
+			// Обход вершин дает нормаль внутрь кости
+			sortedFaces = append(sortedFaces, VisibleFace{Face: face, Normal: normal.Scale(-1).Normalize(), depth: depth})
+		}
+	}
+
+	// Сортировка граней для правильного отображения (Painter's algorithm): дальние раньше
+	sort.Slice(sortedFaces, func(i, j int) bool {
+		return sortedFaces[i].depth > sortedFaces[j].depth
+	})
+	return points, sortedFaces
+}
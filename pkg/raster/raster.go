@@ -0,0 +1,180 @@
+package raster
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/config"
+)
+
+// Renderer рисует кости на процессоре в image.RGBA: без видеокарты и окна,
+// поэтому кадры можно сравнивать с эталонными изображениями в тестах. Проекция,
+// отсечение невидимых граней, порядок отрисовки и освещение те же, что у
+// graphics.Renderer.
+type Renderer struct {
+	Scale  float64 // Во сколько раз кость на экране больше модели
+	Camera Camera  // Проекция сцены на экран
+	Light  *Light  // Освещение граней; nil — грани рисуются без затенения
+
+	cache  map[image.Image]*cachedTexture // Текстуры, приведенные к image.RGBA
+	frames int                            // Сколько кадров нарисовано
+}
+
+// textureTTL — сколько кадров текстура хранится в кэше, не попадая в кадр.
+const textureTTL = 600
+
+// cachedTexture — текстура грани, приведенная к image.RGBA.
+type cachedTexture struct {
+	image *image.RGBA
+	used  int // Кадр, в котором текстура рисовалась последней
+}
+
+// NewRenderer создает программный рендерер.
+func NewRenderer() *Renderer {
+	return &Renderer{Scale: 1.5}
+}
+
+// texture возвращает текстуру в виде image.RGBA или nil, если ее нет. Текстуры,
+// приведенные к image.RGBA, запоминаются.
+func (r *Renderer) texture(tex image.Image) *image.RGBA {
+	if tex == nil {
+		return nil
+	}
+	t, ok := r.cache[tex]
+	if !ok {
+		img, ok := tex.(*image.RGBA)
+		if !ok {
+			b := tex.Bounds()
+			img = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+			draw.Draw(img, img.Rect, tex, b.Min, draw.Src)
+		}
+		if r.cache == nil {
+			r.cache = map[image.Image]*cachedTexture{}
+		}
+		t = &cachedTexture{image: img}
+		r.cache[tex] = t
+	}
+	t.used = r.frames
+	return t.image
+}
+
+// evictTextures забывает текстуры, которые давно не рисовались: например,
+// участников, замененных загрузкой других изображений.
+func (r *Renderer) evictTextures() {
+	r.frames++
+	for tex, t := range r.cache {
+		if r.frames-t.used > textureTTL {
+			delete(r.cache, tex)
+		}
+	}
+}
+
+// Render очищает кадр frame и рисует в нем кости от дальних к ближним.
+func (r *Renderer) Render(frame *image.RGBA, models []Model) {
+	clear(frame.Pix)
+	proj := r.Camera.Projection(float64(frame.Rect.Dx()), float64(frame.Rect.Dy()))
+	for _, i := range BackToFront(models) {
+		r.renderModel(frame, proj, models[i])
+	}
+	r.evictTextures()
+}
+
+// rasterVertex — вершина треугольника на экране.
+type rasterVertex struct {
+	x, y float64
+	w    float64 // Глубина для перспективно-корректной интерполяции; 1 без перспективы
+	u, v float64 // Координаты текстуры в пикселях
+	spec [3]float32
+}
+
+// renderModel рисует одну кость.
+func (r *Renderer) renderModel(frame *image.RGBA, proj Projection, m Model) {
+	points, sortedFaces := VisibleFaces(m, r.Scale, proj)
+	for _, fts := range sortedFaces {
+		face := fts.Face
+		tex := r.texture(face.Texture)
+		if tex == nil {
+			tex = r.texture(config.GreyImage)
+		}
+		shade := [3]float32{1, 1, 1}
+		if r.Light != nil {
+			shade = r.Light.Shade(fts.Normal)
+		}
+
+		corners := make([]rasterVertex, len(face.Indices))
+		for i, idx := range face.Indices {
+			p := points[idx]
+			x, y := proj.Project(p)
+			w := 1.0
+			if proj.Perspective {
+				w = proj.depth(p)
+			}
+			corners[i] = rasterVertex{
+				x: x, y: y, w: w,
+				u: float64(face.UVs[i][0]) * float64(tex.Rect.Dx()),
+				v: float64(face.UVs[i][1]) * float64(tex.Rect.Dy()),
+			}
+			if r.Light != nil && r.Light.Shiny() {
+				corners[i].spec = r.Light.Highlight(fts.Normal, proj.ToEye(p))
+			}
+		}
+		// Грань - выпуклый многоугольник, поэтому разбиваем ее на треугольники веером.
+		for i := 1; i < len(corners)-1; i++ {
+			fillTriangle(frame, tex, shade, corners[0], corners[i], corners[i+1])
+		}
+	}
+}
+
+// edge возвращает удвоенную площадь треугольника abp со знаком.
+func edge(a, b rasterVertex, x, y float64) float64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// fillTriangle закрашивает пиксели, центры которых попадают в треугольник abc.
+// Текстура берется по ближайшему текселю; координаты текстуры и блик
+// интерполируются с учетом перспективы.
+func fillTriangle(frame, tex *image.RGBA, shade [3]float32, a, b, c rasterVertex) {
+	area := edge(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	bounds := frame.Rect
+	minX := max(bounds.Min.X, int(math.Floor(min(a.x, b.x, c.x))))
+	maxX := min(bounds.Max.X-1, int(math.Ceil(max(a.x, b.x, c.x))))
+	minY := max(bounds.Min.Y, int(math.Floor(min(a.y, b.y, c.y))))
+	maxY := min(bounds.Max.Y-1, int(math.Ceil(max(a.y, b.y, c.y))))
+	texW, texH := tex.Rect.Dx(), tex.Rect.Dy()
+
+	for py := minY; py <= maxY; py++ {
+		y := float64(py) + 0.5
+		for px := minX; px <= maxX; px++ {
+			x := float64(px) + 0.5
+			// Барицентрические координаты; знак площади учитывает любой обход вершин
+			l0 := edge(b, c, x, y) / area
+			l1 := edge(c, a, x, y) / area
+			l2 := edge(a, b, x, y) / area
+			if l0 < 0 || l1 < 0 || l2 < 0 {
+				continue
+			}
+
+			k0, k1, k2 := l0/a.w, l1/b.w, l2/c.w
+			inv := 1 / (k0 + k1 + k2)
+			u := (k0*a.u + k1*b.u + k2*c.u) * inv
+			v := (k0*a.v + k1*b.v + k2*c.v) * inv
+			tx := min(max(int(u), 0), texW-1)
+			ty := min(max(int(v), 0), texH-1)
+			src := tex.Pix[tex.PixOffset(tex.Rect.Min.X+tx, tex.Rect.Min.Y+ty):]
+			dst := frame.Pix[frame.PixOffset(px, py):]
+
+			// Затененная текстура поверх кадра, затем блик поверх нее
+			srcA := float64(src[3])
+			for ch := 0; ch < 3; ch++ {
+				spec := float64(a.spec[ch])*k0 + float64(b.spec[ch])*k1 + float64(c.spec[ch])*k2
+				value := float64(src[ch])*float64(shade[ch]) + float64(dst[ch])*(1-srcA/255) + spec*inv*255
+				dst[ch] = uint8(min(math.Round(value), 255))
+			}
+			dst[3] = uint8(min(math.Round(srcA+float64(dst[3])*(1-srcA/255)), 255))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package raster
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden images in testdata")
+
+// Допуски сравнения с эталоном: округление float64 на разных процессорах
+// может немного сдвинуть цвет или край треугольника.
+const (
+	goldenChannelDelta = 2
+	goldenMaxDiffShare = 0.005
+)
+
+// testTexture — текстура грани: рамка, цвет грани и белая метка в левом верхнем углу,
+// по которой видно, как текстура повернута.
+func testTexture(c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			switch {
+			case x == 0 || y == 0 || x == 15 || y == 15:
+				img.SetRGBA(x, y, color.RGBA{A: 0xFF})
+			case x < 6 && y < 6:
+				img.SetRGBA(x, y, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF})
+			default:
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	return img
+}
+
+var testPalette = []color.RGBA{
+	{0xE5, 0x39, 0x35, 0xFF}, {0x43, 0xA0, 0x47, 0xFF}, {0x1E, 0x88, 0xE5, 0xFF}, {0xFD, 0xD8, 0x35, 0xFF},
+	{0x8E, 0x24, 0xAA, 0xFF}, {0x00, 0x89, 0x7B, 0xFF}, {0xF4, 0x51, 0x1E, 0xFF}, {0x6D, 0x4C, 0x41, 0xFF},
+}
+
+// texturedDie возвращает кость с n гранями, раскрашенными цветами testPalette.
+func texturedDie(t *testing.T, sides int) *cube.Polyhedron {
+	die, err := cube.NewPolyhedron(sides)
+	require.NoError(t, err)
+	for i := range die.Faces {
+		die.Faces[i].Texture = testTexture(testPalette[i%len(testPalette)])
+	}
+	return die
+}
+
+// assertGolden сравнивает кадр с эталоном testdata/name.png. С флагом -update
+// эталон перезаписывается.
+func assertGolden(t *testing.T, name string, frame *image.RGBA) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		writePNG(t, path, frame)
+		return
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err, "run go test with -update to create the golden image")
+	defer f.Close()
+	golden, err := png.Decode(f)
+	require.NoError(t, err)
+	require.Equal(t, frame.Rect, golden.Bounds())
+
+	diff := 0
+	for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+		for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+			got := frame.RGBAAt(x, y)
+			want := color.RGBAModel.Convert(golden.At(x, y)).(color.RGBA)
+			if channelDelta(got.R, want.R) > goldenChannelDelta || channelDelta(got.G, want.G) > goldenChannelDelta ||
+				channelDelta(got.B, want.B) > goldenChannelDelta || channelDelta(got.A, want.A) > goldenChannelDelta {
+				diff++
+			}
+		}
+	}
+	if limit := int(goldenMaxDiffShare * float64(frame.Rect.Dx()*frame.Rect.Dy())); diff > limit {
+		actual := filepath.Join(os.TempDir(), name+".png")
+		writePNG(t, actual, frame)
+		t.Errorf("%s: %d pixels differ from the golden image (at most %d allowed); got %s", name, diff, limit, actual)
+	}
+}
+
+func channelDelta(a, b uint8) int {
+	return int(math.Abs(float64(a) - float64(b)))
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, png.Encode(f, img))
+}
+
+// tilted — ориентация, при которой видны три грани.
+var tilted = cube.QuaternionFromAxisAngle(cube.Point3D{X: 1, Y: 1, Z: 0.3}.Normalize(), 0.7)
+
+func TestRenderer_Golden(t *testing.T) {
+	light := &Light{
+		Direction: cube.Point3D{X: -0.4, Y: -0.6, Z: -1},
+		Ambient:   [3]float64{0.5, 0.5, 0.5},
+		Diffuse:   [3]float64{0.6, 0.6, 0.6},
+		Specular:  [3]float64{0.3, 0.3, 0.3},
+		Shininess: 16,
+	}
+	tests := []struct {
+		name   string
+		camera Camera
+		light  *Light
+		dice   []int // Число граней костей сцены
+	}{
+		{name: "d6_orthographic", dice: []int{6}},
+		{name: "d6_perspective", camera: Camera{FOV: 60}, dice: []int{6}},
+		{name: "d6_perspective_lit", camera: Camera{FOV: 60}, light: light, dice: []int{6}},
+		{name: "scene_lit", camera: Camera{FOV: 45, LookAt: cube.Point3D{Y: 10}}, light: light, dice: []int{20, 4, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer()
+			r.Scale, r.Camera, r.Light = 0.5, tt.camera, tt.light
+			models := make([]Model, len(tt.dice))
+			for i, sides := range tt.dice {
+				models[i] = Model{
+					Cube:        texturedDie(t, sides),
+					Orientation: tilted,
+					// Соседние кости частично перекрываются, дальние — правее
+					Position: cube.Point3D{X: float64(i)*90 - float64(len(tt.dice)-1)*45, Y: float64(i%2) * 20, Z: float64(i) * 60},
+				}
+			}
+
+			frame := image.NewRGBA(image.Rect(0, 0, 360, 180))
+			r.Render(frame, models)
+			assertGolden(t, tt.name, frame)
+		})
+	}
+}
+
+func TestRenderer_FaceUp(t *testing.T) {
+	r := NewRenderer()
+	r.Scale = 0.5
+	die := texturedDie(t, 6)
+	frame := image.NewRGBA(image.Rect(0, 0, 120, 120))
+
+	for _, camera := range []Camera{{}, {FOV: 40}} {
+		r.Camera = camera
+		r.Render(frame, []Model{{Cube: die, Orientation: die.TargetOrientation(2)}})
+
+		// Кость с ребром 75 пикселей смотрит выпавшей гранью прямо на зрителя
+		assert.Equal(t, testPalette[2], frame.RGBAAt(70, 70), "The face color is drawn unshaded")
+		assert.Equal(t, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, frame.RGBAAt(30, 30), "The texture is upright: its marker is top left")
+		assert.Equal(t, color.RGBA{}, frame.RGBAAt(5, 5), "The background stays transparent")
+	}
+
+	// Неизвестная текстура рисуется серой
+	die.Faces[2].Texture = nil
+	r.Render(frame, []Model{{Cube: die, Orientation: die.TargetOrientation(2)}})
+	assert.Equal(t, color.RGBA{128, 128, 128, 0xFF}, frame.RGBAAt(60, 60))
+}
+
+func TestRenderer_EvictsTextures(t *testing.T) {
+	r := NewRenderer()
+	die := texturedDie(t, 6)
+	frame := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	model := []Model{{Cube: die, Orientation: die.TargetOrientation(2)}}
+
+	r.Render(frame, model)
+	old := die.Faces[2].Texture
+	require.Contains(t, r.cache, old)
+
+	// Участника заменили: его прежняя текстура больше не рисуется
+	die.Faces[2].Texture = testTexture(color.RGBA{0x10, 0x20, 0x30, 0xFF})
+	for i := 0; i <= textureTTL; i++ {
+		r.Render(frame, model)
+	}
+	assert.NotContains(t, r.cache, old, "A texture that is no longer drawn is evicted")
+	assert.Contains(t, r.cache, die.Faces[2].Texture, "Textures in use stay cached")
+}
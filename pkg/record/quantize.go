@@ -0,0 +1,148 @@
+package record
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+// maxColors — сколько непрозрачных цветов помещается в палитру GIF: нулевой
+// индекс занят прозрачным цветом фона.
+const maxColors = 255
+
+// bucket — цвета кадра, совпадающие в старших пяти битах каждого канала.
+type bucket struct {
+	n       int
+	r, g, b int // Суммы каналов, чтобы взять средний цвет
+}
+
+// key возвращает номер ячейки цвета: по пять старших бит каждого канала.
+func key(r, g, b uint8) int {
+	return int(r>>3)<<10 | int(g>>3)<<5 | int(b>>3)
+}
+
+// channel возвращает пятибитное значение канала ch (0 — R, 1 — G, 2 — B) ячейки k.
+func channel(k, ch int) int {
+	return k >> (10 - 5*ch) & 0x1F
+}
+
+// Quantize переводит кадр в палитру не больше чем из 256 цветов методом медианного
+// сечения: ячейки цветов кадра делятся пополам по самому широкому каналу, пока не
+// наберется 255 групп, и каждая группа заменяется своим средним цветом. Пиксели
+// прозрачнее половины становятся прозрачными.
+func Quantize(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+	buckets := make([]bucket, 1<<15)
+	// Номер ячейки каждого пикселя; -1 — прозрачный
+	keys := make([]int, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if c.A < 0x80 {
+				keys = append(keys, -1)
+				continue
+			}
+			// Пиксели image.RGBA хранятся умноженными на прозрачность
+			r, g, bl := unpremultiply(c.R, c.A), unpremultiply(c.G, c.A), unpremultiply(c.B, c.A)
+			k := key(r, g, bl)
+			buckets[k].n++
+			buckets[k].r += int(r)
+			buckets[k].g += int(g)
+			buckets[k].b += int(bl)
+			keys = append(keys, k)
+		}
+	}
+
+	var used []int
+	for k, bk := range buckets {
+		if bk.n > 0 {
+			used = append(used, k)
+		}
+	}
+	pal := color.Palette{color.RGBA{}}
+	index := make([]uint8, len(buckets))
+	for _, box := range medianCut(used, buckets) {
+		var sum bucket
+		for _, k := range box {
+			sum.n += buckets[k].n
+			sum.r += buckets[k].r
+			sum.g += buckets[k].g
+			sum.b += buckets[k].b
+			index[k] = uint8(len(pal))
+		}
+		pal = append(pal, color.RGBA{uint8(sum.r / sum.n), uint8(sum.g / sum.n), uint8(sum.b / sum.n), 0xFF})
+	}
+
+	out := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), pal)
+	for i, k := range keys {
+		if k >= 0 {
+			out.Pix[i] = index[k]
+		}
+	}
+	return out
+}
+
+// medianCut делит ячейки на не более чем maxColors групп. Каждый раз делится группа
+// с наибольшим числом пикселей среди тех, что еще можно разделить.
+func medianCut(keys []int, buckets []bucket) [][]int {
+	if len(keys) == 0 {
+		return nil
+	}
+	boxes := [][]int{keys}
+	for len(boxes) < maxColors {
+		best, pixels := -1, 0
+		for i, box := range boxes {
+			if n := boxPixels(box, buckets); len(box) > 1 && n > pixels {
+				best, pixels = i, n
+			}
+		}
+		if best < 0 {
+			break // Каждая группа — одна ячейка
+		}
+		box := boxes[best]
+		ch := widestChannel(box)
+		slices.SortFunc(box, func(a, b int) int { return channel(a, ch) - channel(b, ch) })
+		// Медиана по числу пикселей, но обе половины не пустые
+		half, acc, cut := pixels/2, 0, 1
+		for i, k := range box[:len(box)-1] {
+			acc += buckets[k].n
+			cut = i + 1
+			if acc >= half {
+				break
+			}
+		}
+		boxes[best] = box[:cut:cut]
+		boxes = append(boxes, box[cut:])
+	}
+	return boxes
+}
+
+func boxPixels(box []int, buckets []bucket) int {
+	n := 0
+	for _, k := range box {
+		n += buckets[k].n
+	}
+	return n
+}
+
+// widestChannel возвращает канал, значения которого в группе различаются сильнее всего.
+func widestChannel(box []int) int {
+	best, width := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 0x1F, 0
+		for _, k := range box {
+			lo, hi = min(lo, channel(k, ch)), max(hi, channel(k, ch))
+		}
+		if hi-lo > width {
+			best, width = ch, hi-lo
+		}
+	}
+	return best
+}
+
+func unpremultiply(v, a uint8) uint8 {
+	if a == 0xFF {
+		return v
+	}
+	return uint8(min(int(v)*0xFF/int(a), 0xFF))
+}
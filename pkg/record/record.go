@@ -0,0 +1,218 @@
+// Package record записывает анимацию броска: кадры кодируются в фоне
+// в анимированный GIF или в последовательность пронумерованных PNG-файлов.
+package record
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Format — формат записи.
+type Format string
+
+const (
+	GIF Format = "gif" // Анимированный GIF в одном файле
+	PNG Format = "png" // Каталог с файлами frame-0001.png, frame-0002.png...
+)
+
+// ParseFormat разбирает имя формата без учета регистра.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case GIF, PNG:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown recording format %q (expected gif or png)", name)
+}
+
+// Ext возвращает расширение файла записи: ".gif" или пустую строку для каталога PNG.
+func (f Format) Ext() string {
+	if f == GIF {
+		return ".gif"
+	}
+	return ""
+}
+
+// Параметры кодирования.
+const (
+	queueSize = 32              // Сколько кадров может ждать кодировщика, прежде чем Add заблокируется
+	lastHold  = 2 * time.Second // Сколько GIF показывает последний кадр перед повтором
+	minDelay  = 2               // Меньшие задержки кадра GIF браузеры заменяют на 10
+)
+
+// ErrNoFrames возвращается Close, если в запись не попало ни одного кадра.
+var ErrNoFrames = errors.New("no frames were recorded")
+
+// Options — настройки записи.
+type Options struct {
+	Format Format
+	Scale  float64 // Во сколько раз кадр меньше экрана: 0.5 — вдвое; 0 или 1 — без уменьшения
+	FPS    int     // Сколько кадров в секунду сохранять; 0 — каждый тик
+}
+
+// Validate проверяет настройки.
+func (o Options) Validate() error {
+	var errs []error
+	if _, err := ParseFormat(string(o.Format)); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Scale < 0 || o.Scale > 1 {
+		errs = append(errs, fmt.Errorf("recording scale must be in (0, 1], got %g", o.Scale))
+	}
+	if o.FPS < 0 {
+		errs = append(errs, fmt.Errorf("recording fps must not be negative, got %d", o.FPS))
+	}
+	return errors.Join(errs...)
+}
+
+// Recorder принимает кадры одной записи. Кадры уменьшаются и кодируются
+// в отдельной горутине, чтобы не задерживать отрисовку.
+type Recorder struct {
+	path  string
+	opts  Options
+	step  int // Сохраняется каждый step-й тик
+	delay int // Задержка кадра GIF в сотых долях секунды
+	tick  int
+
+	frames chan *image.RGBA
+	done   chan error
+	count  int
+}
+
+// Start начинает запись в path: файл GIF или каталог для PNG. tps — сколько
+// раз в секунду вызывается Due.
+func Start(path string, opts Options, tps int) (*Recorder, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	step := 1
+	if opts.FPS > 0 && opts.FPS < tps {
+		step = int(math.Round(float64(tps) / float64(opts.FPS)))
+	}
+	r := &Recorder{
+		path:   path,
+		opts:   opts,
+		step:   step,
+		delay:  max(int(math.Round(float64(step)*100/float64(tps))), minDelay),
+		frames: make(chan *image.RGBA, queueSize),
+		done:   make(chan error, 1),
+	}
+	go func() {
+		r.done <- r.encode()
+	}()
+	return r, nil
+}
+
+// Path возвращает путь к файлу или каталогу записи.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Frames возвращает, сколько кадров передано в запись.
+func (r *Recorder) Frames() int {
+	return r.count
+}
+
+// Due отсчитывает очередной тик и сообщает, нужно ли сохранить его кадр
+// с учетом Options.FPS.
+func (r *Recorder) Due() bool {
+	due := r.tick%r.step == 0
+	r.tick++
+	return due
+}
+
+// Add передает кадр в запись. Кадр нельзя менять после вызова.
+func (r *Recorder) Add(frame *image.RGBA) {
+	r.count++
+	r.frames <- frame
+}
+
+// Close дожидается, пока все кадры будут закодированы и записаны.
+func (r *Recorder) Close() error {
+	close(r.frames)
+	return <-r.done
+}
+
+// encode уменьшает и кодирует кадры, пока канал не закроется.
+func (r *Recorder) encode() error {
+	var anim gif.GIF
+	n := 0
+	var err error
+	for frame := range r.frames {
+		if err != nil {
+			continue // Дочитываем канал, чтобы Add не заблокировался навсегда
+		}
+		n++
+		frame = scale(frame, r.opts.Scale)
+		switch r.opts.Format {
+		case GIF:
+			anim.Image = append(anim.Image, Quantize(frame))
+			anim.Delay = append(anim.Delay, r.delay)
+			anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+		case PNG:
+			err = r.writePNG(n, frame)
+		}
+	}
+	switch {
+	case err != nil:
+		return err
+	case n == 0:
+		return ErrNoFrames
+	case r.opts.Format == GIF:
+		anim.Delay[n-1] = int(lastHold / (10 * time.Millisecond))
+		return writeGIF(r.path, &anim)
+	}
+	return nil
+}
+
+// writePNG сохраняет n-й кадр последовательности.
+func (r *Recorder) writePNG(n int, frame image.Image) error {
+	if n == 1 {
+		if err := os.MkdirAll(r.path, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(filepath.Join(r.path, fmt.Sprintf("frame-%04d.png", n)))
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, frame); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeGIF(path string, anim *gif.GIF) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// scale уменьшает кадр в factor раз билинейной интерполяцией.
+func scale(frame *image.RGBA, factor float64) *image.RGBA {
+	if factor <= 0 || factor >= 1 {
+		return frame
+	}
+	b := frame.Bounds()
+	w := max(int(math.Round(float64(b.Dx())*factor)), 1)
+	h := max(int(math.Round(float64(b.Dy())*factor)), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Rect, frame, b, xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,125 @@
+package record
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red   = color.RGBA{0xE5, 0x39, 0x35, 0xFF}
+	green = color.RGBA{0x43, 0xA0, 0x47, 0xFF}
+)
+
+// testFrame — кадр 40×20 с прозрачным фоном и двумя цветными квадратами;
+// квадраты сдвигаются вместе с номером кадра.
+func testFrame(n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 4; y < 14; y++ {
+		for x := 0; x < 10; x++ {
+			img.SetRGBA(n+x, y, red)
+			img.SetRGBA(n+x+20, y, green)
+		}
+	}
+	return img
+}
+
+func TestQuantize(t *testing.T) {
+	img := testFrame(0)
+	p := Quantize(img)
+	assert.Len(t, p.Palette, 3, "Transparent background and two colors")
+	assert.Equal(t, color.RGBA{}, p.Palette[0])
+	for _, pt := range []image.Point{{0, 0}, {5, 5}, {25, 5}, {39, 19}} {
+		assert.Equal(t, img.RGBAAt(pt.X, pt.Y), p.At(pt.X, pt.Y), "%v", pt)
+	}
+
+	// Плавный градиент сжимается до 255 цветов, близких к исходным
+	grad := image.NewRGBA(image.Rect(0, 0, 256, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 256; x++ {
+			grad.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y * 4), uint8(255 - x), 0xFF})
+		}
+	}
+	p = Quantize(grad)
+	assert.Len(t, p.Palette, 256)
+	for _, pt := range []image.Point{{0, 0}, {100, 30}, {255, 63}} {
+		want := grad.RGBAAt(pt.X, pt.Y)
+		got := p.At(pt.X, pt.Y).(color.RGBA)
+		assert.InDelta(t, want.R, got.R, 24, "%v", pt)
+		assert.InDelta(t, want.G, got.G, 24, "%v", pt)
+		assert.InDelta(t, want.B, got.B, 24, "%v", pt)
+	}
+}
+
+func TestRecorder_GIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roll.gif")
+	r, err := Start(path, Options{Format: GIF, Scale: 0.5, FPS: 20}, 60)
+	require.NoError(t, err)
+	for tick := 0; tick < 12; tick++ {
+		if r.Due() {
+			r.Add(testFrame(tick))
+		}
+	}
+	assert.Equal(t, 4, r.Frames(), "Every third tick is kept at 20 fps")
+	require.NoError(t, r.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	require.NoError(t, err)
+	require.Len(t, anim.Image, 4)
+	assert.Equal(t, []int{5, 5, 5, 200}, anim.Delay, "The last frame is held")
+	assert.Equal(t, image.Rect(0, 0, 20, 10), anim.Image[0].Bounds(), "Frames are scaled down")
+	assert.Equal(t, uint8(0), anim.Image[0].ColorIndexAt(0, 0))
+	_, _, _, a := anim.Image[0].At(0, 0).RGBA()
+	assert.Zero(t, a, "The background stays transparent")
+}
+
+func TestRecorder_PNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roll")
+	r, err := Start(path, Options{Format: PNG}, 60)
+	require.NoError(t, err)
+	for tick := 0; tick < 3; tick++ {
+		require.True(t, r.Due())
+		r.Add(testFrame(tick))
+	}
+	require.NoError(t, r.Close())
+
+	names, err := filepath.Glob(filepath.Join(path, "*.png"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(path, "frame-0001.png"), filepath.Join(path, "frame-0002.png"), filepath.Join(path, "frame-0003.png"),
+	}, names)
+
+	f, err := os.Open(names[2])
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBAModel.Convert(red), color.RGBAModel.Convert(img.At(2, 5)), "Frames are saved unchanged")
+}
+
+func TestRecorder_Errors(t *testing.T) {
+	_, err := ParseFormat("webm")
+	assert.ErrorContains(t, err, "webm")
+	f, err := ParseFormat("GIF")
+	require.NoError(t, err)
+	assert.Equal(t, GIF, f)
+
+	_, err = Start("x.gif", Options{Format: GIF, Scale: 2}, 60)
+	assert.ErrorContains(t, err, "scale")
+
+	path := filepath.Join(t.TempDir(), "empty.gif")
+	r, err := Start(path, Options{Format: GIF}, 60)
+	require.NoError(t, err)
+	assert.ErrorIs(t, r.Close(), ErrNoFrames)
+	assert.NoFileExists(t, path)
+}
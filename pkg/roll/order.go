@@ -0,0 +1,77 @@
+package roll
+
+import (
+	"log"
+	"slices"
+
+	"github.com/olegshirko/dice_roller/pkg/selection"
+)
+
+// SetPolicy выбирает политику выбора победителя. Политика задает и порядок пула,
+// поэтому, если кость еще не бросали, участники заново раздаются на грани.
+func (sm *StateManager) SetPolicy(p selection.Policy) {
+	sm.Policy = p
+	sm.AssetManager.Orderer = p
+	if sm.LastWinnerIndex >= 0 || slices.Contains(sm.IsWinner, true) || len(sm.AssetManager.AllTextures) == 0 {
+		return
+	}
+	sm.AssetManager.Reshuffle()
+	sm.AssetManager.SetInitialTextures(sm.Cube.Faces, sm.IsGrey)
+}
+
+// LastWinner возвращает участника на грани, выпавшей последним броском, или
+// пустую строку, если кость еще не останавливалась.
+func (sm *StateManager) LastWinner() string {
+	if sm.LastWinnerIndex < 0 {
+		return ""
+	}
+	return sm.AssetManager.LabelOf(sm.Cube.Faces[sm.LastWinnerIndex].Texture)
+}
+
+// Redeal перемешивает всех присутствующих, заново раздает их на грани
+// и начинает новый цикл.
+func (sm *StateManager) Redeal() {
+	sm.AssetManager.Reshuffle()
+	sm.AssetManager.SetInitialTextures(sm.Cube.Faces, sm.IsGrey)
+	for i := range sm.IsWinner {
+		sm.IsWinner[i] = false
+	}
+	sm.LastWinnerIndex = -1
+	sm.Cycle++
+}
+
+// OrderDone сообщает, что очередь выступлений разыграна: пул исчерпан, и все
+// грани в игре уже выиграли.
+func (sm *StateManager) OrderDone() bool {
+	return len(sm.AssetManager.AvailableTextures) == 0 && sm.CycleExhausted()
+}
+
+// DrawOrder заново раздает всех присутствующих и разыгрывает полную очередь
+// выступлений без анимации, как клавиша F в окне. picked вызывается для
+// каждого выпавшего участника сразу после броска, до следующего. Если кость
+// уже брошена, возвращается nil.
+func (sm *StateManager) DrawOrder(picked func(label string)) []string {
+	if sm.Rotating || sm.Snapping {
+		return nil
+	}
+	sm.Redeal()
+	order := []string{}
+	for !sm.OrderDone() {
+		sm.StartRotation()
+		if !sm.Rotating && !sm.Snapping {
+			// Разыгрывать больше некого.
+			sm.Shaking = false
+			break
+		}
+		if !sm.Finish() {
+			break
+		}
+		label := sm.LastWinner()
+		order = append(order, label)
+		if picked != nil {
+			picked(label)
+		}
+	}
+	log.Printf("Stand-up order is ready: %d speakers.", len(order))
+	return order
+}
@@ -0,0 +1,71 @@
+package roll
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rosterManager создает менеджер с участниками names из файла состава.
+func rosterManager(t *testing.T, seed uint64, names ...string) *assets.Manager {
+	path := filepath.Join(t.TempDir(), "team.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(names, "\n")), 0o644))
+	am := assets.NewManager(random.NewPCG(seed))
+	require.True(t, am.LoadRoster(path, ""))
+	return am
+}
+
+func TestDrawOrder(t *testing.T) {
+	names := []string{"Ann", "Bob", "Cid", "Dan", "Eve", "Fay", "Gus", "Hal"}
+	draw := func(seed uint64) ([]string, []string) {
+		rnd := random.NewPCG(seed)
+		sm := NewStateManager(cube.NewCube(), rosterManager(t, seed, names...), rnd, config.Default().Animation)
+		picked := []string{}
+		order := sm.DrawOrder(func(label string) { picked = append(picked, label) })
+		return order, picked
+	}
+
+	order, picked := draw(7)
+	assert.ElementsMatch(t, names, order, "Everyone speaks exactly once, even with more people than faces")
+	assert.Equal(t, order, picked, "picked sees every speaker in order")
+
+	again, _ := draw(7)
+	assert.Equal(t, order, again, "The same seed gives the same order")
+}
+
+func TestSetPolicy_AcrossSessions(t *testing.T) {
+	// Людей больше, чем граней: по одному выбору в день очередь round-robin
+	// должна обойти всех, прежде чем кто-то выступит второй раз.
+	names := []string{"Ann", "Bob", "Cid", "Dan", "Eve", "Fay", "Gus", "Hal"}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var past []history.Entry
+	for day := 0; day < 2*len(names); day++ {
+		rnd := random.NewPCG(uint64(day))
+		sm := NewStateManager(cube.NewCube(), rosterManager(t, uint64(day), names...), rnd, config.Default().Animation)
+		policy, err := selection.New("round-robin", past)
+		require.NoError(t, err)
+		sm.SetPolicy(policy)
+
+		sm.StartRotation()
+		require.True(t, sm.Finish())
+		past = append(past, history.Entry{Time: start.AddDate(0, 0, day), Label: sm.LastWinner(), Cycle: 1})
+	}
+
+	labels := make([]string, len(past))
+	for i, e := range past {
+		labels[i] = e.Label
+	}
+	assert.ElementsMatch(t, names, labels[:len(names)], "Everyone speaks once before anyone speaks twice")
+	assert.Equal(t, labels[:len(names)], labels[len(names):], "The second round keeps the order of the first")
+}
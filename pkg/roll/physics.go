@@ -0,0 +1,111 @@
+package roll
+
+import (
+	"log"
+	"math"
+
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/physics"
+)
+
+// tableToView переводит координаты стола (ось Z вверх) в координаты экрана,
+// где зритель смотрит вдоль +Z: стол виден сверху, верхняя грань обращена к зрителю.
+var tableToView = cube.QuaternionFromAxisAngle(cube.Point3D{X: 1}, math.Pi)
+
+const (
+	throwHeight    = 250.0 // Высота над столом, с которой бросается кость
+	maxMissedLands = 3     // Сколько раз кость может лечь мимо граней в игре, прежде чем бросок закончится доворотом
+)
+
+// throw бросает кость на стол из ее текущего положения со случайными скоростями.
+func (sm *StateManager) throw() {
+	if sm.body == nil || sm.body.Shape != sm.Cube {
+		sm.body = physics.NewBody(sm.Cube)
+	}
+
+	viewToTable := tableToView.Conjugate()
+	sm.body.Orientation = viewToTable.Mul(sm.Orientation)
+
+	start := viewToTable.Rotate(sm.Position)
+	start.Z = throwHeight
+	velocity := cube.Point3D{
+		X: (sm.Random.Float64() - 0.5) * 12,
+		Y: (sm.Random.Float64() - 0.5) * 12,
+	}
+	spin := cube.Point3D{
+		X: (sm.Random.Float64() - 0.5) * 0.6,
+		Y: (sm.Random.Float64() - 0.5) * 0.6,
+		Z: (sm.Random.Float64() - 0.5) * 0.6,
+	}
+	sm.body.Throw(start, velocity, spin)
+}
+
+// updatePhysics продвигает симуляцию броска и, когда кость остановилась,
+// объявляет победителем верхнюю грань. Если кость легла на неактивную грань
+// или встала на ребро, бросок повторяется, но не больше maxMissedLands раз:
+// когда в игре осталась пара граней из двадцати, повторы тянулись бы долго,
+// поэтому дальше кость доворачивается к ближайшей к верху грани в игре.
+// Если победитель задан заранее (RollTo), кость просто доворачивается к нему.
+func (sm *StateManager) updatePhysics() {
+	sm.body.Step(sm.Table)
+	sm.Orientation = tableToView.Mul(sm.body.Orientation)
+	sm.Position = tableToView.Rotate(sm.body.Position)
+
+	if !sm.body.Settled() {
+		return
+	}
+
+	if sm.WinningFaceIndex >= 0 {
+		sm.Rotating = false
+		sm.Snapping = true
+		return
+	}
+
+	face := sm.body.TopFace()
+	if sm.body.Cocked() || sm.IsGrey[face] || sm.IsWinner[face] {
+		sm.missedLands++
+		nearest := sm.nearestLiveFace()
+		if sm.missedLands < maxMissedLands || nearest < 0 {
+			log.Printf("Die landed on face %d, which is not in play. Rolling again.", face)
+			sm.throw()
+			return
+		}
+		log.Printf("Die missed the faces in play %d times; snapping to face %d.", sm.missedLands, nearest)
+		face = nearest
+	}
+
+	sm.WinningFaceIndex = face
+	sm.IsWinner[face] = true
+	sm.TargetOrientation = sm.Cube.TargetOrientation(face)
+	sm.Rotating = false
+	sm.Snapping = true
+}
+
+// nearestLiveFace возвращает грань в игре, больше других обращенную вверх, или -1,
+// если таких граней нет.
+func (sm *StateManager) nearestLiveFace() int {
+	best, bestUp := -1, math.Inf(-1)
+	for i, face := range sm.Cube.Faces {
+		if sm.IsGrey[i] || sm.IsWinner[i] {
+			continue
+		}
+		if up := sm.body.Orientation.Rotate(face.Normal).Dot(physics.Up); up > bestUp {
+			best, bestUp = i, up
+		}
+	}
+	return best
+}
+
+// Collide сталкивает кости sm и other, брошенные на общий стол в физическом
+// режиме. Удар может сдвинуть кость, поэтому ее положение на экране обновляется.
+func (sm *StateManager) Collide(other *StateManager) {
+	if sm.body == nil || other.body == nil || !sm.Rotating && !other.Rotating {
+		return
+	}
+	sm.body.Collide(other.body, sm.Table)
+	for _, s := range []*StateManager{sm, other} {
+		if s.Mode == ModePhysics && s.Rotating {
+			s.Position = tableToView.Rotate(s.body.Position)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Package roll разыгрывает участников на кости: состояние и анимация броска,
+// физический режим, очередь выступлений и проверка доказательств честности.
+// Пакет не зависит от Ebitengine, поэтому им пользуются и окно, и команды без экрана.
+package roll
+
+import (
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/physics"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+	"log"
+	"math"
+)
+
+// Mode определяет, как выбирается выигравшая грань.
+type Mode int
+
+const (
+	// ModePredetermined: победитель выбирается заранее, анимация лишь доводит кость до него.
+	ModePredetermined Mode = iota
+	// ModePhysics: кость бросается на виртуальный стол, побеждает грань, оказавшаяся сверху.
+	ModePhysics
+)
+
+// Phase — фаза броска, как ее видят внешние программы.
+type Phase string
+
+const (
+	PhaseIdle     Phase = "idle"     // Кость ждет броска
+	PhaseRotating Phase = "rotating" // Кость вращается или катится по столу
+	PhaseSnapping Phase = "snapping" // Кость доворачивается к выигравшей грани
+	PhaseShaking  Phase = "shaking"  // Выбирать некого, и кость вздрагивает
+)
+
+// StateManager управляет состоянием игры (вращение, остановка на выигравшей грани).
+// Работает с костью любой формы: количество граней берется из модели.
+type StateManager struct {
+	Cube              *cube.Polyhedron
+	AssetManager      *assets.Manager
+	IsGrey            []bool          // Статус "серости" граней
+	IsWinner          []bool          // Статус "победителя" граней
+	Orientation       cube.Quaternion // Текущая ориентация кости
+	RotationSpeedX    float64         // Скорость вращения вокруг экранной оси X (рад/тик)
+	RotationSpeedY    float64         // Скорость вращения вокруг экранной оси Y (рад/тик)
+	Rotating          bool
+	IdleRotating      bool // Новое состояние для вращения при простое
+	Snapping          bool
+	TargetOrientation cube.Quaternion // Ориентация, при которой выигравшая грань смотрит на зрителя
+	WinningFaceIndex  int
+	LastWinnerIndex   int
+	NeedsToRetireFace bool
+	Position          cube.Point3D // Смещение кости от центра экрана (прыжок, перемещение по столу)
+	jumpVelocity      float64      // Вертикальная скорость для прыжка
+	isJumping         bool         // Флаг активности прыжка
+	Shaking           bool         // Флаг для анимации дрожания
+	shakeProgress     float64      // Прогресс анимации дрожания
+	shakeBase         cube.Quaternion
+	Mode              Mode             // Способ определения победителя
+	Table             physics.Table    // Параметры стола для физического режима
+	body              *physics.Body    // Твердое тело кости в физическом режиме
+	missedLands       int              // Сколько раз текущий бросок лег мимо граней в игре
+	Random            random.Source    // Источник случайности для выбора победителя и параметров броска
+	Cycle             int              // Номер текущего цикла розыгрыша, начиная с 1
+	Policy            selection.Policy // Политика выбора победителя среди граней в игре
+	Animation         config.Animation // Параметры анимации броска
+}
+
+// NewStateManager создает новый менеджер состояний.
+// Все случайные решения (победитель, скорости вращения, бросок) берутся из rnd,
+// поэтому источник с фиксированным зерном воспроизводит броски в точности.
+func NewStateManager(c *cube.Polyhedron, am *assets.Manager, rnd random.Source, anim config.Animation) *StateManager {
+	sm := &StateManager{
+		Cube:             c,
+		AssetManager:     am,
+		Random:           rnd,
+		Animation:        anim,
+		Orientation:      cube.IdentityQuaternion(),
+		Rotating:         false,
+		IdleRotating:     true, // Включаем по умолчанию
+		WinningFaceIndex: -1,
+		LastWinnerIndex:  -1,
+		Cycle:            1,
+		Policy:           selection.Uniform{},
+		isJumping:        false,
+		jumpVelocity:     0,
+		Table:            physics.DefaultTable(),
+		RotationSpeedX:   anim.IdleSpeedX, // Начальная скорость для медленного вращения
+		RotationSpeedY:   anim.IdleSpeedY,
+		Shaking:          false,
+		IsGrey:           make([]bool, len(c.Faces)),
+		IsWinner:         make([]bool, len(c.Faces)),
+	}
+	// Инициализируем грани пустыми текстурами.
+	// Настоящие текстуры раздает менеджер ассетов
+	for i := range sm.Cube.Faces {
+		sm.Cube.Faces[i].Texture = config.EmptyImage
+		sm.IsGrey[i] = true
+		sm.IsWinner[i] = false
+	}
+	return sm
+}
+
+// Phase возвращает текущую фазу броска.
+func (sm *StateManager) Phase() Phase {
+	switch {
+	case sm.Rotating:
+		return PhaseRotating
+	case sm.Snapping:
+		return PhaseSnapping
+	case sm.Shaking:
+		return PhaseShaking
+	}
+	return PhaseIdle
+}
+
+// CycleExhausted сообщает, что в цикле не осталось граней, которые еще не выигрывали.
+func (sm *StateManager) CycleExhausted() bool {
+	for i := range sm.IsGrey {
+		if !sm.IsGrey[i] && !sm.IsWinner[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// StartRotation инкапсулирует логику запуска вращения куба.
+func (sm *StateManager) StartRotation() {
+	// Останавливаем фоновое вращение и сбрасываем скорости перед запуском основного цикла
+	if sm.IdleRotating {
+		sm.IdleRotating = false
+		sm.RotationSpeedX = 0
+		sm.RotationSpeedY = 0
+	}
+
+	if sm.Rotating || sm.Snapping {
+		return
+	}
+
+	// 1. Собираем все валидные (не серые и не выигравшие) грани
+	validFaceIndices := []int{}
+	for i := range sm.IsGrey {
+		if !sm.IsGrey[i] && !sm.IsWinner[i] {
+			validFaceIndices = append(validFaceIndices, i)
+		}
+	}
+
+	// 2. Если валидных граней нет, возможно, пора начать новый цикл
+	if len(validFaceIndices) == 0 {
+		anyActiveFaces := false
+		for i := range sm.IsGrey {
+			if !sm.IsGrey[i] {
+				anyActiveFaces = true
+				if sm.IsWinner[i] {
+					sm.AssetManager.ReplaceFaceTexture(i, sm.Cube.Faces, sm.IsGrey)
+					if !sm.IsGrey[i] {
+						sm.IsWinner[i] = false
+						validFaceIndices = append(validFaceIndices, i)
+					}
+				} else {
+					validFaceIndices = append(validFaceIndices, i)
+				}
+			}
+		}
+		if anyActiveFaces {
+			sm.Cycle++
+		} else {
+			log.Println("All faces are grey, no new cycle possible.")
+		}
+	}
+
+	// 3. Если есть из чего выбирать, выбираем победителя
+
+	// Подсчитываем активные (не серые) грани, чтобы определить, финальный ли это раунд
+	activeFaceCount := 0
+	for i := range sm.IsGrey {
+		if !sm.IsGrey[i] {
+			activeFaceCount++
+		}
+	}
+
+	// Эффект "примагничивания" (snap) без вращения,
+	// только если это последняя доступная грань в финальном раунде (когда осталось <=2 активных граней).
+	if len(validFaceIndices) == 1 && activeFaceCount <= 2 {
+		sm.WinningFaceIndex = validFaceIndices[0]
+		sm.IsWinner[sm.WinningFaceIndex] = true
+		sm.TargetOrientation = sm.Cube.TargetOrientation(sm.WinningFaceIndex)
+
+		sm.Snapping = true
+		sm.Rotating = false
+
+	} else if len(validFaceIndices) > 0 && sm.Mode == ModePhysics {
+		// В физическом режиме победителя определит сам бросок
+		sm.WinningFaceIndex = -1
+		sm.missedLands = 0
+		sm.throw()
+
+		sm.Rotating = true
+		sm.Snapping = false
+	} else if len(validFaceIndices) > 0 {
+		// Полноценное вращение для всех остальных случаев
+		// Выбираем победителя: если остался один, то он и есть, иначе - случайный
+		if len(validFaceIndices) == 1 {
+			sm.WinningFaceIndex = validFaceIndices[0]
+		} else {
+			sm.WinningFaceIndex = validFaceIndices[sm.pick(validFaceIndices)]
+		}
+		sm.IsWinner[sm.WinningFaceIndex] = true
+		sm.TargetOrientation = sm.Cube.TargetOrientation(sm.WinningFaceIndex)
+		sm.spin()
+	} else {
+		// Если нет доступных граней, запускаем анимацию дрожания
+		sm.Shaking = true
+		sm.shakeProgress = 0
+		sm.shakeBase = sm.Orientation
+	}
+}
+
+// RollTo запускает бросок, который остановится на грани face. Результат уже
+// определен вызывающим (например, выражением с костями), поэтому отметки граней
+// и политика выбора не используются. В физическом режиме кость бросается на стол,
+// а остановившись, доворачивается к грани face.
+func (sm *StateManager) RollTo(face int) {
+	if sm.Rotating || sm.Snapping {
+		return
+	}
+	sm.IdleRotating = false
+	sm.Shaking = false
+	sm.WinningFaceIndex = face
+	sm.TargetOrientation = sm.Cube.TargetOrientation(face)
+	if sm.Mode == ModePhysics {
+		sm.throw()
+		sm.Rotating = true
+		sm.Snapping = false
+		return
+	}
+	sm.spin()
+}
+
+// spin раскручивает кость со случайными скоростями и подбрасывает ее.
+// Когда вращение затухнет, кость довернется к TargetOrientation.
+func (sm *StateManager) spin() {
+	sm.RotationSpeedX = (sm.Random.Float64() - 0.5) * 0.4
+	sm.RotationSpeedY = (sm.Random.Float64() - 0.5) * 0.4
+	if math.Abs(sm.RotationSpeedX) < 0.05 && math.Abs(sm.RotationSpeedY) < 0.05 {
+		sm.RotationSpeedX = 0.15 + sm.Random.Float64()*0.1
+	}
+
+	sm.Rotating = true
+	sm.Snapping = false
+	sm.isJumping = true
+	sm.jumpVelocity = sm.Animation.JumpVelocity // Начальная скорость прыжка вверх
+}
+
+// ScaleJump меняет высоту прыжка только что брошенной кости: k = 1 — обычный прыжок.
+func (sm *StateManager) ScaleJump(k float64) {
+	sm.jumpVelocity = sm.Animation.JumpVelocity * k
+}
+
+// pick выбирает победителя среди граней faceIndices с помощью политики выбора.
+// Политика видит имена участников на гранях, а не индексы граней.
+func (sm *StateManager) pick(faceIndices []int) int {
+	labels := make([]string, len(faceIndices))
+	for i, face := range faceIndices {
+		labels[i] = sm.AssetManager.LabelOf(sm.Cube.Faces[face].Texture)
+	}
+	return sm.Policy.Pick(labels, sm.Random)
+}
+
+// UpdateState обновляет состояние вращения и переходы между фазами.
+// Возвращает true, если спин только что завершился.
+func (sm *StateManager) UpdateState() (spinFinished bool) {
+	spinFinished = false
+
+	if sm.isJumping {
+		sm.jumpVelocity += sm.Animation.Gravity
+		sm.Position.Y += sm.jumpVelocity
+
+		if sm.Position.Y >= 0 {
+			sm.Position.Y = 0
+			sm.jumpVelocity = -sm.jumpVelocity * sm.Animation.Bounce // Отскок с затуханием
+		}
+	}
+
+	if sm.IdleRotating {
+		sm.Rotate(sm.RotationSpeedX, sm.RotationSpeedY)
+	} else if sm.Snapping {
+		// Одним движением по кратчайшей дуге поворачиваем кость к выигравшей грани,
+		// сразу выставляя ее "верх" вверх экрана.
+		sm.Orientation = cube.Slerp(sm.Orientation, sm.TargetOrientation, sm.Animation.SnapSpeed)
+
+		if sm.Orientation.AngleTo(sm.TargetOrientation) < 0.001 {
+			sm.Orientation = sm.TargetOrientation
+			sm.Snapping = false
+
+			sm.LastWinnerIndex = sm.WinningFaceIndex
+			sm.NeedsToRetireFace = true
+			sm.WinningFaceIndex = -1
+			spinFinished = true
+		}
+	} else if sm.Rotating {
+		// ... (код состояния Rotating)
+		if sm.NeedsToRetireFace {
+			sm.LastWinnerIndex = -1
+			sm.NeedsToRetireFace = false
+		}
+
+		if sm.Mode == ModePhysics {
+			sm.updatePhysics()
+			return
+		}
+
+		sm.Rotate(sm.RotationSpeedX, sm.RotationSpeedY)
+
+		sm.RotationSpeedX *= sm.Animation.Decay
+		sm.RotationSpeedY *= sm.Animation.Decay
+
+		// Логируем текущие скорости, чтобы видеть их затухание
+		// log.Printf("UpdateState: Rotating... SpeedX: %.4f, SpeedY: %.4f", sm.RotationSpeedX, sm.RotationSpeedY)
+
+		if math.Abs(sm.RotationSpeedX) < sm.Animation.StopSpeed && math.Abs(sm.RotationSpeedY) < sm.Animation.StopSpeed {
+			sm.Rotating = false
+			sm.isJumping = false
+			sm.Position.Y = 0
+			sm.jumpVelocity = 0
+			sm.Snapping = true
+		}
+	} else if sm.Shaking {
+		sm.shakeProgress += sm.Animation.ShakeSpeed
+
+		// Используем синусоиду для создания эффекта дрожания,
+		// покачивая кость вокруг диагональной оси
+		offset := math.Sin(sm.shakeProgress) * sm.Animation.ShakeMagnitude
+		shake := cube.QuaternionFromAxisAngle(cube.Point3D{X: 1, Y: -1}, offset)
+		sm.Orientation = shake.Mul(sm.shakeBase)
+
+		// Завершаем анимацию после двух полных циклов синусоиды
+		if sm.shakeProgress >= math.Pi*4 {
+			sm.Shaking = false
+			sm.shakeProgress = 0
+			// Возвращаем исходную ориентацию, чтобы дрожание не смещало кость
+			sm.Orientation = sm.shakeBase
+		}
+	}
+	return
+}
+
+// maxFinishTicks ограничивает длительность одного броска при доведении его до конца без анимации.
+const maxFinishTicks = 100000
+
+// Finish доводит текущий бросок до конца без анимации.
+// Возвращает true, если бросок был и завершился.
+func (sm *StateManager) Finish() bool {
+	if !sm.Rotating && !sm.Snapping {
+		return false
+	}
+	for tick := 0; tick < maxFinishTicks; tick++ {
+		if sm.UpdateState() {
+			return true
+		}
+	}
+	return false
+}
+
+// Rotate поворачивает кость вокруг экранных осей X и Y на заданные углы.
+func (sm *StateManager) Rotate(angleX, angleY float64) {
+	rotX := cube.QuaternionFromAxisAngle(cube.Point3D{X: 1}, angleX)
+	rotY := cube.QuaternionFromAxisAngle(cube.Point3D{Y: 1}, angleY)
+	sm.Orientation = rotX.Mul(rotY).Mul(sm.Orientation).Normalize()
+}
@@ -0,0 +1,368 @@
+package roll
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/olegshirko/dice_roller/pkg/selection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStateManager(t *testing.T) {
+	c := cube.NewCube()
+	am := assets.NewManager(random.NewPCG(1))
+	sm := NewStateManager(c, am, random.NewPCG(1), config.Default().Animation)
+
+	assert.NotNil(t, sm)
+	assert.Equal(t, c, sm.Cube)
+	assert.Equal(t, am, sm.AssetManager)
+	assert.False(t, sm.Rotating, "Rotating should be false on init")
+	assert.True(t, sm.IdleRotating, "IdleRotating should be true on init")
+	assert.Equal(t, -1, sm.WinningFaceIndex, "WinningFaceIndex should be -1 on init")
+	assert.Equal(t, -1, sm.LastWinnerIndex, "LastWinnerIndex should be -1 on init")
+	assert.False(t, sm.Shaking, "Shaking should be false on init")
+
+	assert.Len(t, sm.IsGrey, 6)
+	assert.Len(t, sm.IsWinner, 6)
+	for i := 0; i < 6; i++ {
+		assert.True(t, sm.IsGrey[i], "Face %d should be grey on init", i)
+		assert.False(t, sm.IsWinner[i], "Face %d should not be a winner on init", i)
+		assert.Equal(t, config.EmptyImage, sm.Cube.Faces[i].Texture, "Face %d should have empty texture on init", i)
+	}
+}
+
+func TestStartRotation(t *testing.T) {
+	// Сценарий 1: Нормальное вращение
+	t.Run("Normal Rotation", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		// Делаем несколько граней активными (не серыми)
+		sm.IsGrey[0] = false
+		sm.IsGrey[1] = false
+		sm.IsGrey[2] = false
+
+		sm.StartRotation()
+
+		assert.True(t, sm.Rotating, "Should be in Rotating state")
+		assert.False(t, sm.Snapping, "Should not be in Snapping state")
+		assert.False(t, sm.IdleRotating, "IdleRotating should be disabled")
+		assert.Contains(t, []int{0, 1, 2}, sm.WinningFaceIndex, "Winning face should be one of the valid faces")
+		assert.True(t, sm.IsWinner[sm.WinningFaceIndex], "Winning face should be marked as winner")
+	})
+
+	// Сценарий 2: Последняя доступная грань
+	t.Run("Last Available Face Snap", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		// Все грани неактивны, кроме одной
+		for i := range sm.IsGrey {
+			sm.IsGrey[i] = true
+		}
+		sm.IsGrey[3] = false
+
+		sm.StartRotation()
+
+		assert.False(t, sm.Rotating, "Should not be in Rotating state")
+		assert.True(t, sm.Snapping, "Should be in Snapping state")
+		assert.Equal(t, 3, sm.WinningFaceIndex, "Winning face should be the last available one")
+		assert.True(t, sm.IsWinner[3], "The winning face should be marked as winner")
+	})
+
+	// Сценарий 3: Нет доступных граней (все серые, но не выигравшие)
+	t.Run("No Available Faces Shake", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		// Все грани активны, но уже выиграли
+		for i := range sm.IsGrey {
+			sm.IsGrey[i] = false
+			sm.IsWinner[i] = true
+		}
+		// ... кроме одной, которая серая
+		sm.IsGrey[5] = true
+
+		sm.StartRotation()
+
+		assert.True(t, sm.Shaking, "Should be in Shaking state")
+		assert.False(t, sm.Rotating, "Should not be in Rotating state")
+	})
+
+	// Сценарий 4: Перезапуск цикла
+	t.Run("Restart Cycle when all are winners", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		// Добавляем "фейковые" текстуры, чтобы было что заменять
+		am.AvailableTextures = make([]image.Image, 6)
+		for i := 0; i < 6; i++ {
+			am.AvailableTextures[i] = image.NewRGBA(image.Rect(0, 0, 1, 1))
+		}
+
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		// Все грани не серые и все выиграли
+		for i := 0; i < 6; i++ {
+			sm.IsGrey[i] = false
+			sm.IsWinner[i] = true
+		}
+
+		initialTextureCount := len(am.AvailableTextures)
+		sm.StartRotation()
+
+		assert.True(t, sm.Rotating, "Should start rotating again")
+		// Проверяем, что текстуры были заменены (их количество уменьшилось)
+		assert.Less(t, len(am.AvailableTextures), initialTextureCount, "Available textures should decrease after replacing faces")
+
+		// Проверяем, что статусы IsWinner были сброшены
+		for i := 0; i < 6; i++ {
+			if i == sm.WinningFaceIndex {
+				assert.True(t, sm.IsWinner[i], "New winning face should be marked as winner")
+			} else {
+				assert.False(t, sm.IsWinner[i], "Non-winning faces should have their winner status reset")
+			}
+		}
+	})
+}
+
+// TestStartRotation_Polyhedron проверяет, что менеджер состояний работает с костью, отличной от куба.
+func TestStartRotation_Polyhedron(t *testing.T) {
+	d20 := cube.NewIcosahedron()
+	sm := NewStateManager(d20, assets.NewManager(random.NewPCG(1)), random.NewPCG(1), config.Default().Animation)
+	assert.Len(t, sm.IsGrey, 20)
+	assert.Len(t, sm.IsWinner, 20)
+
+	sm.IsGrey[7] = false
+	sm.IsGrey[15] = false
+	sm.IsGrey[19] = false
+
+	sm.StartRotation()
+
+	assert.True(t, sm.Rotating, "Should be in Rotating state")
+	assert.Contains(t, []int{7, 15, 19}, sm.WinningFaceIndex, "Winning face should be one of the valid faces")
+	assert.Equal(t, d20.TargetOrientation(sm.WinningFaceIndex), sm.TargetOrientation)
+}
+
+// TestPhysicsRoll проверяет бросок в физическом режиме: победителем становится
+// верхняя грань остановившейся кости, и только среди граней, находящихся в игре.
+func TestPhysicsRoll(t *testing.T) {
+	sm := NewStateManager(cube.NewCube(), assets.NewManager(random.NewPCG(1)), random.NewPCG(1), config.Default().Animation)
+	sm.Mode = ModePhysics
+	sm.IsGrey[0] = false
+	sm.IsGrey[2] = false
+	sm.IsGrey[4] = false
+
+	sm.StartRotation()
+
+	assert.True(t, sm.Rotating, "Should be in Rotating state")
+	assert.Equal(t, -1, sm.WinningFaceIndex, "Winner should not be known before the die lands")
+
+	spinFinished := false
+	for i := 0; i < 20*sm.Table.MaxTicks && !spinFinished; i++ {
+		spinFinished = sm.UpdateState()
+	}
+
+	assert.True(t, spinFinished, "The roll should finish")
+	assert.Contains(t, []int{0, 2, 4}, sm.LastWinnerIndex, "Winner should be one of the faces in play")
+	assert.True(t, sm.IsWinner[sm.LastWinnerIndex], "Winning face should be marked as winner")
+	assert.Equal(t, sm.Cube.TargetOrientation(sm.LastWinnerIndex), sm.Orientation, "Winning face should end up facing the viewer")
+}
+
+func TestPhysicsRoll_MissedLandings(t *testing.T) {
+	// Все грани активны, но девятнадцать из двадцати уже выиграли: кость бросается
+	// на стол и почти всегда ложится мимо единственной грани в игре
+	for seed := uint64(1); seed <= 5; seed++ {
+		die, err := cube.NewPolyhedron(20)
+		require.NoError(t, err)
+		sm := NewStateManager(die, assets.NewManager(random.NewPCG(seed)), random.NewPCG(seed), config.Default().Animation)
+		sm.Mode = ModePhysics
+		for i := range sm.IsGrey {
+			sm.IsGrey[i] = false
+			sm.IsWinner[i] = i != 7
+		}
+
+		sm.StartRotation()
+		require.True(t, sm.Rotating, "seed %d: the die should be thrown", seed)
+		require.NotNil(t, sm.body)
+
+		spinFinished := false
+		for i := 0; i < (maxMissedLands+1)*sm.Table.MaxTicks && !spinFinished; i++ {
+			spinFinished = sm.UpdateState()
+		}
+
+		require.True(t, spinFinished, "seed %d: the roll should end after at most %d missed landings", seed, maxMissedLands)
+		assert.Equal(t, maxMissedLands, sm.missedLands, "seed %d", seed)
+		assert.Equal(t, 7, sm.LastWinnerIndex, "seed %d", seed)
+		assert.Equal(t, sm.Cube.TargetOrientation(7), sm.Orientation, "seed %d: the live face should end up facing the viewer", seed)
+	}
+}
+
+func TestUpdateState(t *testing.T) {
+	// Тест перехода Rotating -> Snapping
+	t.Run("Rotating to Snapping", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		sm.IdleRotating = false // Отключаем, чтобы тестировать именно Rotating
+		sm.Rotating = true
+		sm.RotationSpeedX = 0.005 // Малая скорость для быстрого перехода
+		sm.RotationSpeedY = 0.005
+
+		sm.UpdateState()
+
+		assert.False(t, sm.Rotating, "Should exit Rotating state")
+		assert.True(t, sm.Snapping, "Should enter Snapping state")
+	})
+
+	// Тест перехода Snapping -> Finished
+	t.Run("Snapping to Finished", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		sm.IdleRotating = false // Отключаем, чтобы тестировать именно Snapping
+		sm.Snapping = true
+		sm.WinningFaceIndex = 2
+		sm.TargetOrientation = sm.Cube.TargetOrientation(sm.WinningFaceIndex)
+		// Устанавливаем ориентацию близко к целевой для быстрого перехода
+		nudge := cube.QuaternionFromAxisAngle(cube.Point3D{X: 1}, 0.0001)
+		sm.Orientation = nudge.Mul(sm.TargetOrientation)
+
+		spinFinished := sm.UpdateState()
+
+		assert.True(t, spinFinished, "UpdateState should return true to indicate spin finished")
+		assert.False(t, sm.Snapping, "Should exit Snapping state")
+		assert.Equal(t, sm.TargetOrientation, sm.Orientation, "Orientation should be set exactly to the target")
+		assert.True(t, sm.NeedsToRetireFace, "NeedsToRetireFace should be true")
+		assert.Equal(t, 2, sm.LastWinnerIndex, "LastWinnerIndex should be updated")
+		assert.Equal(t, -1, sm.WinningFaceIndex, "WinningFaceIndex should be reset")
+	})
+
+	// Тест плавного доворота: за один тик кость приближается к цели, но еще не достигает ее
+	t.Run("Snapping moves towards target", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		sm.IdleRotating = false
+		sm.Snapping = true
+		sm.WinningFaceIndex = 4
+		sm.TargetOrientation = sm.Cube.TargetOrientation(sm.WinningFaceIndex)
+		initialDistance := sm.Orientation.AngleTo(sm.TargetOrientation)
+
+		spinFinished := sm.UpdateState()
+
+		assert.False(t, spinFinished, "Spin should not finish after a single snapping step")
+		assert.True(t, sm.Snapping, "Should stay in Snapping state")
+		assert.Less(t, sm.Orientation.AngleTo(sm.TargetOrientation), initialDistance, "Orientation should get closer to the target")
+	})
+
+	// Тест дрожания: после анимации ориентация возвращается к исходной
+	t.Run("Shaking restores orientation", func(t *testing.T) {
+		am := assets.NewManager(random.NewPCG(1))
+		sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+		sm.IdleRotating = false
+		sm.Orientation = cube.QuaternionFromAxisAngle(cube.Point3D{Y: 1}, 0.3)
+		initial := sm.Orientation
+		sm.Shaking = true
+		sm.shakeBase = initial
+
+		for i := 0; i < 100 && sm.Shaking; i++ {
+			sm.UpdateState()
+		}
+
+		assert.False(t, sm.Shaking, "Shaking should finish")
+		assert.Equal(t, initial, sm.Orientation, "Orientation should be restored after shaking")
+	})
+}
+
+// TestStartRotation_Reproducible проверяет, что одинаковое зерно дает одинаковых победителей и вращение.
+func TestStartRotation_Reproducible(t *testing.T) {
+	roll := func(seed uint64) (winners []int, speeds [][2]float64) {
+		sm := NewStateManager(cube.NewCube(), assets.NewManager(random.NewPCG(seed)), random.NewPCG(seed), config.Default().Animation)
+		for i := range sm.IsGrey {
+			sm.IsGrey[i] = false
+		}
+		for n := 0; n < 4; n++ {
+			sm.StartRotation()
+			winners = append(winners, sm.WinningFaceIndex)
+			speeds = append(speeds, [2]float64{sm.RotationSpeedX, sm.RotationSpeedY})
+			for !sm.UpdateState() {
+			}
+		}
+		return winners, speeds
+	}
+
+	winnersA, speedsA := roll(42)
+	winnersB, speedsB := roll(42)
+	assert.Equal(t, winnersA, winnersB, "Same seed should pick the same winners")
+	assert.Equal(t, speedsA, speedsB, "Same seed should give the same spin")
+}
+
+// TestStartRotation_Scripted проверяет, что победитель берется из источника случайности.
+func TestStartRotation_Scripted(t *testing.T) {
+	rnd := random.NewScripted([]int{2}, []float64{0.9, 0.1})
+	sm := NewStateManager(cube.NewCube(), assets.NewManager(rnd), rnd, config.Default().Animation)
+	sm.IsGrey[1] = false
+	sm.IsGrey[3] = false
+	sm.IsGrey[5] = false
+
+	sm.StartRotation()
+
+	assert.Equal(t, 5, sm.WinningFaceIndex, "Scripted index 2 should pick the third valid face")
+	assert.InDelta(t, 0.16, sm.RotationSpeedX, 1e-9)
+	assert.InDelta(t, -0.16, sm.RotationSpeedY, 1e-9)
+}
+
+// TestStartRotation_Policy проверяет, что победитель выбирается политикой по именам участников.
+func TestStartRotation_Policy(t *testing.T) {
+	am := assets.NewManager(random.NewPCG(1))
+	sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), config.Default().Animation)
+	for i, name := range []string{"anna", "bob", "carol"} {
+		tex := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		am.AddTexture(tex, name)
+		sm.Cube.Faces[i*2].Texture = tex
+		sm.IsGrey[i*2] = false
+	}
+	now := time.Now()
+	sm.Policy = selection.NewRoundRobin([]history.Entry{
+		{Time: now.AddDate(0, 0, -1), Label: "anna"},
+		{Time: now.AddDate(0, 0, -3), Label: "bob"},
+		{Time: now.AddDate(0, 0, -2), Label: "carol"},
+	})
+
+	sm.StartRotation()
+
+	assert.Equal(t, 2, sm.WinningFaceIndex, "bob waited the longest")
+}
+
+func TestUpdateState_Animation(t *testing.T) {
+	am := assets.NewManager(random.NewPCG(1))
+	anim := config.Default().Animation
+	anim.SnapSpeed = 1
+	sm := NewStateManager(cube.NewCube(), am, random.NewPCG(1), anim)
+	for i := range sm.IsGrey {
+		sm.IsGrey[i] = i > 1 // Две активные грани: последняя доводится без вращения
+	}
+	sm.IsWinner[0] = true
+
+	sm.StartRotation()
+	assert.True(t, sm.Snapping)
+	assert.True(t, sm.UpdateState(), "With snap speed 1 the die reaches the winner in a single tick")
+	assert.Equal(t, 1, sm.LastWinnerIndex)
+}
+
+func TestRollTo(t *testing.T) {
+	d20, err := cube.NewPolyhedron(20)
+	assert.NoError(t, err)
+	for _, mode := range []Mode{ModePredetermined, ModePhysics} {
+		sm := NewStateManager(d20, assets.NewManager(random.NewPCG(1)), random.NewPCG(1), config.Default().Animation)
+		sm.Mode = mode
+
+		for _, face := range []int{17, 3, 3} {
+			sm.RollTo(face)
+			assert.True(t, sm.Rotating, "The die should spin before settling")
+			assert.True(t, sm.Finish())
+			assert.Equal(t, face, sm.LastWinnerIndex, "The die should land on the requested face")
+			assert.Less(t, sm.Orientation.AngleTo(d20.TargetOrientation(face)), 1e-6)
+		}
+		assert.False(t, sm.IsWinner[3], "Forced rolls do not retire faces")
+	}
+}
@@ -0,0 +1,78 @@
+package roll
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/olegshirko/dice_roller/pkg/assets"
+	"github.com/olegshirko/dice_roller/pkg/config"
+	"github.com/olegshirko/dice_roller/pkg/cube"
+	"github.com/olegshirko/dice_roller/pkg/fairness"
+	"github.com/olegshirko/dice_roller/pkg/random"
+)
+
+// VerifyProof повторяет цикл розыгрыша по раскрытому доказательству и проверяет,
+// что каждый WinningFaceIndex следует из зерна и исходного состояния.
+func VerifyProof(p *fairness.Proof) error {
+	if err := p.CheckCommitment(); err != nil {
+		return err
+	}
+	die, err := cube.NewPolyhedron(p.Sides)
+	if err != nil {
+		return err
+	}
+	if len(p.Faces) != len(die.Faces) {
+		return fmt.Errorf("proof lists %d faces, %s has %d", len(p.Faces), die.Name, len(die.Faces))
+	}
+
+	rnd := random.NewPCG(*p.Seed)
+	am := assets.NewManager(rnd)
+	// Для повтора достаточно различимых текстур-заглушек с теми же именами.
+	textures := map[string]image.Image{}
+	texture := func(label string) image.Image {
+		if tex, ok := textures[label]; ok {
+			return tex
+		}
+		tex := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		am.AddTexture(tex, label)
+		textures[label] = tex
+		return tex
+	}
+
+	// Параметры анимации на выбор победителя не влияют, поэтому достаточно значений по умолчанию.
+	sm := NewStateManager(die, am, rnd, config.Default().Animation)
+	sm.IdleRotating = false
+	if p.Physics {
+		sm.Mode = ModePhysics
+	}
+	for i, face := range p.Faces {
+		sm.IsGrey[i] = face.Grey
+		sm.IsWinner[i] = face.Winner
+		if face.Grey {
+			die.Faces[i].Texture = config.GreyImage
+		} else {
+			die.Faces[i].Texture = texture(face.Label)
+		}
+	}
+	for _, label := range p.Pool {
+		am.AvailableTextures = append(am.AvailableTextures, texture(label))
+	}
+
+	for n, draw := range p.Draws {
+		o := draw.Orientation
+		sm.Orientation = cube.Quaternion{W: o[0], X: o[1], Y: o[2], Z: o[3]}
+		sm.Position = cube.Point3D{X: draw.Position[0], Y: draw.Position[1], Z: draw.Position[2]}
+		sm.StartRotation()
+		if !sm.Finish() {
+			return fmt.Errorf("draw %d: replayed roll did not finish", n+1)
+		}
+
+		face := sm.LastWinnerIndex
+		label := am.LabelOf(die.Faces[face].Texture)
+		if face != draw.Face || label != draw.Label {
+			return fmt.Errorf("draw %d: proof claims face %d (%q), replay gives face %d (%q)",
+				n+1, draw.Face, draw.Label, face, label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Package selection содержит политики выбора победителя среди граней, находящихся в игре.
+// Политики, учитывающие журнал результатов, делают очередность справедливой
+// не только внутри одного цикла, но и между запусками.
+package selection
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+)
+
+// Policy выбирает победителя среди кандидатов.
+type Policy interface {
+	// Pick возвращает индекс выбранного кандидата. candidates — имена участников
+	// на гранях в игре, их всегда больше одного.
+	Pick(candidates []string, rnd random.Source) int
+	// Observe сообщает политике о новом результате.
+	Observe(e history.Entry)
+	// Forget сообщает политике, что результат e отменен, как будто его не было.
+	Forget(e history.Entry)
+	// Order возвращает индексы labels в том порядке, в котором участники попадают
+	// из пула на грани: первым — тот, кого политика выбрала бы первым. Так участник,
+	// которого политика ждет, не застревает в пуле, когда людей больше, чем граней.
+	Order(labels []string, rnd random.Source) []int
+}
+
+// Names перечисляет политики, доступные через New.
+var Names = []string{"uniform", "weighted", "round-robin"}
+
+// New создает политику по имени, учитывая прошлые результаты entries.
+func New(name string, entries []history.Entry) (Policy, error) {
+	switch name {
+	case "uniform":
+		return Uniform{}, nil
+	case "weighted":
+		return NewWeighted(entries), nil
+	case "round-robin":
+		return NewRoundRobin(entries), nil
+	default:
+		return nil, fmt.Errorf("unknown selection policy %q (supported: %v)", name, Names)
+	}
+}
+
+// Uniform выбирает кандидата равновероятно, не глядя на историю.
+type Uniform struct{}
+
+// Pick реализует Policy.
+func (Uniform) Pick(candidates []string, rnd random.Source) int {
+	return rnd.Intn(len(candidates))
+}
+
+// Observe реализует Policy.
+func (Uniform) Observe(history.Entry) {}
+
+// Forget реализует Policy.
+func (Uniform) Forget(history.Entry) {}
+
+// Order реализует Policy: участники просто перемешиваются.
+func (Uniform) Order(labels []string, rnd random.Source) []int {
+	order := make([]int, len(labels))
+	for i := range order {
+		order[i] = i
+	}
+	rnd.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	return order
+}
+
+// orderByPicks выстраивает labels очередью, раз за разом выбирая pick следующего
+// из оставшихся.
+func orderByPicks(pick func(candidates []string, rnd random.Source) int, labels []string, rnd random.Source) []int {
+	rest := make([]int, len(labels))
+	for i := range rest {
+		rest[i] = i
+	}
+	order := make([]int, 0, len(labels))
+	for len(rest) > 1 {
+		candidates := make([]string, len(rest))
+		for k, i := range rest {
+			candidates[k] = labels[i]
+		}
+		k := pick(candidates, rnd)
+		order = append(order, rest[k])
+		rest = slices.Delete(rest, k, k+1)
+	}
+	return append(order, rest...)
+}
+
+// lastPicks хранит время всех выборов каждого участника, чтобы отмененный выбор
+// можно было забыть и вернуться к предыдущему.
+type lastPicks map[string][]time.Time
+
+func newLastPicks(entries []history.Entry) lastPicks {
+	l := lastPicks{}
+	for _, e := range entries {
+		l.observe(e)
+	}
+	return l
+}
+
+func (l lastPicks) observe(e history.Entry) {
+	l[e.Label] = append(l[e.Label], e.Time)
+}
+
+func (l lastPicks) forget(e history.Entry) {
+	times := l[e.Label]
+	if i := slices.IndexFunc(times, e.Time.Equal); i >= 0 {
+		l[e.Label] = slices.Delete(times, i, i+1)
+	}
+	if len(l[e.Label]) == 0 {
+		delete(l, e.Label)
+	}
+}
+
+// latest возвращает время последнего выбора участника label; ok ложно, если его не выбирали.
+func (l lastPicks) latest(label string) (last time.Time, ok bool) {
+	for _, t := range l[label] {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last, len(l[label]) > 0
+}
+
+// Weighted выбирает кандидата с вероятностью, пропорциональной числу дней
+// с его последнего выбора. Кто давно не выбирался, выбирается чаще; выбранный
+// сегодня сохраняет небольшой шанс, чтобы очередность не становилась предсказуемой.
+type Weighted struct {
+	Now     func() time.Time
+	MinDays float64 // Вес только что выбранного участника
+	MaxDays float64 // Вес никогда не выбиравшегося участника и предел для остальных
+	last    lastPicks
+}
+
+// NewWeighted создает взвешенную политику по прошлым результатам.
+func NewWeighted(entries []history.Entry) *Weighted {
+	return &Weighted{
+		Now:     time.Now,
+		MinDays: 1.0 / 24,
+		MaxDays: 14,
+		last:    newLastPicks(entries),
+	}
+}
+
+// Weight возвращает вес участника в днях.
+func (w *Weighted) Weight(label string) float64 {
+	last, ok := w.last.latest(label)
+	if !ok {
+		return w.MaxDays
+	}
+	days := w.Now().Sub(last).Hours() / 24
+	return math.Min(math.Max(days, w.MinDays), w.MaxDays)
+}
+
+// Pick реализует Policy.
+func (w *Weighted) Pick(candidates []string, rnd random.Source) int {
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, label := range candidates {
+		weights[i] = w.Weight(label)
+		total += weights[i]
+	}
+
+	r := rnd.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return i
+		}
+		r -= weight
+	}
+	return len(candidates) - 1
+}
+
+// Observe реализует Policy.
+func (w *Weighted) Observe(e history.Entry) {
+	w.last.observe(e)
+}
+
+// Forget реализует Policy.
+func (w *Weighted) Forget(e history.Entry) {
+	w.last.forget(e)
+}
+
+// Order реализует Policy: очередь разыгрывается с теми же весами, что и выбор.
+func (w *Weighted) Order(labels []string, rnd random.Source) []int {
+	return orderByPicks(w.Pick, labels, rnd)
+}
+
+// RoundRobin всегда выбирает того, кто дольше всех не выбирался (никогда не
+// выбиравшиеся идут первыми). Равных кандидатов разделяет случайный выбор.
+type RoundRobin struct {
+	last lastPicks
+}
+
+// NewRoundRobin создает политику строгой очереди по прошлым результатам.
+func NewRoundRobin(entries []history.Entry) *RoundRobin {
+	return &RoundRobin{last: newLastPicks(entries)}
+}
+
+// Pick реализует Policy.
+func (r *RoundRobin) Pick(candidates []string, rnd random.Source) int {
+	var oldest []int
+	var oldestTime time.Time
+	for i, label := range candidates {
+		last, _ := r.last.latest(label)
+		switch {
+		case len(oldest) == 0 || last.Before(oldestTime):
+			oldest, oldestTime = []int{i}, last
+		case last.Equal(oldestTime):
+			oldest = append(oldest, i)
+		}
+	}
+	if len(oldest) == 1 {
+		return oldest[0]
+	}
+	return oldest[rnd.Intn(len(oldest))]
+}
+
+// Observe реализует Policy.
+func (r *RoundRobin) Observe(e history.Entry) {
+	r.last.observe(e)
+}
+
+// Forget реализует Policy.
+func (r *RoundRobin) Forget(e history.Entry) {
+	r.last.forget(e)
+}
+
+// Order реализует Policy: дольше всех ждавшие идут первыми.
+func (r *RoundRobin) Order(labels []string, rnd random.Source) []int {
+	return orderByPicks(r.Pick, labels, rnd)
+}
@@ -0,0 +1,125 @@
+package selection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/olegshirko/dice_roller/pkg/history"
+	"github.com/olegshirko/dice_roller/pkg/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func daysAgo(label string, days int) history.Entry {
+	return history.Entry{Time: now.AddDate(0, 0, -days), Label: label, Cycle: 1}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Names {
+		p, err := New(name, nil)
+		require.NoError(t, err, name)
+		assert.NotNil(t, p)
+	}
+	_, err := New("lottery", nil)
+	assert.Error(t, err)
+}
+
+func TestUniform(t *testing.T) {
+	rnd := random.NewScripted([]int{2, 0}, nil)
+	candidates := []string{"anna", "bob", "carol"}
+	assert.Equal(t, 2, Uniform{}.Pick(candidates, rnd))
+	assert.Equal(t, 0, Uniform{}.Pick(candidates, rnd))
+}
+
+func TestWeighted_Weights(t *testing.T) {
+	w := NewWeighted([]history.Entry{daysAgo("anna", 3), daysAgo("bob", 0), daysAgo("anna", 5), daysAgo("dave", 40)})
+	w.Now = func() time.Time { return now }
+
+	assert.InDelta(t, 3, w.Weight("anna"), 1e-9, "The latest pick should count")
+	assert.InDelta(t, w.MinDays, w.Weight("bob"), 1e-9, "Picked today keeps a small chance")
+	assert.InDelta(t, w.MaxDays, w.Weight("carol"), 1e-9, "Never picked gets the full weight")
+	assert.InDelta(t, w.MaxDays, w.Weight("dave"), 1e-9, "Weight is capped")
+
+	w.Observe(history.Entry{Time: now, Label: "carol"})
+	assert.InDelta(t, w.MinDays, w.Weight("carol"), 1e-9)
+}
+
+func TestWeighted_Pick(t *testing.T) {
+	w := NewWeighted([]history.Entry{daysAgo("anna", 1), daysAgo("bob", 3)})
+	w.Now = func() time.Time { return now }
+	candidates := []string{"anna", "bob"}
+
+	// Веса 1 и 3: первая четверть отрезка — anna, остальное — bob
+	assert.Equal(t, 0, w.Pick(candidates, random.NewScripted(nil, []float64{0.2})))
+	assert.Equal(t, 1, w.Pick(candidates, random.NewScripted(nil, []float64{0.3})))
+	assert.Equal(t, 1, w.Pick(candidates, random.NewScripted(nil, []float64{0.999})))
+
+	counts := map[int]int{}
+	rnd := random.NewPCG(1)
+	for i := 0; i < 4000; i++ {
+		counts[w.Pick(candidates, rnd)]++
+	}
+	assert.InDelta(t, 3.0, float64(counts[1])/float64(counts[0]), 0.4, "bob should be picked about three times as often")
+}
+
+func TestRoundRobin(t *testing.T) {
+	r := NewRoundRobin([]history.Entry{daysAgo("anna", 1), daysAgo("bob", 3), daysAgo("carol", 2)})
+	candidates := []string{"anna", "bob", "carol"}
+
+	assert.Equal(t, 1, r.Pick(candidates, random.NewScripted(nil, nil)), "Longest waiting goes first")
+
+	// Очередь соблюдается изо дня в день, с каким бы зерном ни шел розыгрыш
+	order := []string{}
+	for day := 0; day < 6; day++ {
+		i := r.Pick(candidates, random.NewPCG(uint64(day)))
+		order = append(order, candidates[i])
+		r.Observe(history.Entry{Time: now.AddDate(0, 0, day), Label: candidates[i]})
+	}
+	assert.Equal(t, []string{"bob", "carol", "anna", "bob", "carol", "anna"}, order)
+
+	// Никогда не выбиравшиеся равны между собой: выбор между ними случайный
+	fresh := NewRoundRobin(nil)
+	assert.Equal(t, 2, fresh.Pick(candidates, random.NewScripted([]int{2}, nil)))
+}
+
+func TestOrder(t *testing.T) {
+	labels := []string{"anna", "bob", "carol", "dave"}
+	past := []history.Entry{daysAgo("anna", 1), daysAgo("bob", 3), daysAgo("carol", 2)}
+
+	assert.Equal(t, []int{3, 1, 2, 0}, NewRoundRobin(past).Order(labels, random.NewPCG(1)),
+		"Never picked first, then the longest waiting")
+
+	w := NewWeighted(past)
+	w.Now = func() time.Time { return now }
+	// Первым чаще всего оказывается dave (вес 14), последним — anna (вес 1).
+	first := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		order := w.Order(labels, random.NewPCG(uint64(i)))
+		assert.ElementsMatch(t, []int{0, 1, 2, 3}, order)
+		first[order[0]]++
+	}
+	assert.Greater(t, first[3], first[1])
+	assert.Greater(t, first[1], first[0])
+
+	assert.ElementsMatch(t, []int{0, 1, 2, 3}, Uniform{}.Order(labels, random.NewPCG(1)))
+}
+
+func TestForget(t *testing.T) {
+	picked := daysAgo("anna", 0)
+	w := NewWeighted([]history.Entry{daysAgo("anna", 3)})
+	w.Now = func() time.Time { return now }
+	w.Observe(picked)
+	w.Forget(picked)
+	assert.InDelta(t, 3, w.Weight("anna"), 1e-9, "The undone pick is forgotten, the one before it counts again")
+	w.Forget(daysAgo("anna", 3))
+	assert.InDelta(t, w.MaxDays, w.Weight("anna"), 1e-9)
+
+	r := NewRoundRobin([]history.Entry{daysAgo("anna", 1), daysAgo("bob", 2)})
+	candidates := []string{"anna", "bob"}
+	r.Observe(daysAgo("bob", 0))
+	assert.Equal(t, 0, r.Pick(candidates, random.NewScripted(nil, nil)))
+	r.Forget(daysAgo("bob", 0))
+	assert.Equal(t, 1, r.Pick(candidates, random.NewScripted(nil, nil)), "bob is waiting longer again")
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"log"
+
 	"github.com/sqweek/dialog"
 )
 
@@ -11,3 +13,25 @@ var ShowFilePicker = func() ([]string, error) {
 	}
 	return []string{filename}, nil
 }
+
+// PickTextures открывает диалог выбора файлов текстур и возвращает выбранные файлы
+// или nil, если выбор отменен. Диалог не трогает игру, поэтому его можно
+// показывать в фоновой горутине, а загружать файлы — в игровом цикле.
+func PickTextures() []string {
+	log.Println("Opening file dialog to select textures...")
+	filenames, err := ShowFilePicker()
+	if err != nil {
+		if err == dialog.ErrCancelled {
+			log.Println("Texture selection cancelled.")
+		} else {
+			log.Printf("Error selecting file(s): %v", err)
+		}
+		return nil
+	}
+
+	if len(filenames) == 0 {
+		log.Println("No files were selected.")
+		return nil
+	}
+	return filenames
+}
@@ -0,0 +1,193 @@
+// Package webhook отправляет уведомления о выбранном участнике запросом POST:
+// тело собирается по шаблону, неудачные попытки повторяются с растущей паузой.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Snapshot — какое изображение приложить к уведомлению.
+type Snapshot string
+
+const (
+	NoSnapshot  Snapshot = ""
+	SnapshotPNG Snapshot = "png" // Кадр, на котором кость остановилась
+	SnapshotGIF Snapshot = "gif" // Анимация всего броска
+)
+
+// DefaultTemplate — тело по умолчанию. Поле text показывают чаты (Slack, Mattermost,
+// Rocket.Chat), остальные поля удобны для своих инструментов.
+const DefaultTemplate = `{"text": {{json (print .Label " is up!")}}, "label": {{json .Label}}, "time": {{json .Time}}, "cycle": {{.Cycle}}` +
+	`{{with .Snapshot}}, "snapshot": {"type": {{json $.SnapshotType}}, "data": {{json .}}}{{end}}}`
+
+// Параметры отправки.
+const (
+	maxBackoff = time.Minute // Пауза между попытками не растет дальше
+	maxLogged  = 2048        // Сколько байт тела выводится в лог в режиме DryRun
+)
+
+// Payload — данные шаблона тела.
+type Payload struct {
+	Label        string    // Выбранный участник
+	Time         time.Time // Когда кость остановилась
+	Cycle        int       // Номер цикла розыгрыша
+	Snapshot     []byte    // Изображение; функция json шаблона кодирует его в base64
+	SnapshotType string    // MIME-тип изображения: "image/png" или "image/gif"
+}
+
+// Options — настройки уведомлений.
+type Options struct {
+	URL      string
+	Template string // Шаблон text/template; пустой — DefaultTemplate
+	Snapshot Snapshot
+	Timeout  time.Duration // Ожидание ответа на одну попытку
+	Retries  int           // Сколько раз повторить неудачную отправку
+	Backoff  time.Duration // Пауза перед первым повтором; каждая следующая вдвое длиннее
+	DryRun   bool          // Выводить тело в лог вместо отправки
+}
+
+// Notifier отправляет уведомления.
+type Notifier struct {
+	opts   Options
+	tmpl   *template.Template
+	client *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+	wg     sync.WaitGroup
+}
+
+// New проверяет шаблон и создает Notifier.
+func New(opts Options) (*Notifier, error) {
+	text := opts.Template
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	return &Notifier{
+		opts:   opts,
+		tmpl:   tmpl,
+		client: &http.Client{Timeout: opts.Timeout},
+		sleep:  sleep,
+	}, nil
+}
+
+// toJSON — функция шаблона json: значение в виде JSON, строки экранируются.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Snapshot возвращает, какое изображение прикладывать к уведомлению.
+func (n *Notifier) Snapshot() Snapshot {
+	return n.opts.Snapshot
+}
+
+// Body собирает тело уведомления по шаблону.
+func (n *Notifier) Body(p Payload) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Notify отправляет уведомление в фоне; ошибки выводятся в лог.
+func (n *Notifier) Notify(p Payload) {
+	n.Pending()(p)
+}
+
+// Pending регистрирует уведомление, данные которого еще готовятся (например,
+// кодируется снимок), чтобы Wait дождался и его. Возвращенную функцию нужно
+// вызвать ровно один раз; она отправляет уведомление в фоне.
+func (n *Notifier) Pending() func(Payload) {
+	n.wg.Add(1)
+	return func(p Payload) {
+		go func() {
+			defer n.wg.Done()
+			if err := n.Send(context.Background(), p); err != nil {
+				log.Printf("Webhook for %s failed: %v", p.Label, err)
+			}
+		}()
+	}
+}
+
+// Wait дожидается уведомлений, отправляемых в фоне.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// Send отправляет уведомление и повторяет попытку при сетевой ошибке, ответе 5xx,
+// 408 или 429. Остальные ответы 4xx означают ошибку в адресе или шаблоне,
+// поэтому не повторяются.
+func (n *Notifier) Send(ctx context.Context, p Payload) error {
+	body, err := n.Body(p)
+	if err != nil {
+		return err
+	}
+	if n.opts.DryRun {
+		logged := body
+		if len(logged) > maxLogged {
+			logged = append(logged[:maxLogged:maxLogged], fmt.Sprintf("... (%d bytes)", len(body))...)
+		}
+		log.Printf("Webhook dry run: POST %s\n%s", n.opts.URL, logged)
+		return nil
+	}
+
+	delay := n.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.opts.Retries {
+			return err
+		}
+		log.Printf("Webhook attempt %d failed: %v; retrying in %v", attempt+1, err, delay)
+		if err := n.sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+}
+
+// post делает одну попытку и сообщает, имеет ли смысл повторить ее.
+func (n *Notifier) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Чтобы соединение можно было переиспользовать
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("%s answered %s", n.opts.URL, resp.Status)
+}
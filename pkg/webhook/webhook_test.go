@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer отвечает на запросы кодами из statuses по очереди и запоминает тела.
+type fakeServer struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (s *fakeServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies = append(s.bodies, string(body))
+	s.headers = append(s.headers, req.Header)
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+// newTestNotifier создает Notifier, который ходит в server и запоминает паузы вместо ожидания.
+func newTestNotifier(t *testing.T, opts Options, server http.RoundTripper) (*Notifier, *[]time.Duration) {
+	t.Helper()
+	n, err := New(opts)
+	require.NoError(t, err)
+	n.client.Transport = server
+	var delays []time.Duration
+	n.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	return n, &delays
+}
+
+var testPayload = Payload{Label: "alice", Time: time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC), Cycle: 3}
+
+func TestBody(t *testing.T) {
+	n, err := New(Options{URL: "http://example.com"})
+	require.NoError(t, err)
+
+	body, err := n.Body(testPayload)
+	require.NoError(t, err)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got), "default body must be valid JSON: %s", body)
+	assert.Equal(t, "alice is up!", got["text"])
+	assert.Equal(t, "alice", got["label"])
+	assert.Equal(t, "2024-05-06T09:30:00Z", got["time"])
+	assert.EqualValues(t, 3, got["cycle"])
+	assert.NotContains(t, got, "snapshot", "no snapshot without an image")
+
+	p := testPayload
+	p.Label = `Bob "the builder"`
+	p.Snapshot, p.SnapshotType = []byte{0x89, 'P', 'N', 'G'}, "image/png"
+	body, err = n.Body(p)
+	require.NoError(t, err)
+	got = nil
+	require.NoError(t, json.Unmarshal(body, &got), "quotes in labels must be escaped: %s", body)
+	assert.Equal(t, `Bob "the builder"`, got["label"])
+	assert.Equal(t, map[string]any{"type": "image/png", "data": "iVBORw=="}, got["snapshot"])
+
+	n, err = New(Options{Template: `{"content": "Next: {{.Label}}"}`})
+	require.NoError(t, err)
+	body, err = n.Body(testPayload)
+	require.NoError(t, err)
+	assert.Equal(t, `{"content": "Next: alice"}`, string(body))
+
+	_, err = New(Options{Template: `{{.Label`})
+	assert.Error(t, err, "broken template")
+}
+
+func TestSend_Retries(t *testing.T) {
+	server := &fakeServer{statuses: []int{0, http.StatusBadGateway, http.StatusTooManyRequests, http.StatusNoContent}}
+	n, delays := newTestNotifier(t, Options{URL: "http://example.com/hook", Retries: 3, Backoff: time.Second}, server)
+
+	require.NoError(t, n.Send(context.Background(), testPayload))
+	assert.Len(t, server.bodies, 4)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, *delays, "backoff doubles")
+	assert.Equal(t, "application/json", server.headers[0].Get("Content-Type"))
+	assert.Equal(t, server.bodies[0], server.bodies[3], "every attempt sends the same body")
+
+	server = &fakeServer{statuses: []int{http.StatusServiceUnavailable}}
+	n, delays = newTestNotifier(t, Options{URL: "http://example.com/hook", Retries: 2, Backoff: time.Second}, server)
+	err := n.Send(context.Background(), testPayload)
+	assert.ErrorContains(t, err, "Service Unavailable")
+	assert.Len(t, server.bodies, 3, "first attempt and two retries")
+	assert.Len(t, *delays, 2)
+
+	server = &fakeServer{statuses: []int{http.StatusNotFound, http.StatusOK}}
+	n, delays = newTestNotifier(t, Options{URL: "http://example.com/hook", Retries: 3, Backoff: time.Second}, server)
+	assert.Error(t, n.Send(context.Background(), testPayload))
+	assert.Len(t, server.bodies, 1, "client errors are not retried")
+	assert.Empty(t, *delays)
+}
+
+func TestSend_Backoff(t *testing.T) {
+	server := &fakeServer{statuses: []int{http.StatusInternalServerError}}
+	n, delays := newTestNotifier(t, Options{URL: "http://example.com/hook", Retries: 8, Backoff: 10 * time.Second}, server)
+
+	assert.Error(t, n.Send(context.Background(), testPayload))
+	assert.Equal(t, time.Minute, (*delays)[len(*delays)-1], "backoff is capped")
+}
+
+// hangingServer не отвечает, пока запрос не отменят.
+type hangingServer struct{}
+
+func (hangingServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestSend_Timeout(t *testing.T) {
+	n, delays := newTestNotifier(t, Options{URL: "http://example.com/hook", Timeout: 20 * time.Millisecond, Retries: 1}, hangingServer{})
+
+	start := time.Now()
+	assert.Error(t, n.Send(context.Background(), testPayload))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Len(t, *delays, 1, "a timed-out attempt is retried")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, delays = newTestNotifier(t, Options{URL: "http://example.com/hook", Retries: 3}, hangingServer{})
+	assert.ErrorIs(t, n.Send(ctx, testPayload), context.Canceled)
+	assert.Empty(t, *delays, "a cancelled send is not retried")
+}
+
+func TestNotify_DryRun(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+	server := &fakeServer{statuses: []int{http.StatusOK}}
+	n, _ := newTestNotifier(t, Options{URL: "http://example.com/hook", DryRun: true}, server)
+
+	n.Notify(testPayload)
+	p := testPayload
+	p.Snapshot = bytes.Repeat([]byte{1}, 4*maxLogged)
+	n.Notify(p)
+	n.Wait()
+
+	assert.Empty(t, server.bodies, "dry run sends nothing")
+	assert.Contains(t, out.String(), "POST http://example.com/hook")
+	assert.Contains(t, out.String(), `"label": "alice"`)
+	assert.Contains(t, out.String(), "bytes)", "long bodies are truncated")
+	assert.Less(t, out.Len(), 2*maxLogged+1000)
+}